@@ -0,0 +1,33 @@
+// Package blobstore define o contrato dos armazenamentos de arquivos gerados
+// pela API, como as exportações assíncronas da uiapi e os snapshots da API
+// pública. As duas APIs usam o mesmo armazenamento, e os erros deste pacote
+// permitem que cada uma trate chaves inexistentes ou já escritas sem depender
+// da implementação.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound é retornado pelo Store quando a chave não existe.
+var ErrNotFound = errors.New("key not found")
+
+// ErrExists é retornado pelo Create do Store quando a chave já existe.
+var ErrExists = errors.New("key already exists")
+
+// Store guarda arquivos por chave, de forma que eles sobrevivam a reinícios
+// da API e sejam compartilhados entre as instâncias.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Create escreve o conteúdo apenas se a chave ainda não existir, de forma
+	// atômica, e retorna ErrExists caso contrário.
+	Create(ctx context.Context, key string, content []byte) error
+	// Get retorna ErrNotFound se a chave não existir.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// List retorna as chaves que começam com prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Delete não retorna erro se a chave não existir.
+	Delete(ctx context.Context, key string) error
+}
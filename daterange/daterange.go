@@ -0,0 +1,144 @@
+// Package daterange lê e valida os intervalos de meses informados nos
+// parâmetros de e ate das rotas da API, no formato AAAA-MM.
+package daterange
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Nomes dos parâmetros do intervalo.
+const (
+	FromParam = "de"
+	ToParam   = "ate"
+)
+
+// Primeiro mês com dados disponíveis, usado quando o parâmetro de não é informado.
+var First = Month{Year: 2018, Month: 1}
+
+// Month é um mês de um ano.
+type Month struct {
+	Year  int
+	Month int
+}
+
+// Number transforma o mês de um ano em um número sequencial de meses, usado
+// para comparar meses e contar os meses entre eles.
+func Number(year, month int) int {
+	return year*12 + month - 1
+}
+
+// MonthOf retorna o mês de um número sequencial de meses, o inverso de Number.
+func MonthOf(n int) Month {
+	return Month{Year: n / 12, Month: n%12 + 1}
+}
+
+// Current retorna o mês atual, ou seja, o mês de now.
+func Current(now time.Time) Month {
+	return Month{Year: now.Year(), Month: int(now.Month())}
+}
+
+func (m Month) String() string {
+	return fmt.Sprintf("%04d-%02d", m.Year, m.Month)
+}
+
+func (m Month) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + m.String() + `"`), nil
+}
+
+func (m *Month) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	t, err := time.Parse("2006-01", value)
+	if err != nil {
+		return err
+	}
+	*m = Month{Year: t.Year(), Month: int(t.Month())}
+	return nil
+}
+
+// Range é um intervalo de meses, incluindo o primeiro e o último.
+type Range struct {
+	From Month `json:"de"`
+	To   Month `json:"ate"`
+}
+
+// Parse lê os parâmetros de e ate. Retorna nil se nenhum dos dois for
+// informado. Sem de, o intervalo começa no primeiro mês com dados e, sem ate,
+// termina no mês atual. Os meses fora desse intervalo são inválidos, o que
+// limita os anos consultados pelas rotas.
+func Parse(from, to string) (*Range, error) {
+	return parse(from, to, time.Now())
+}
+
+func parse(from, to string, now time.Time) (*Range, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" && to == "" {
+		return nil, nil
+	}
+	last := Current(now)
+	r := &Range{From: First, To: last}
+	var err error
+	if from != "" {
+		if r.From, err = parseMonth(FromParam, from, last); err != nil {
+			return nil, err
+		}
+	}
+	if to != "" {
+		if r.To, err = parseMonth(ToParam, to, last); err != nil {
+			return nil, err
+		}
+	}
+	if Number(r.From.Year, r.From.Month) > Number(r.To.Year, r.To.Month) {
+		return nil, fmt.Errorf("parâmetro de '%s' é posterior ao parâmetro ate '%s'!", r.From, r.To)
+	}
+	return r, nil
+}
+
+func parseMonth(param, value string, last Month) (Month, error) {
+	t, err := time.Parse("2006-01", value)
+	if err != nil {
+		return Month{}, fmt.Errorf("parâmetro %s '%s' é inválido! Use o formato AAAA-MM.", param, value)
+	}
+	m := Month{Year: t.Year(), Month: int(t.Month())}
+	if n := Number(m.Year, m.Month); n < Number(First.Year, First.Month) || n > Number(last.Year, last.Month) {
+		return Month{}, fmt.Errorf("parâmetro %s '%s' é inválido! Os dados estão disponíveis de %s a %s.", param, value, First, last)
+	}
+	return m, nil
+}
+
+// Contains verifica se o mês está no intervalo. Um intervalo nil contém todos
+// os meses.
+func (r *Range) Contains(year, month int) bool {
+	if r == nil {
+		return true
+	}
+	n := Number(year, month)
+	return n >= Number(r.From.Year, r.From.Month) && n <= Number(r.To.Year, r.To.Month)
+}
+
+// Years retorna, em ordem, os anos do intervalo.
+func (r *Range) Years() []int {
+	var years []int
+	for y := r.From.Year; y <= r.To.Year; y++ {
+		years = append(years, y)
+	}
+	return years
+}
+
+// Months retorna, em ordem, os meses do intervalo.
+func (r *Range) Months() []Month {
+	var months []Month
+	for n := Number(r.From.Year, r.From.Month); n <= Number(r.To.Year, r.To.Month); n++ {
+		months = append(months, MonthOf(n))
+	}
+	return months
+}
+
+func (r *Range) String() string {
+	return fmt.Sprintf("%s a %s", r.From, r.To)
+}
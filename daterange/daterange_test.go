@@ -0,0 +1,82 @@
+package daterange
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := parseTests{now: time.Date(2023, time.May, 10, 0, 0, 0, 0, time.UTC)}
+	t.Run("Test Parse when range is not informed", tests.testWhenRangeIsNotInformed)
+	t.Run("Test Parse when range is valid", tests.testWhenRangeIsValid)
+	t.Run("Test Parse when one of the ends is missing", tests.testWhenOneOfTheEndsIsMissing)
+	t.Run("Test Parse when range is invalid", tests.testWhenRangeIsInvalid)
+}
+
+type parseTests struct {
+	now time.Time
+}
+
+func (p parseTests) testWhenRangeIsNotInformed(t *testing.T) {
+	r, err := parse("", " ", p.now)
+
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+	assert.True(t, r.Contains(2020, 1))
+}
+
+func (p parseTests) testWhenRangeIsValid(t *testing.T) {
+	r, err := parse("2021-07", "2023-03", p.now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Range{From: Month{2021, 7}, To: Month{2023, 3}}, r)
+	assert.Equal(t, []int{2021, 2022, 2023}, r.Years())
+	assert.Len(t, r.Months(), 21)
+	assert.Equal(t, Month{2022, 1}, r.Months()[6])
+	assert.True(t, r.Contains(2021, 7))
+	assert.True(t, r.Contains(2023, 3))
+	assert.False(t, r.Contains(2021, 6))
+	assert.False(t, r.Contains(2023, 4))
+	assert.Equal(t, "2021-07 a 2023-03", r.String())
+}
+
+func (p parseTests) testWhenOneOfTheEndsIsMissing(t *testing.T) {
+	from, err := parse("2022-11", "", p.now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Range{From: Month{2022, 11}, To: Month{2023, 5}}, from)
+
+	to, err := parse("", "2018-03", p.now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Range{From: First, To: Month{2018, 3}}, to)
+}
+
+func (p parseTests) testWhenRangeIsInvalid(t *testing.T) {
+	for _, params := range [][2]string{{"2021-13", ""}, {"", "2021"}, {"07/2021", ""}, {"2023-03", "2021-07"}, {"0001-01", ""}, {"2017-12", ""}, {"", "2023-06"}, {"", "9999-12"}} {
+		_, err := parse(params[0], params[1], p.now)
+
+		assert.Error(t, err, params)
+	}
+}
+
+func TestNumber(t *testing.T) {
+	assert.Equal(t, Number(2023, 12)+1, Number(2024, 1))
+	assert.Equal(t, Month{2024, 1}, MonthOf(Number(2024, 1)))
+	assert.Equal(t, Month{2023, 12}, MonthOf(Number(2024, 1)-1))
+	assert.Equal(t, Month{2023, 5}, Current(time.Date(2023, time.May, 10, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestMonthJSON(t *testing.T) {
+	data, err := json.Marshal(Range{From: Month{2021, 7}, To: Month{2023, 3}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"de":"2021-07","ate":"2023-03"}`, string(data))
+
+	var r Range
+	assert.NoError(t, json.Unmarshal(data, &r))
+	assert.Equal(t, Range{From: Month{2021, 7}, To: Month{2023, 3}}, r)
+	assert.Error(t, json.Unmarshal([]byte(`{"de":"2021-13"}`), &r))
+}
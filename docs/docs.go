@@ -35,6 +35,18 @@ const docTemplate = `{
                         "name": "orgao",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao. Os valores anuais são corrigidos pela média dos fatores dos meses do ano.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -62,132 +74,84 @@ const docTemplate = `{
                 }
             }
         },
-        "/uiapi/v2/download": {
+        "/uiapi/v2/anomalias": {
             "get": {
-                "description": "Baixa um arquivo csv referentes a remunerações a partir de filtros. O arquivo tem um limite de 10 mil linhas. Para cada parâmetro, é possível passar múltiplos valores separados por vírgula. As colunas do arquivo csv são:\n\n- Nome do órgão\n- Mês de referência do contracheque\n- Ano de referência do contracheque\n- Matrícula do membro (identificador único do membro no órgão)\n- Nome do membro\n- Cargo que o membro exerce no órgão\n- Lotação (unidade na qual o membro do órgão desenvolve suas atividades)\n- Categoria do contracheque (base, outras remunerações ou descontos)\n- Detalhamento do contracheque (ex: subsídio, desconto, benefício, etc)\n- Valor do contracheque em reais, não corrigido pela inflação",
+                "description": "Lista as anomalias dos totais mensais dos órgãos no período, calculadas pela API. Cada mês é comparado com a mediana dos 6 meses anteriores com dados do mesmo órgão (são necessários ao menos 3). Os sinais são:\n- remuneracoes: o total de remunerações se afasta mais de 50% da mediana\n- num_membros: a quantidade de membros se afasta mais de 30% da mediana\n- rubrica: uma rubrica atinge três vezes a sua mediana ou, se não aparecia nos meses anteriores, passa a representar 10% das remunerações (salário + benefícios) do mês\n\nAs anomalias são ordenadas por ano, mês e órgão. Os valores são nominais.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "ui_api"
                 ],
-                "operationId": "DownloadByUrl",
+                "operationId": "GetAnomalies",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Anos a serem pesquisados, separados por virgula. Exemplo: 2018,2019,2020",
+                        "description": "Anos a serem considerados, separados por vírgula. Exemplo: 2023,2024. Obrigatório se de e ate não forem informados.",
                         "name": "anos",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Meses a serem pesquisados, separados por virgula. Exemplo: 1,2,3",
+                        "description": "Meses a serem considerados, separados por vírgula. Exemplo: 1,2,3",
                         "name": "meses",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Orgãos a serem pesquisados, separados por virgula. Exemplo: tjal,mpal,mppb",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Órgãos a serem considerados, separados por vírgula. Sem filtros, todos os órgãos são considerados.",
                         "name": "orgaos",
                         "in": "query"
                     },
                     {
-                        "enum": [
-                            "base",
-                            "outras",
-                            "descontos"
-                        ],
                         "type": "string",
-                        "description": "Categorias a serem pesquisadas. Se nada for informado, todas as categorias serão baixadas",
-                        "name": "categorias",
+                        "description": "Grupos de órgãos a serem considerados, separados por vírgula. Exemplo: justica-estadual",
+                        "name": "grupos",
                         "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Arquivo CSV com os dados.",
-                        "schema": {
-                            "type": "file"
-                        }
                     },
-                    "400": {
-                        "description": "Erro de validação dos parâmetros.",
-                        "schema": {
-                            "type": "string"
-                        }
+                    {
+                        "type": "string",
+                        "description": "UFs dos órgãos a serem considerados, separadas por vírgula. Exemplo: AL,PB",
+                        "name": "ufs",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Erro interno do servidor.",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/uiapi/v2/geral/remuneracao/{ano}": {
-            "get": {
-                "description": "Busca os dados, das remunerações (remuneração base/salário, outras remunerações/benefícios, descontos) e benefícios identificados (rubricas/penduricalhos) de um ano inteiro, agrupados por mês.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "ui_api"
-                ],
-                "operationId": "GetGeneralRemunerationFromYear",
-                "parameters": [
                     {
                         "type": "string",
-                        "description": "Ano da remuneração. Ex.: 2018, 2019, 2020...",
-                        "name": "ano",
-                        "in": "path",
-                        "required": true
+                        "description": "Entidades dos órgãos a serem consideradas, separadas por vírgula. Exemplo: Tribunal",
+                        "name": "entidades",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Requisição bem sucedida.",
+                        "description": "Anomalias encontradas",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/uiapi.mensalRemuneration"
+                                "$ref": "#/definitions/uiapi.anomaly"
                             }
                         }
                     },
                     "400": {
-                        "description": "Parâmetro ano inválido.",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "500": {
-                        "description": "Erro interno.",
+                        "description": "Parâmetros inválidos",
                         "schema": {
                             "type": "string"
                         }
-                    }
-                }
-            }
-        },
-        "/uiapi/v2/geral/resumo": {
-            "get": {
-                "description": "Busca e resume os dados das remunerações de todos os anos, trazendo o número de órgão participantes das tetativas de coleta (inclue os órgãos da coleta automatizada, coleta manual e órgãos não coletados, mas que armazenamos alguma informação), número total de meses coletados, data do primeiro e último mês coletado e o valor total de remuneração considerando todos os meses.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "ui_api"
-                ],
-                "operationId": "GetGeneralSummary",
-                "responses": {
-                    "200": {
-                        "description": "Requisição bem sucedida.",
-                        "schema": {
-                            "$ref": "#/definitions/uiapi.generalSummary"
-                        }
                     },
                     "500": {
-                        "description": "Erro interno do servidor.",
+                        "description": "Erro interno do servidor",
                         "schema": {
                             "type": "string"
                         }
@@ -195,104 +159,93 @@ const docTemplate = `{
                 }
             }
         },
-        "/uiapi/v2/orgao/media/{ano}": {
+        "/uiapi/v2/comparar": {
             "get": {
-                "description": "Busca médias (remuneração base, outras remunerações, descontos e remuneração total) de cada órgão em um ano especificado.",
+                "description": "Compara os órgãos lado a lado. Retorna as séries mensais de totais e valores por membro de cada órgão, alinhadas pelos mesmos meses (null nos meses em que o órgão não tem dados), e a posição de cada órgão em cada mês e no período, pela remuneração líquida (salário + benefícios - descontos) por membro, da maior para a menor.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "ui_api"
                 ],
-                "operationId": "GetAveragePerAgency",
+                "operationId": "CompareAgencies",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Ano para filtrar os dados",
-                        "name": "ano",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Lista de dados de médias dos órgãos",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/uiapi.averagePerAgency"
-                            }
-                        }
+                        "type": "string",
+                        "description": "Órgãos a serem comparados, separados por vírgula. Exemplo: tjal,tjpe,tjba",
+                        "name": "orgaos",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Parâmetro ANO inválido",
-                        "schema": {
-                            "type": "string"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Grupos de órgãos a serem comparados, separados por vírgula. Exemplo: justica-estadual",
+                        "name": "grupos",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Erro ao buscar dados",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/uiapi/v2/orgao/resumo/{orgao}/{ano}/{mes}": {
-            "get": {
-                "description": "Endpoint de resumo das remunerações mensal de um órgão. Fornece uma análise financeira abrangente para um órgão específico em um mês e ano determinados\n\nInformações Financeiras Detalhadas:\n- Remuneração base total e máxima\n- Outras remunerações e benefícios\n- Valor total de descontos\n- Contagem de membros\n- Análise de rubricas (penduricalhos) específicas\n\nContexto Adicional:\n- Marcadores de existência de dados anteriores/posteriores ao ano/mês consultados\n- Timestamp da coleta de dados\n- Detalhamento de diferentes tipos de remuneração (remuneração base, outras remunerações e descontos)",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "ui_api"
-                ],
-                "operationId": "GetSummaryOfAgency",
-                "parameters": [
                     {
                         "type": "string",
-                        "description": "Sigla do órgão. Ex.: tjal, mppb, tjmmg",
-                        "name": "orgao",
-                        "in": "path",
-                        "required": true
+                        "description": "UFs dos órgãos a serem comparados, separadas por vírgula. Exemplo: AL,PE,BA",
+                        "name": "ufs",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Mês de referência (1-12)",
-                        "name": "mes",
-                        "in": "path",
-                        "required": true
+                        "description": "Entidades dos órgãos a serem comparados, separadas por vírgula. Exemplo: Tribunal",
+                        "name": "entidades",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Ano de referência",
-                        "name": "ano",
-                        "in": "path",
-                        "required": true
+                        "description": "Anos a serem considerados, separados por vírgula. Exemplo: 2022,2023. Obrigatório se de e ate não forem informados.",
+                        "name": "anos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Meses a serem considerados, separados por vírgula. Exemplo: 1,2,3",
+                        "name": "meses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Resumo financeiro do órgão processado com sucesso",
+                        "description": "Séries mensais e posições dos órgãos",
                         "schema": {
-                            "$ref": "#/definitions/uiapi.v2AgencySummary"
+                            "$ref": "#/definitions/uiapi.agencyComparison"
                         }
                     },
                     "400": {
-                        "description": "Erro de validação dos parâmetros de entrada",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "404": {
-                        "description": "Órgão ou dados não encontrados",
+                        "description": "Parâmetros inválidos",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Erro interno durante processamento da consulta",
+                        "description": "Erro interno do servidor",
                         "schema": {
                             "type": "string"
                         }
@@ -300,16 +253,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/uiapi/v2/orgao/salario/{orgao}/{ano}/{mes}": {
+        "/uiapi/v2/distribuicao/{orgao}/{ano}/{mes}": {
             "get": {
-                "description": "Endpoint de análise detalhada de remunerações dos órgãos. Recupera informações financeiras granulares para um órgão específico em um mês e ano determinados\n\nInformações Fornecidos:\n- Remuneração máxima no período\n- Histograma de distribuição de rendimentos\n- Metadados do pacote de dados\n\nHistograma de Distribuição de Rendimentos:\n\nO histograma apresenta a quantidade de membros que receberam diferentes faixas de remunerações em um determinado mês.\nAs faixas de remuneração são definidas da seguinte forma:\n- 10000: quantidade de membros que receberam até R$ 10.000,00\n- 20000: quantidade de membros que receberam entre R$ 10.000,01 e R$ 20.000,00\n- 30000: quantidade de membros que receberam entre R$ 20.000,01 e R$ 30.000,00\n- 40000: quantidade de membros que receberam entre R$ 30.000,01 e R$ 40.000,00\n- 50000: quantidade de membros que receberam entre R$ 40.000,01 e R$ 50.000,00\n- -1: quantidade de membros que receberam acima de R$ 50.000,01",
+                "description": "Calcula a distribuição dos valores dos contracheques dos membros de um órgão em um mês, a partir das linhas dos arquivos de remunerações: percentis (p10, p25, p50, p75, p90 e p99), média, mediana, mínimo, máximo, coeficiente de Gini e um histograma com faixas configuráveis. Os valores de cada membro são a soma das linhas do seu contracheque.\n\nAs faixas do histograma incluem o limite inferior e excluem o superior. A primeira faixa tem os valores abaixo do primeiro limite e a última, os valores a partir do último limite. Por padrão, os limites vão de R$ 0,00 a R$ 100.000,00, de R$ 10.000,00 em R$ 10.000,00.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "ui_api"
                 ],
-                "operationId": "GetSalaryOfAgencyMonthYear",
+                "operationId": "GetRemunerationDistribution",
                 "parameters": [
                     {
                         "type": "string",
@@ -319,41 +272,71 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
+                        "type": "integer",
+                        "description": "Ano de referência",
+                        "name": "ano",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
                         "description": "Mês de referência (1-12)",
                         "name": "mes",
                         "in": "path",
                         "required": true
                     },
                     {
+                        "enum": [
+                            "liquido",
+                            "base",
+                            "outras",
+                            "descontos"
+                        ],
                         "type": "string",
-                        "description": "Ano de referência",
-                        "name": "ano",
-                        "in": "path",
-                        "required": true
+                        "description": "Valor do contracheque usado na distribuição. Padrão: liquido (salário + benefícios - descontos)",
+                        "name": "valor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limites das faixas do histograma, em ordem crescente e separados por vírgula. Exemplo: 0,25000,50000,75000,100000",
+                        "name": "faixas",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao. As faixas se referem aos valores já corrigidos.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Dados de remuneração processados com sucesso",
+                        "description": "Distribuição dos valores dos contracheques",
                         "schema": {
-                            "$ref": "#/definitions/uiapi.agencyRemuneration"
+                            "$ref": "#/definitions/uiapi.remunerationDistribution"
                         }
                     },
-                    "206": {
-                        "description": "Dados coletados com informações de processamento",
+                    "400": {
+                        "description": "Parâmetros inválidos",
                         "schema": {
-                            "$ref": "#/definitions/uiapi.v2ProcInfoResult"
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Erro de validação dos parâmetros de entrada",
+                    "404": {
+                        "description": "Não existem dados para os parâmetros informados",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Erro interno durante processamento da consulta",
+                        "description": "Erro interno do servidor",
                         "schema": {
                             "type": "string"
                         }
@@ -361,47 +344,1426 @@ const docTemplate = `{
                 }
             }
         },
-        "/uiapi/v2/orgao/totais/{orgao}/{ano}": {
+        "/uiapi/v2/download": {
             "get": {
-                "description": "Recupera dados financeiros detalhados para um órgão em um ano específico\n\nDados Financeiros Mensais:\n- Remuneração base\n- Outras remunerações e benefícios\n- Descontos\n- Contagem de membros\n\nMétricas Adicionais:\n- Médias per capita\n- Detalhamento de rubricas (auxílios, férias, gratificações)\n- Informações gerais sobre o órgão pesquisado\n- Informações sobre o pacote de dados (URL para download, hash, tamanho)",
+                "description": "Baixa um arquivo referente a remunerações a partir de filtros, nos formatos csv (padrão), jsonl ou parquet. Sem chave de acesso, a pesquisa pode ter no máximo 10 mil linhas: pesquisas maiores são recusadas, em vez de truncadas, e devem usar a rota de exportações. Com chave de acesso, não há limite e o arquivo é enviado à medida que os dados são lidos; se houver um erro no meio do envio, a conexão é interrompida. Para cada parâmetro, é possível passar múltiplos valores separados por vírgula. Nos formatos jsonl e parquet, mês e ano são inteiros e o valor é numérico. As colunas do arquivo são:\n\n- Nome do órgão\n- Mês de referência do contracheque\n- Ano de referência do contracheque\n- Matrícula do membro (identificador único do membro no órgão)\n- Nome do membro\n- Cargo que o membro exerce no órgão\n- Lotação (unidade na qual o membro do órgão desenvolve suas atividades)\n- Categoria do contracheque (base, outras remunerações ou descontos)\n- Detalhamento do contracheque (ex: subsídio, desconto, benefício, etc)\n- Valor do contracheque em reais, não corrigido pela inflação",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "ui_api"
                 ],
-                "operationId": "GetTotalsOfAgencyYear",
+                "operationId": "DownloadByUrl",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Identificador do órgão público",
-                        "name": "orgao",
-                        "in": "path",
-                        "required": true
+                        "description": "Anos a serem pesquisados, separados por virgula. Exemplo: 2018,2019,2020",
+                        "name": "anos",
+                        "in": "query"
                     },
                     {
-                        "type": "integer",
+                        "type": "string",
+                        "description": "Meses a serem pesquisados, separados por virgula. Exemplo: 1,2,3",
+                        "name": "meses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orgãos a serem pesquisados, separados por virgula. Exemplo: tjal,mpal,mppb",
+                        "name": "orgaos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grupos de órgãos a serem pesquisados, separados por vírgula. Exemplo: justica-estadual,ministerios-publicos",
+                        "name": "grupos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UFs dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: AL,PB,PE",
+                        "name": "ufs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entidades dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: Tribunal",
+                        "name": "entidades",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Categorias a serem pesquisadas, separadas por vírgula: base, outras e descontos. Se nada for informado, todas as categorias serão baixadas. Substitui o antigo parâmetro tipos, que não é mais aceito. Exemplo: base,outras",
+                        "name": "categorias",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho do nome do membro, sem diferenciar maiúsculas e acentos",
+                        "name": "nome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho do cargo do membro, sem diferenciar maiúsculas e acentos",
+                        "name": "cargo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho da lotação do membro, sem diferenciar maiúsculas e acentos",
+                        "name": "lotacao",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho do detalhamento do contracheque, sem diferenciar maiúsculas e acentos. Exemplo: auxilio-moradia",
+                        "name": "detalhamento_contracheque",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Valor mínimo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 1000.50 ou 1.000,50",
+                        "name": "valor_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Valor máximo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 50000",
+                        "name": "valor_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ordenação das linhas no formato 'campo direção'. Campos: orgao, mes, ano, nome, cargo, lotacao, detalhamento_contracheque e valor. Direções: asc (padrão) e desc. Na ordenação por valor, as linhas com valor inválido ficam de fora. Com ordenação, o arquivo só começa a ser enviado depois que todos os dados forem lidos",
+                        "name": "ordenar",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "membro"
+                        ],
+                        "type": "string",
+                        "description": "Agrupa as linhas por membro, mês e ano. As colunas passam a ser orgao, mes, ano, matricula, nome, cargo, lotacao, base, outras, descontos e liquido",
+                        "name": "agrupar",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "description": "Formato do arquivo. Se nada for informado, o arquivo será gerado em csv",
+                        "name": "formato",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave de acesso no formato 'Bearer \u003cchave\u003e'. Remove o limite de linhas do arquivo",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Arquivo com os dados no formato pedido. Sem chave de acesso, o cabeçalho X-Total-Rows informa a quantidade de linhas.",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Erro de validação dos parâmetros ou, sem chave de acesso, pesquisa com mais linhas que o limite do download.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/exportacoes": {
+            "post": {
+                "description": "Cria uma exportação assíncrona de remunerações, para arquivos grandes demais para a rota de download. Aceita os mesmos parâmetros da rota /uiapi/v2/download, mas o arquivo não tem limite de linhas, mesmo sem chave de acesso. O arquivo é gerado em segundo plano e a situação da exportação deve ser consultada na rota /uiapi/v2/exportacoes/{id} até que ela seja concluída. Cada cliente pode ter poucas exportações em andamento ao mesmo tempo, e as exportações e seus arquivos são apagados depois de um tempo, informado no campo expira_em.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "CreateExport",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Anos a serem pesquisados, separados por virgula. Exemplo: 2018,2019,2020",
+                        "name": "anos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Meses a serem pesquisados, separados por virgula. Exemplo: 1,2,3",
+                        "name": "meses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orgãos a serem pesquisados, separados por virgula. Exemplo: tjal,mpal,mppb",
+                        "name": "orgaos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grupos de órgãos a serem pesquisados, separados por vírgula. Exemplo: justica-estadual,ministerios-publicos",
+                        "name": "grupos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UFs dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: AL,PB,PE",
+                        "name": "ufs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entidades dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: Tribunal",
+                        "name": "entidades",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Categorias a serem pesquisadas, separadas por vírgula: base, outras e descontos. Exemplo: base,outras",
+                        "name": "categorias",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho do nome do membro, sem diferenciar maiúsculas e acentos",
+                        "name": "nome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho do cargo do membro, sem diferenciar maiúsculas e acentos",
+                        "name": "cargo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho da lotação do membro, sem diferenciar maiúsculas e acentos",
+                        "name": "lotacao",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho do detalhamento do contracheque, sem diferenciar maiúsculas e acentos. Exemplo: auxilio-moradia",
+                        "name": "detalhamento_contracheque",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Valor mínimo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 1000.50 ou 1.000,50",
+                        "name": "valor_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Valor máximo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 50000",
+                        "name": "valor_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ordenação das linhas no formato 'campo direção'. Na ordenação por valor, as linhas com valor inválido ficam de fora. Exemplo: valor desc",
+                        "name": "ordenar",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "membro"
+                        ],
+                        "type": "string",
+                        "description": "Agrupa as linhas por membro, mês e ano. As colunas passam a ser orgao, mes, ano, matricula, nome, cargo, lotacao, base, outras, descontos e liquido",
+                        "name": "agrupar",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "description": "Formato do arquivo. Se nada for informado, o arquivo será gerado em csv",
+                        "name": "formato",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Exportação criada",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.exportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Erro de validação dos parâmetros.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Limite de exportações em andamento do cliente atingido.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Exportações indisponíveis ou fila de exportações cheia.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/exportacoes/{id}": {
+            "get": {
+                "description": "Retorna a situação de uma exportação assíncrona (pendente, executando, concluida ou erro). Quando a exportação é concluída, o campo 'arquivo' contém a rota para baixar o arquivo.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetExport",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identificador da exportação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Situação da exportação",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.exportJob"
+                        }
+                    },
+                    "404": {
+                        "description": "Exportação não encontrada",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/exportacoes/{id}/arquivo": {
+            "get": {
+                "description": "Baixa o arquivo de uma exportação assíncrona concluída.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "DownloadExport",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identificador da exportação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Arquivo com os dados no formato pedido",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Exportação não encontrada",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Exportação ainda não concluída",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/geral/remuneracao/{ano}": {
+            "get": {
+                "description": "Busca os dados, das remunerações (remuneração base/salário, outras remunerações/benefícios, descontos) e benefícios identificados (rubricas/penduricalhos) de um ano inteiro, agrupados por mês.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetGeneralRemunerationFromYear",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ano da remuneração. Ex.: 2018, 2019, 2020...",
+                        "name": "ano",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao. O mês base usado é informado no cabeçalho X-Correcao-Monetaria.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Requisição bem sucedida.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/uiapi.mensalRemuneration"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetro ano inválido.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/geral/resumo": {
+            "get": {
+                "description": "Busca e resume os dados das remunerações de todos os anos, trazendo o número de órgão participantes das tetativas de coleta (inclue os órgãos da coleta automatizada, coleta manual e órgãos não coletados, mas que armazenamos alguma informação), número total de meses coletados, data do primeiro e último mês coletado e o valor total de remuneração considerando todos os meses.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetGeneralSummary",
+                "responses": {
+                    "200": {
+                        "description": "Requisição bem sucedida.",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.generalSummary"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/membro/{orgao}": {
+            "get": {
+                "description": "Retorna, mês a mês, os totais de remuneração base, outras remunerações e descontos dos membros de um órgão com o nome informado, em todos os meses disponíveis. O nome é comparado sem diferenciar maiúsculas e acentos. Como pode haver homônimos, é retornada uma série por matrícula. Os valores não são corrigidos pela inflação.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetMembersByName",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do órgão. Exemplo: tjal",
+                        "name": "orgao",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nome completo do membro. Exemplo: maria jose da silva",
+                        "name": "nome",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Totais mensais dos membros",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/uiapi.memberTimeSeries"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetro nome não informado",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Membro não encontrado",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/membro/{orgao}/{matricula}": {
+            "get": {
+                "description": "Retorna, mês a mês, os totais de remuneração base, outras remunerações e descontos de um membro, identificado pelo órgão e pela matrícula, em todos os meses disponíveis. Os valores não são corrigidos pela inflação.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetMemberByRegistration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do órgão. Exemplo: tjal",
+                        "name": "orgao",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Matrícula do membro no órgão",
+                        "name": "matricula",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Totais mensais do membro",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.memberTimeSeries"
+                        }
+                    },
+                    "404": {
+                        "description": "Membro não encontrado",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/orgao/media/{ano}": {
+            "get": {
+                "description": "Busca médias (remuneração base, outras remunerações, descontos e remuneração total) de cada órgão em um ano especificado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetAveragePerAgency",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Ano para filtrar os dados",
+                        "name": "ano",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao. As médias são corrigidas pela média dos fatores dos meses do ano e o mês base usado é informado no cabeçalho X-Correcao-Monetaria.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lista de dados de médias dos órgãos",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/uiapi.averagePerAgency"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetro ANO inválido",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao buscar dados",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/orgao/resumo/{orgao}/{ano}/{mes}": {
+            "get": {
+                "description": "Endpoint de resumo das remunerações mensal de um órgão. Fornece uma análise financeira abrangente para um órgão específico em um mês e ano determinados\n\nInformações Financeiras Detalhadas:\n- Remuneração base total e máxima\n- Outras remunerações e benefícios\n- Valor total de descontos\n- Contagem de membros\n- Análise de rubricas (penduricalhos) específicas\n\nContexto Adicional:\n- Marcadores de existência de dados anteriores/posteriores ao ano/mês consultados\n- Timestamp da coleta de dados\n- Detalhamento de diferentes tipos de remuneração (remuneração base, outras remunerações e descontos)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetSummaryOfAgency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sigla do órgão. Ex.: tjal, mppb, tjmmg",
+                        "name": "orgao",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês de referência (1-12)",
+                        "name": "mes",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ano de referência",
+                        "name": "ano",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resumo financeiro do órgão processado com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.v2AgencySummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Erro de validação dos parâmetros de entrada",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Órgão ou dados não encontrados",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno durante processamento da consulta",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/orgao/salario/{orgao}/{ano}/{mes}": {
+            "get": {
+                "description": "Endpoint de análise detalhada de remunerações dos órgãos. Recupera informações financeiras granulares para um órgão específico em um mês e ano determinados\n\nInformações Fornecidos:\n- Remuneração máxima no período\n- Histograma de distribuição de rendimentos\n- Metadados do pacote de dados\n\nHistograma de Distribuição de Rendimentos:\n\nO histograma apresenta a quantidade de membros que receberam diferentes faixas de remunerações em um determinado mês.\nAs faixas de remuneração são definidas da seguinte forma:\n- 10000: quantidade de membros que receberam até R$ 10.000,00\n- 20000: quantidade de membros que receberam entre R$ 10.000,01 e R$ 20.000,00\n- 30000: quantidade de membros que receberam entre R$ 20.000,01 e R$ 30.000,00\n- 40000: quantidade de membros que receberam entre R$ 30.000,01 e R$ 40.000,00\n- 50000: quantidade de membros que receberam entre R$ 40.000,01 e R$ 50.000,00\n- -1: quantidade de membros que receberam acima de R$ 50.000,01",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetSalaryOfAgencyMonthYear",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sigla do órgão. Ex.: tjal, mppb, tjmmg",
+                        "name": "orgao",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês de referência (1-12)",
+                        "name": "mes",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ano de referência",
+                        "name": "ano",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dados de remuneração processados com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.agencyRemuneration"
+                        }
+                    },
+                    "206": {
+                        "description": "Dados coletados com informações de processamento",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.v2ProcInfoResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Erro de validação dos parâmetros de entrada",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno durante processamento da consulta",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/orgao/totais/{orgao}": {
+            "get": {
+                "description": "Retorna os totais mensais de um órgão em um intervalo de meses, que pode abranger vários anos, no mesmo formato de /uiapi/v2/orgao/totais/{orgao}/{ano}. Cada mês informa o seu ano e a média por membro é calculada com os meses do intervalo: os totais divididos pela soma da quantidade de membros de cada mês.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetTotalsOfAgencyRange",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identificador do órgão público",
+                        "name": "orgao",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Totais mensais do órgão no intervalo",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.agencyTotalsRange"
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros inválidos",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/orgao/totais/{orgao}/{ano}": {
+            "get": {
+                "description": "Recupera dados financeiros detalhados para um órgão em um ano específico\n\nDados Financeiros Mensais:\n- Remuneração base\n- Outras remunerações e benefícios\n- Descontos\n- Contagem de membros\n\nMétricas Adicionais:\n- Médias per capita\n- Detalhamento de rubricas (auxílios, férias, gratificações)\n- Informações gerais sobre o órgão pesquisado\n- Informações sobre o pacote de dados (URL para download, hash, tamanho)\n- Anomalias calculadas pela API, comparando cada mês com a mediana dos 6 meses anteriores com dados (ver /uiapi/v2/anomalias)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetTotalsOfAgencyYear",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identificador do órgão público",
+                        "name": "orgao",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
                         "description": "Ano de referência para a consulta",
                         "name": "ano",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dados financeiros completos do órgão no ano especificado",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.v2AgencyTotalsYear"
+                        }
+                    },
+                    "400": {
+                        "description": "Erro de validação: parâmetros de órgão ou ano inválidos",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno durante processamento da consulta",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/orgao/{grupo}": {
+            "get": {
+                "description": "Busca informações de id (sigla), nome e entidade (jurisdiçãso) de um determinado grupo de órgãos. Ao Selecionar um grupo de órgãos por estado (Ex.: RJ, SP, etc.), retorna as informações dos tribunais de justiça desse estado (entidade=Tribunal).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetBasicInfoOfType",
+                "parameters": [
+                    {
+                        "enum": [
+                            "justica-eleitoral",
+                            "ministerios-publicos",
+                            "justica-estadual",
+                            "justica-do-trabalho",
+                            "justica-federal",
+                            "justica-militar",
+                            "justica-superior",
+                            "conselhos-de-justica",
+                            "AC",
+                            "AL",
+                            "AP",
+                            "AM",
+                            "BA",
+                            "CE",
+                            "DF",
+                            "ES",
+                            "GO",
+                            "MA",
+                            "MT",
+                            "MS",
+                            "MG",
+                            "PA",
+                            "PB",
+                            "PR",
+                            "PE",
+                            "PI",
+                            "RJ",
+                            "RN",
+                            "RS",
+                            "RO",
+                            "RR",
+                            "SC",
+                            "SP",
+                            "SE",
+                            "TO"
+                        ],
+                        "type": "string",
+                        "description": "Grupo de órgãos",
+                        "name": "grupo",
+                        "in": "path"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Órgãos do grupo",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.state"
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetro inválido",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Grupo não encontrado",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/pesquisar": {
+            "get": {
+                "description": "Endpoint de busca avançada para remunerações de servidores públicos\n\nPermite realizar pesquisas detalhadas nas remunerações de servidores públicos com múltiplos filtros flexíveis:\n\n- Filtragem por anos específicos\n- Seleção de meses específicos\n- Pesquisa por órgãos públicos de diferentes esferas, individualmente ou por grupo, UF e entidade. Os filtros de órgãos são combinados entre si (ex: grupos=justica-estadual\u0026ufs=AL,PB retorna os tribunais estaduais de Alagoas e da Paraíba)\n- Categorias de remuneração\n- Nome, cargo e lotação do membro e detalhamento do contracheque (ex: auxílio-moradia), sem diferenciar maiúsculas e acentos\n- Faixa de valores das linhas dos contracheques, com ordenação dos resultados (ex: maiores valores primeiro)\n\nCaracterísticas principais:\n- Suporta múltiplas seleções em cada filtro\n- Permite combinações complexas de busca\n- Retorna dados consolidados de remuneração dos contracheques por membros\n- Resultados paginados: o campo 'proximo' da resposta deve ser passado no parâmetro de mesmo nome para obter a página seguinte\n- Com agrupar=membro, retorna um contracheque por membro e mês, com os totais de base, outras, descontos e o valor líquido\n\nCasos de uso:\n- Análise comparativa de remunerações entre diferentes órgãos\n- Análise análise granular das remunerações por membros dos órgãos",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "SearchByUrl",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lista de anos a serem pesquisados, separados por virgula. Exemplo: 2018,2019,2020",
+                        "name": "anos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lista de meses a serem pesquisados, separados por virgula. Exemplo: 1,2,3",
+                        "name": "meses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lista de órgãos a serem pesquisados, separados por virgula. Exemplo: tjal,mpal,mppb",
+                        "name": "orgaos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grupos de órgãos a serem pesquisados, separados por vírgula: justica-eleitoral, ministerios-publicos, justica-estadual, justica-do-trabalho, justica-federal, justica-militar, justica-superior e conselhos-de-justica. Exemplo: justica-estadual",
+                        "name": "grupos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UFs dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: AL,PB,PE",
+                        "name": "ufs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entidades dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: Tribunal",
+                        "name": "entidades",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Categorias a serem pesquisadas, separadas por vírgula: base (salário), outras (benefícios) e descontos. Substitui o antigo parâmetro tipos, que não é mais aceito. Exemplo: base,outras",
+                        "name": "categorias",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho do nome do membro. Exemplo: maria jose",
+                        "name": "nome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho do cargo do membro. Exemplo: juiz",
+                        "name": "cargo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho da lotação do membro. Exemplo: maceio",
+                        "name": "lotacao",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho do detalhamento do contracheque. Exemplo: auxilio-moradia",
+                        "name": "detalhamento_contracheque",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Valor mínimo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 1000.50 ou 1.000,50",
+                        "name": "valor_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Valor máximo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 50000",
+                        "name": "valor_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ordenação dos resultados no formato 'campo direção'. Campos: orgao, mes, ano, nome, cargo, lotacao, detalhamento_contracheque e valor. Direções: asc (padrão) e desc. Na ordenação por valor, as linhas com valor inválido ficam de fora. Exemplo: valor desc",
+                        "name": "ordenar",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "membro"
+                        ],
+                        "type": "string",
+                        "description": "Agrupa as linhas dos contracheques por membro, mês e ano, somando base, outras e descontos e calculando o valor líquido. Nesse caso, o campo 'result' contém objetos memberResult e, na ordenação, 'valor' corresponde ao valor líquido",
+                        "name": "agrupar",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor da próxima página, retornado no campo 'proximo' da página anterior. Os demais parâmetros devem ser os mesmos da página anterior",
+                        "name": "proximo",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Requisição bem-sucedida com dados de remuneração",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.searchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Erro de validação dos parâmetros de busca",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor durante processamento da pesquisa",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/ranking": {
+            "get": {
+                "description": "Classifica os órgãos por uma métrica no período informado. O valor de cada órgão é a média mensal da métrica nos meses com dados. Também retorna a posição e o valor de cada órgão no período anterior, de mesma duração, e a variação entre os dois. Por exemplo, o período anterior a 2023 é 2022 e o anterior a abril a junho de 2023 é janeiro a março de 2023.\n\nMétricas:\n- remuneracao: remuneração líquida (salário + benefícios - descontos) por membro\n- remuneracao_base: remuneração base (salário) por membro\n- outras: outras remunerações (benefícios) por membro\n- descontos: descontos por membro\n- rubrica: valor da rubrica informada por membro\n- indice: índice de transparência",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetRanking",
+                "parameters": [
+                    {
+                        "enum": [
+                            "remuneracao",
+                            "remuneracao_base",
+                            "outras",
+                            "descontos",
+                            "rubrica",
+                            "indice"
+                        ],
+                        "type": "string",
+                        "description": "Métrica usada na classificação. Padrão: remuneracao",
+                        "name": "metrica",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rubrica usada com metrica=rubrica. Exemplo: auxilio_saude",
+                        "name": "rubrica",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Anos do período, separados por vírgula. Exemplo: 2023. Obrigatório se de e ate não forem informados.",
+                        "name": "anos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Meses do período, separados por vírgula. Exemplo: 4,5,6",
+                        "name": "meses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Órgãos a serem classificados, separados por vírgula. Sem filtros, todos os órgãos são classificados.",
+                        "name": "orgaos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grupos de órgãos a serem classificados, separados por vírgula. Exemplo: justica-estadual",
+                        "name": "grupos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UFs dos órgãos a serem classificados, separadas por vírgula. Exemplo: AL,PE",
+                        "name": "ufs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entidades dos órgãos a serem classificados, separadas por vírgula. Exemplo: Tribunal",
+                        "name": "entidades",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Direção da classificação. Padrão: desc",
+                        "name": "ordem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade de órgãos retornados. Sem ele, todos os órgãos são retornados.",
+                        "name": "limite",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ranking dos órgãos",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.agencyRanking"
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros inválidos",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/readme": {
+            "get": {
+                "description": "Recupera o arquivo README com informações sobre o conjunto de dados. Permite filtrar o README com base em parâmetros opcionais de ano, mês e órgão\n\nComportamentos:\n- Se nenhum filtro for aplicado, retorna o README original\n- Com filtro de órgão, gera um README com observações específicas sobre potenciais falhas nos dados",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "DownloadReadme",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "2024",
+                        "description": "Ano para filtragem dos dados",
+                        "name": "ano",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "12",
+                        "description": "Mês para filtragem dos dados",
+                        "name": "mes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "tjrr",
+                        "description": "Sigla do órgão para filtragem. Ex.: tjal, mppb, mpdft",
+                        "name": "orgao",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "README.txt com conteúdo detalhado",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Erro de validação de parâmetros (ano/mês inválidos)",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno ao processar o README",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/rubricas": {
+            "get": {
+                "description": "Lista as rubricas encontradas no resumo dos contracheques (campo resumo_rubricas das rotas de totais e resumos) de todos os órgãos e anos com dados. As rubricas conhecidas têm descrição.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetItems",
+                "responses": {
+                    "200": {
+                        "description": "Rubricas encontradas nos dados",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/uiapi.itemDescription"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/rubricas/{rubrica}": {
+            "get": {
+                "description": "Retorna os totais mensais de uma rubrica em cada órgão. Sem filtros, considera todos os órgãos em todos os anos com dados. Os valores não são corrigidos pela inflação. Os totais vêm do resumo dos contracheques, por isso os filtros das linhas da pesquisa (categorias, nome, cargo, lotacao, detalhamento_contracheque, valor_min, valor_max, ordenar e agrupar) não são aceitos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetItemTimeSeries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rubrica, como listada em /uiapi/v2/rubricas. Exemplo: auxilio_saude",
+                        "name": "rubrica",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Anos a serem considerados, separados por vírgula. Exemplo: 2022,2023",
+                        "name": "anos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Meses a serem considerados, separados por vírgula. Exemplo: 1,2,3",
+                        "name": "meses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grupos de órgãos a serem considerados, separados por vírgula. Exemplo: justica-estadual,ministerios-publicos",
+                        "name": "grupos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UFs dos órgãos a serem considerados, separadas por vírgula. Exemplo: AL,PB",
+                        "name": "ufs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entidades dos órgãos a serem consideradas, separadas por vírgula. Exemplo: Tribunal",
+                        "name": "entidades",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Órgãos a serem considerados, separados por vírgula. Exemplo: tjal,mpal",
+                        "name": "orgaos",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Dados financeiros completos do órgão no ano especificado",
+                        "description": "Totais mensais da rubrica por órgão",
                         "schema": {
-                            "$ref": "#/definitions/uiapi.v2AgencyTotalsYear"
+                            "$ref": "#/definitions/uiapi.itemTimeSeries"
                         }
                     },
                     "400": {
-                        "description": "Erro de validação: parâmetros de órgão ou ano inválidos",
+                        "description": "Parâmetros inválidos ou não aceitos pela rota",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Rubrica não encontrada",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Erro interno durante processamento da consulta",
+                        "description": "Erro interno do servidor",
                         "schema": {
                             "type": "string"
                         }
@@ -409,76 +1771,101 @@ const docTemplate = `{
                 }
             }
         },
-        "/uiapi/v2/orgao/{grupo}": {
+        "/uiapi/v2/teto": {
             "get": {
-                "description": "Busca informações de id (sigla), nome e entidade (jurisdiçãso) de um determinado grupo de órgãos. Ao Selecionar um grupo de órgãos por estado (Ex.: RJ, SP, etc.), retorna as informações dos tribunais de justiça desse estado (entidade=Tribunal).",
+                "description": "Retorna a tabela de valores do teto constitucional (subsídio dos ministros do STF) usada no cálculo dos membros acima do teto, com o início da vigência de cada valor.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "ui_api"
                 ],
-                "operationId": "GetBasicInfoOfType",
+                "operationId": "GetTetoTable",
+                "responses": {
+                    "200": {
+                        "description": "Tabela do teto constitucional",
+                        "schema": {
+                            "$ref": "#/definitions/uiapi.tetoTableResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uiapi/v2/teto/excedentes": {
+            "get": {
+                "description": "Retorna, para cada órgão e mês, a quantidade e o percentual de membros com remuneração líquida (salário + benefícios - descontos) acima do teto constitucional vigente no mês, e a soma do que excedeu o teto. Também retorna os totais do período. É obrigatório informar os anos e ao menos um filtro de órgãos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ui_api"
+                ],
+                "operationId": "GetTetoBreaches",
                 "parameters": [
                     {
-                        "enum": [
-                            "justica-eleitoral",
-                            "ministerios-publicos",
-                            "justica-estadual",
-                            "justica-do-trabalho",
-                            "justica-federal",
-                            "justica-militar",
-                            "justica-superior",
-                            "conselhos-de-justica",
-                            "AC",
-                            "AL",
-                            "AP",
-                            "AM",
-                            "BA",
-                            "CE",
-                            "DF",
-                            "ES",
-                            "GO",
-                            "MA",
-                            "MT",
-                            "MS",
-                            "MG",
-                            "PA",
-                            "PB",
-                            "PR",
-                            "PE",
-                            "PI",
-                            "RJ",
-                            "RN",
-                            "RS",
-                            "RO",
-                            "RR",
-                            "SC",
-                            "SP",
-                            "SE",
-                            "TO"
-                        ],
                         "type": "string",
-                        "description": "Grupo de órgãos",
-                        "name": "grupo",
-                        "in": "path"
+                        "description": "Anos a serem considerados, separados por vírgula. Exemplo: 2023,2024. Obrigatório se de e ate não forem informados.",
+                        "name": "anos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Meses a serem considerados, separados por vírgula. Exemplo: 1,2,3",
+                        "name": "meses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Órgãos a serem considerados, separados por vírgula. Exemplo: tjal,mpal",
+                        "name": "orgaos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grupos de órgãos a serem considerados, separados por vírgula. Exemplo: justica-estadual",
+                        "name": "grupos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UFs dos órgãos a serem considerados, separadas por vírgula. Exemplo: AL,PB",
+                        "name": "ufs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entidades dos órgãos a serem consideradas, separadas por vírgula. Exemplo: Tribunal",
+                        "name": "entidades",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Órgãos do grupo",
+                        "description": "Membros acima do teto por órgão e mês",
                         "schema": {
-                            "$ref": "#/definitions/uiapi.state"
+                            "$ref": "#/definitions/uiapi.tetoBreachesResponse"
                         }
                     },
                     "400": {
-                        "description": "Parâmetro inválido",
+                        "description": "Parâmetros inválidos",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Grupo não encontrado",
+                    "500": {
+                        "description": "Erro interno do servidor",
                         "schema": {
                             "type": "string"
                         }
@@ -486,62 +1873,99 @@ const docTemplate = `{
                 }
             }
         },
-        "/uiapi/v2/pesquisar": {
+        "/v2/agregados": {
             "get": {
-                "description": "Endpoint de busca avançada para remunerações de servidores públicos\n\nPermite realizar pesquisas detalhadas nas remunerações de servidores públicos com múltiplos filtros flexíveis:\n\n- Filtragem por anos específicos\n- Seleção de meses específicos\n- Pesquisa por órgãos públicos de diferentes esferas\n- Categorias de remuneração\n\nCaracterísticas principais:\n- Suporta múltiplas seleções em cada filtro\n- Permite combinações complexas de busca\n- Retorna dados consolidados de remuneração dos contracheques por membros\n\nCasos de uso:\n- Análise comparativa de remunerações entre diferentes órgãos\n- Análise análise granular das remunerações por membros dos órgãos",
+                "description": "Agrega os dados mensais dos órgãos pelas dimensões informadas (órgão, jurisdição, UF, ano e mês), calculando para cada agregado as medidas pedidas: totais (remuneração base/salário, outras remunerações/benefícios, descontos e remunerações líquidas), médias mensais por membro, quantidades (órgãos, meses com dados e média mensal de membros) e gastos por rubrica. Sem dimensões, todos os dados são agregados em um único item. Os meses sem dados de remuneração são ignorados.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ui_api"
+                    "public_api"
                 ],
-                "operationId": "SearchByUrl",
+                "operationId": "GetAggregates",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Lista de anos a serem pesquisados, separados por virgula. Exemplo: 2018,2019,2020",
+                        "description": "Anos, separados por vírgula. Ex.: 2022,2023. Obrigatório se de e ate não forem informados.",
                         "name": "anos",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Lista de meses a serem pesquisados, separados por virgula. Exemplo: 1,2,3",
+                        "description": "Meses (1-12), separados por vírgula. Padrão: todos os meses.",
                         "name": "meses",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Lista de órgãos a serem pesquisados, separados por virgula. Exemplo: tjal,mpal,mppb",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dimensões da agregação, separadas por vírgula: orgao, grupo, uf, ano e mes.",
+                        "name": "dimensoes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Medidas, separadas por vírgula: totais, medias, quantidades e rubricas. Padrão: totais, medias e quantidades.",
+                        "name": "medidas",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Siglas dos órgãos, separadas por vírgula. Ex.: tjal,tjba",
                         "name": "orgaos",
                         "in": "query"
                     },
                     {
-                        "enum": [
-                            "base",
-                            "outras",
-                            "descontos"
-                        ],
                         "type": "string",
-                        "description": "Categorias a serem pesquisadas. Remuneração base (salário), outras remunerações (benefícios) e descontos",
-                        "name": "categorias",
+                        "description": "Jurisdições dos órgãos, separadas por vírgula. Ex.: justica-estadual,ministerios-publicos",
+                        "name": "grupos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UFs dos órgãos, separadas por vírgula. Ex.: AL,BA",
+                        "name": "ufs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Requisição bem-sucedida com dados de remuneração",
+                        "description": "Requisição bem sucedida.",
                         "schema": {
-                            "$ref": "#/definitions/uiapi.searchResponse"
+                            "$ref": "#/definitions/papi.aggregation"
                         }
                     },
                     "400": {
-                        "description": "Erro de validação dos parâmetros de busca",
+                        "description": "Parâmetros inválidos.",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Erro interno do servidor durante processamento da pesquisa",
+                        "description": "Erro interno do servidor.",
                         "schema": {
                             "type": "string"
                         }
@@ -549,54 +1973,156 @@ const docTemplate = `{
                 }
             }
         },
-        "/uiapi/v2/readme": {
+        "/v2/atualizacoes": {
             "get": {
-                "description": "Recupera o arquivo README com informações sobre o conjunto de dados. Permite filtrar o README com base em parâmetros opcionais de ano, mês e órgão\n\nComportamentos:\n- Se nenhum filtro for aplicado, retorna o README original\n- Com filtro de órgão, gera um README com observações específicas sobre potenciais falhas nos dados",
+                "description": "Lista os meses dos órgãos coletados ou recoletados depois de uma marca temporal, em ordem de coleta, com as versões do coletor e do parser. Apenas a coleta vigente de cada mês é listada.\n- Resultados paginados: enquanto a resposta tiver o campo proximo, ele deve ser passado no parâmetro de mesmo nome, com o mesmo desde, para obter a página seguinte\n- Para sincronizar uma cópia dos dados, use o campo ultima_coleta da última página como o parâmetro desde da próxima consulta",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "ui_api"
+                    "public_api"
                 ],
-                "operationId": "DownloadReadme",
+                "operationId": "GetUpdates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Marca temporal no formato RFC 3339 (ex.: 2023-01-02T15:04:05Z) ou data no formato AAAA-MM-DD, considerada à meia-noite no horário de Brasília",
+                        "name": "desde",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor da próxima página, retornado no campo proximo da página anterior",
+                        "name": "proximo",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Requisição bem sucedida",
+                        "schema": {
+                            "$ref": "#/definitions/papi.collectionUpdates"
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetro desde ou proximo inválido",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/dados": {
+            "get": {
+                "description": "Busca os dados mensais de vários órgãos de uma só vez, no mesmo formato de /v2/dados/{orgao}/{ano}, para todos os anos do intervalo informado. Os dados são buscados com uma consulta ao banco por ano do intervalo, por isso os anos devem estar entre 2018 e o ano atual. Os órgãos podem ser filtrados por sigla, jurisdição e UF; sem filtros, todos os órgãos são retornados. Os dados são ordenados por ano, órgão e mês e enviados à medida que são lidos, como um array JSON (formato=json) ou com um objeto JSON por linha (formato=jsonl). Se houver um erro depois que o envio começou, a conexão é interrompida, para que uma resposta incompleta não pareça completa.",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "public_api"
+                ],
+                "operationId": "GetMonthlyInfosOfAgencies",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "2024",
-                        "description": "Ano para filtragem dos dados",
-                        "name": "ano",
+                        "description": "Siglas dos órgãos, separadas por vírgula. Ex.: tjal,tjba",
+                        "name": "orgaos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Jurisdições dos órgãos, separadas por vírgula. Ex.: justica-estadual,ministerios-publicos",
+                        "name": "grupos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UFs dos órgãos, separadas por vírgula. Ex.: AL,BA",
+                        "name": "ufs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Obsoleto: use grupos. Aceito apenas quando grupos não é informado.",
+                        "name": "grupo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Obsoleto: use ufs. Aceito apenas quando ufs não é informado.",
+                        "name": "uf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Primeiro ano do intervalo, a partir de 2018. Padrão: 2018.",
+                        "name": "ano_inicio",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Último ano do intervalo, até o ano atual. Padrão: ano atual.",
+                        "name": "ano_fim",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com ano_inicio e ano_fim.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com ano_inicio e ano_fim.",
+                        "name": "ate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Formato da resposta: json (padrão) ou jsonl.",
+                        "name": "formato",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "default": "12",
-                        "description": "Mês para filtragem dos dados",
-                        "name": "mes",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao.",
+                        "name": "corrigir",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "default": "tjrr",
-                        "description": "Sigla do órgão para filtragem. Ex.: tjal, mppb, mpdft",
-                        "name": "orgao",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "README.txt com conteúdo detalhado",
+                        "description": "Requisição bem-sucedida com dados mensais",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/papi.summaryzedMI"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Erro de validação de parâmetros (ano/mês inválidos)",
+                        "description": "Parâmetros inválidos",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Erro interno ao processar o README",
+                        "description": "Erro interno do servidor",
                         "schema": {
                             "type": "string"
                         }
@@ -621,6 +2147,30 @@ const docTemplate = `{
                         "name": "orgao",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -663,6 +2213,18 @@ const docTemplate = `{
                         "name": "orgao",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -721,6 +2283,18 @@ const docTemplate = `{
                         "name": "mes",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao.",
+                        "name": "corrigir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível.",
+                        "name": "base",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -767,6 +2341,18 @@ const docTemplate = `{
                         "description": "Define se os metadados utilizados para calcular o índice serão retornados ou não.",
                         "name": "detalhe",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -824,6 +2410,18 @@ const docTemplate = `{
                         "description": "Define se os metadados utilizados para calcular o índice serão retornados ou não.",
                         "name": "detalhe",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com ano e mês. Padrão: 2018-01.",
+                        "name": "de",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com ano e mês. Padrão: mês atual.",
+                        "name": "ate",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1061,9 +2659,102 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/v2/snapshot": {
+            "get": {
+                "description": "Baixa um snapshot de todos os dados do DadosJusBr: um arquivo zip com as tabelas orgaos, coletas (resumos mensais e índices de transparência) e remuneracoes_zips (links para os arquivos de remunerações), em JSON e CSV, e um manifesto com a data do snapshot e o hash SHA-256 de cada arquivo. Um snapshot é gerado por dia, em segundo plano, e guardado, para que uma versão exata dos dados possa ser citada e baixada novamente. Enquanto o snapshot do dia não fica pronto, a rota responde 503.\n\nA tabela coletas traz os mesmos meses das rotas de dados mensais: os meses em que a coleta falhou não fazem parte dela.",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "public_api"
+                ],
+                "operationId": "GetSnapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Data do snapshot, no formato AAAA-MM-DD. Padrão: data de hoje.",
+                        "name": "data",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Arquivo zip do snapshot",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetro data inválido",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Snapshot não encontrado",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Snapshot do dia ainda não gerado",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "daterange.Month": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "type": "integer"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "daterange.Range": {
+            "type": "object",
+            "properties": {
+                "ate": {
+                    "$ref": "#/definitions/daterange.Month"
+                },
+                "de": {
+                    "$ref": "#/definitions/daterange.Month"
+                }
+            }
+        },
+        "ipca.Correction": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "description": "Mês base, no formato AAAA-MM",
+                    "type": "string"
+                },
+                "indice": {
+                    "type": "string"
+                },
+                "serie_fim": {
+                    "type": "string"
+                },
+                "serie_inicio": {
+                    "description": "Primeiro e último meses da série, no formato AAAA-MM. Os valores dos\nmeses fora deste intervalo não são corrigidos.",
+                    "type": "string"
+                }
+            }
+        },
         "papi.agency": {
             "type": "object",
             "properties": {
@@ -1111,6 +2802,23 @@ const docTemplate = `{
                 }
             }
         },
+        "papi.aggregateCounts": {
+            "type": "object",
+            "properties": {
+                "media_mensal_membros": {
+                    "description": "Média mensal da soma dos membros dos órgãos",
+                    "type": "number"
+                },
+                "meses_com_dados": {
+                    "description": "Quantidade de meses com dados, somando todos os órgãos",
+                    "type": "integer"
+                },
+                "orgaos": {
+                    "description": "Quantidade de órgãos com dados",
+                    "type": "integer"
+                }
+            }
+        },
         "papi.aggregateIndexes": {
             "type": "object",
             "properties": {
@@ -1188,6 +2896,90 @@ const docTemplate = `{
                 }
             }
         },
+        "papi.aggregateValues": {
+            "type": "object",
+            "properties": {
+                "descontos": {
+                    "type": "number"
+                },
+                "outras_remuneracoes": {
+                    "type": "number"
+                },
+                "remuneracao_base": {
+                    "type": "number"
+                },
+                "remuneracoes": {
+                    "type": "number"
+                }
+            }
+        },
+        "papi.aggregation": {
+            "type": "object",
+            "properties": {
+                "agregados": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/papi.aggregationRow"
+                    }
+                },
+                "correcao_monetaria": {
+                    "$ref": "#/definitions/ipca.Correction"
+                },
+                "dimensoes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "medidas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "papi.aggregationRow": {
+            "type": "object",
+            "properties": {
+                "ano": {
+                    "type": "integer"
+                },
+                "grupo": {
+                    "type": "string"
+                },
+                "id_orgao": {
+                    "type": "string"
+                },
+                "medias": {
+                    "description": "Médias mensais por membro",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/papi.aggregateValues"
+                        }
+                    ]
+                },
+                "mes": {
+                    "type": "integer"
+                },
+                "quantidades": {
+                    "$ref": "#/definitions/papi.aggregateCounts"
+                },
+                "rubricas": {
+                    "$ref": "#/definitions/papi.itemSummary"
+                },
+                "sem_correcao": {
+                    "description": "Algum mês do agregado tem valores nominais, pois seu IPCA não está na série.",
+                    "type": "boolean"
+                },
+                "totais": {
+                    "$ref": "#/definitions/papi.aggregateValues"
+                },
+                "uf": {
+                    "type": "string"
+                }
+            }
+        },
         "papi.allAgencyInformation": {
             "type": "object",
             "properties": {
@@ -1280,19 +3072,75 @@ const docTemplate = `{
                 }
             }
         },
-        "papi.collecting": {
+        "papi.collecting": {
+            "type": "object",
+            "properties": {
+                "descricao": {
+                    "description": "Reasons why we didn't collect the data",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "timestamp": {
+                    "description": "Day(unix) we checked the status of the data",
+                    "type": "integer"
+                }
+            }
+        },
+        "papi.collectionUpdate": {
+            "type": "object",
+            "properties": {
+                "ano": {
+                    "type": "integer"
+                },
+                "id_orgao": {
+                    "type": "string"
+                },
+                "mes": {
+                    "type": "integer"
+                },
+                "repositorio_coletor": {
+                    "type": "string"
+                },
+                "repositorio_parser": {
+                    "description": "Apenas quando o parser é diferente do coletor",
+                    "type": "string"
+                },
+                "timestamp_coleta": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Dados do mês na API pública",
+                    "type": "string"
+                },
+                "versao_coletor": {
+                    "type": "string"
+                },
+                "versao_parser": {
+                    "type": "string"
+                }
+            }
+        },
+        "papi.collectionUpdates": {
             "type": "object",
             "properties": {
-                "descricao": {
-                    "description": "Reasons why we didn't collect the data",
+                "atualizacoes": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/papi.collectionUpdate"
                     }
                 },
-                "timestamp": {
-                    "description": "Day(unix) we checked the status of the data",
-                    "type": "integer"
+                "desde": {
+                    "type": "string"
+                },
+                "proximo": {
+                    "description": "Cursor da próxima página, vazio na última página",
+                    "type": "string"
+                },
+                "ultima_coleta": {
+                    "description": "Marca da coleta mais recente da página, null se não há atualizações",
+                    "type": "string"
                 }
             }
         },
@@ -1442,6 +3290,9 @@ const docTemplate = `{
                 "coleta_manual": {
                     "type": "boolean"
                 },
+                "correcao_monetaria": {
+                    "$ref": "#/definitions/ipca.Correction"
+                },
                 "dados_coleta": {
                     "$ref": "#/definitions/papi.collect"
                 },
@@ -1466,6 +3317,10 @@ const docTemplate = `{
                 "pacote_de_dados": {
                     "$ref": "#/definitions/papi.backup"
                 },
+                "sem_correcao": {
+                    "description": "Valores nominais apesar de corrigir, pois o IPCA do mês não está na série",
+                    "type": "boolean"
+                },
                 "sumarios": {
                     "$ref": "#/definitions/papi.summaries"
                 }
@@ -1532,6 +3387,85 @@ const docTemplate = `{
                 }
             }
         },
+        "uiapi.agencyComparison": {
+            "type": "object",
+            "properties": {
+                "correcao_monetaria": {
+                    "$ref": "#/definitions/ipca.Correction"
+                },
+                "meses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uiapi.comparisonMonth"
+                    }
+                },
+                "orgaos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uiapi.agencyComparisonSeries"
+                    }
+                }
+            }
+        },
+        "uiapi.agencyComparisonSeries": {
+            "type": "object",
+            "properties": {
+                "id_orgao": {
+                    "type": "string"
+                },
+                "meses_com_dados": {
+                    "type": "integer"
+                },
+                "posicao": {
+                    "description": "Posição no período, ausente se o órgão não tem dados",
+                    "type": "integer"
+                },
+                "remuneracoes_por_membro": {
+                    "description": "Média mensal da remuneração líquida por membro",
+                    "type": "number"
+                },
+                "sem_correcao": {
+                    "description": "A média inclui meses com valores nominais, pois seu IPCA não está na série",
+                    "type": "boolean"
+                },
+                "totais": {
+                    "description": "Alinhado com os meses da comparação, null quando não há dados",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uiapi.comparisonTotals"
+                    }
+                }
+            }
+        },
+        "uiapi.agencyRanking": {
+            "type": "object",
+            "properties": {
+                "correcao_monetaria": {
+                    "$ref": "#/definitions/ipca.Correction"
+                },
+                "metrica": {
+                    "type": "string"
+                },
+                "ordem": {
+                    "type": "string"
+                },
+                "orgaos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uiapi.rankingPosition"
+                    }
+                },
+                "periodo": {
+                    "$ref": "#/definitions/uiapi.periodRange"
+                },
+                "periodo_anterior": {
+                    "$ref": "#/definitions/uiapi.periodRange"
+                },
+                "rubrica": {
+                    "type": "string"
+                }
+            }
+        },
         "uiapi.agencyRemuneration": {
             "type": "object",
             "properties": {
@@ -1549,9 +3483,35 @@ const docTemplate = `{
                 }
             }
         },
+        "uiapi.agencyTotalsRange": {
+            "type": "object",
+            "properties": {
+                "correcao_monetaria": {
+                    "$ref": "#/definitions/ipca.Correction"
+                },
+                "intervalo": {
+                    "$ref": "#/definitions/daterange.Range"
+                },
+                "media_por_membro": {
+                    "$ref": "#/definitions/uiapi.perCapitaData"
+                },
+                "meses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uiapi.v2MonthTotals"
+                    }
+                },
+                "orgao": {
+                    "$ref": "#/definitions/uiapi.agency"
+                }
+            }
+        },
         "uiapi.annualSummary": {
             "type": "object",
             "properties": {
+                "correcao_monetaria": {
+                    "$ref": "#/definitions/ipca.Correction"
+                },
                 "dados_anuais": {
                     "type": "array",
                     "items": {
@@ -1619,77 +3579,325 @@ const docTemplate = `{
                 },
                 "resumo_rubricas": {
                     "$ref": "#/definitions/uiapi.itemSummary"
+                },
+                "sem_correcao": {
+                    "description": "A correção anual inclui meses com valores nominais, pois seu IPCA não está na série",
+                    "type": "boolean"
+                }
+            }
+        },
+        "uiapi.anomaly": {
+            "type": "object",
+            "properties": {
+                "ano": {
+                    "type": "integer"
+                },
+                "desvio": {
+                    "description": "(valor - mediana) / mediana, null se a mediana é zero",
+                    "type": "number"
+                },
+                "id_orgao": {
+                    "type": "string"
+                },
+                "mediana_movel": {
+                    "description": "Mediana dos meses anteriores com dados",
+                    "type": "number"
+                },
+                "mes": {
+                    "type": "integer"
+                },
+                "rubrica": {
+                    "type": "string"
+                },
+                "tipo": {
+                    "description": "remuneracoes, num_membros ou rubrica",
+                    "type": "string"
+                },
+                "valor": {
+                    "type": "number"
+                }
+            }
+        },
+        "uiapi.averagePerAgency": {
+            "type": "object",
+            "properties": {
+                "id_orgao": {
+                    "type": "string"
+                },
+                "media_por_membro": {
+                    "$ref": "#/definitions/uiapi.perCapitaData"
+                }
+            }
+        },
+        "uiapi.backup": {
+            "type": "object",
+            "properties": {
+                "hash": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "uiapi.collecting": {
+            "type": "object",
+            "properties": {
+                "descricao": {
+                    "description": "Reasons why we didn't collect the data",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "timestamp": {
+                    "description": "Day(unix) we checked the status of the data",
+                    "type": "integer"
+                }
+            }
+        },
+        "uiapi.comparisonMonth": {
+            "type": "object",
+            "properties": {
+                "ano": {
+                    "type": "integer"
+                },
+                "mes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "uiapi.comparisonTotals": {
+            "type": "object",
+            "properties": {
+                "descontos": {
+                    "type": "number"
+                },
+                "descontos_por_membro": {
+                    "type": "number"
+                },
+                "outras_remuneracoes": {
+                    "type": "number"
+                },
+                "outras_remuneracoes_por_membro": {
+                    "type": "number"
+                },
+                "posicao": {
+                    "description": "Posição do órgão no mês",
+                    "type": "integer"
+                },
+                "remuneracao_base": {
+                    "type": "number"
+                },
+                "remuneracao_base_por_membro": {
+                    "type": "number"
+                },
+                "remuneracoes": {
+                    "type": "number"
+                },
+                "remuneracoes_por_membro": {
+                    "type": "number"
+                },
+                "sem_correcao": {
+                    "description": "Valores nominais apesar de corrigir, pois o IPCA do mês não está na série",
+                    "type": "boolean"
+                },
+                "total_membros": {
+                    "type": "integer"
+                }
+            }
+        },
+        "uiapi.exportJob": {
+            "type": "object",
+            "properties": {
+                "arquivo": {
+                    "description": "Rota para baixar o arquivo, preenchido ao concluir",
+                    "type": "string"
+                },
+                "criada_em": {
+                    "type": "string"
+                },
+                "erro": {
+                    "description": "Preenchido quando a exportação falha",
+                    "type": "string"
+                },
+                "expira_em": {
+                    "description": "Quando a exportação e seu arquivo serão apagados",
+                    "type": "string"
+                },
+                "finalizada_em": {
+                    "type": "string"
+                },
+                "formato": {
+                    "description": "csv, jsonl ou parquet",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "linhas": {
+                    "description": "Linhas escritas no arquivo, preenchido ao concluir",
+                    "type": "integer"
+                },
+                "parametros": {
+                    "description": "Query params da pesquisa, no mesmo formato da rota de download",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pendente, executando, concluida ou erro",
+                    "type": "string"
+                }
+            }
+        },
+        "uiapi.generalSummary": {
+            "type": "object",
+            "properties": {
+                "data_fim": {
+                    "type": "string"
+                },
+                "data_inicio": {
+                    "type": "string"
+                },
+                "num_contracheques": {
+                    "type": "integer"
+                },
+                "num_meses_coletados": {
+                    "type": "integer"
+                },
+                "num_orgaos": {
+                    "type": "integer"
+                },
+                "remuneracao_total": {
+                    "type": "number"
+                }
+            }
+        },
+        "uiapi.histogramBin": {
+            "type": "object",
+            "properties": {
+                "fim": {
+                    "description": "null na última faixa",
+                    "type": "number"
+                },
+                "inicio": {
+                    "description": "null na primeira faixa",
+                    "type": "number"
+                },
+                "quantidade": {
+                    "type": "integer"
                 }
             }
         },
-        "uiapi.averagePerAgency": {
+        "uiapi.itemAgencyTotals": {
             "type": "object",
             "properties": {
                 "id_orgao": {
                     "type": "string"
                 },
-                "media_por_membro": {
-                    "$ref": "#/definitions/uiapi.perCapitaData"
+                "meses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uiapi.itemMonthTotal"
+                    }
                 }
             }
         },
-        "uiapi.backup": {
+        "uiapi.itemDescription": {
             "type": "object",
             "properties": {
-                "hash": {
+                "descricao": {
                     "type": "string"
                 },
-                "size": {
+                "rubrica": {
+                    "type": "string"
+                }
+            }
+        },
+        "uiapi.itemMonthTotal": {
+            "type": "object",
+            "properties": {
+                "ano": {
                     "type": "integer"
                 },
-                "url": {
-                    "type": "string"
+                "mes": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "number"
                 }
             }
         },
-        "uiapi.collecting": {
+        "uiapi.itemSummary": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "number"
+            }
+        },
+        "uiapi.itemTimeSeries": {
             "type": "object",
             "properties": {
                 "descricao": {
-                    "description": "Reasons why we didn't collect the data",
+                    "type": "string"
+                },
+                "orgaos": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/uiapi.itemAgencyTotals"
                     }
                 },
-                "timestamp": {
-                    "description": "Day(unix) we checked the status of the data",
-                    "type": "integer"
+                "rubrica": {
+                    "type": "string"
                 }
             }
         },
-        "uiapi.generalSummary": {
+        "uiapi.memberMonth": {
             "type": "object",
             "properties": {
-                "data_fim": {
-                    "type": "string"
+                "ano": {
+                    "type": "integer"
                 },
-                "data_inicio": {
+                "base": {
+                    "type": "number"
+                },
+                "cargo": {
                     "type": "string"
                 },
-                "num_contracheques": {
-                    "type": "integer"
+                "descontos": {
+                    "type": "number"
                 },
-                "num_meses_coletados": {
-                    "type": "integer"
+                "lotacao": {
+                    "type": "string"
                 },
-                "num_orgaos": {
+                "mes": {
                     "type": "integer"
                 },
-                "remuneracao_total": {
+                "outras": {
                     "type": "number"
                 }
             }
         },
-        "uiapi.itemSummary": {
+        "uiapi.memberTimeSeries": {
             "type": "object",
-            "additionalProperties": {
-                "type": "number"
+            "properties": {
+                "matricula": {
+                    "type": "string"
+                },
+                "meses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uiapi.memberMonth"
+                    }
+                },
+                "nome": {
+                    "description": "Nome como aparece no primeiro contracheque encontrado",
+                    "type": "string"
+                },
+                "orgao": {
+                    "type": "string"
+                }
             }
         },
         "uiapi.mensalRemuneration": {
@@ -1715,6 +3923,10 @@ const docTemplate = `{
                 },
                 "resumo_rubricas": {
                     "$ref": "#/definitions/uiapi.itemSummary"
+                },
+                "sem_correcao": {
+                    "description": "Valores nominais apesar de corrigir, pois o IPCA do mês não está na série",
+                    "type": "boolean"
                 }
             }
         },
@@ -1732,6 +3944,44 @@ const docTemplate = `{
                 },
                 "remuneracoes": {
                     "type": "number"
+                },
+                "sem_correcao": {
+                    "description": "Inclui meses com valores nominais, pois seu IPCA não está na série",
+                    "type": "boolean"
+                }
+            }
+        },
+        "uiapi.percentiles": {
+            "type": "object",
+            "properties": {
+                "p10": {
+                    "type": "number"
+                },
+                "p25": {
+                    "type": "number"
+                },
+                "p50": {
+                    "type": "number"
+                },
+                "p75": {
+                    "type": "number"
+                },
+                "p90": {
+                    "type": "number"
+                },
+                "p99": {
+                    "type": "number"
+                }
+            }
+        },
+        "uiapi.periodRange": {
+            "type": "object",
+            "properties": {
+                "fim": {
+                    "type": "string"
+                },
+                "inicio": {
+                    "type": "string"
                 }
             }
         },
@@ -1777,6 +4027,96 @@ const docTemplate = `{
                 }
             }
         },
+        "uiapi.rankingPosition": {
+            "type": "object",
+            "properties": {
+                "id_orgao": {
+                    "type": "string"
+                },
+                "meses_com_dados": {
+                    "type": "integer"
+                },
+                "posicao": {
+                    "type": "integer"
+                },
+                "posicao_anterior": {
+                    "description": "null se o órgão não tem dados no período anterior",
+                    "type": "integer"
+                },
+                "sem_correcao": {
+                    "description": "O valor inclui meses com valores nominais, pois seu IPCA não está na série",
+                    "type": "boolean"
+                },
+                "valor": {
+                    "description": "Média mensal da métrica nos meses com dados",
+                    "type": "number"
+                },
+                "valor_anterior": {
+                    "type": "number"
+                },
+                "variacao": {
+                    "description": "Valor menos o valor anterior",
+                    "type": "number"
+                },
+                "variacao_percentual": {
+                    "description": "null se o valor anterior é zero",
+                    "type": "number"
+                }
+            }
+        },
+        "uiapi.remunerationDistribution": {
+            "type": "object",
+            "properties": {
+                "ano": {
+                    "type": "integer"
+                },
+                "correcao_monetaria": {
+                    "$ref": "#/definitions/ipca.Correction"
+                },
+                "gini": {
+                    "description": "null se a soma dos valores não é positiva",
+                    "type": "number"
+                },
+                "histograma": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uiapi.histogramBin"
+                    }
+                },
+                "id_orgao": {
+                    "type": "string"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "media": {
+                    "type": "number"
+                },
+                "mediana": {
+                    "type": "number"
+                },
+                "mes": {
+                    "type": "integer"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "num_membros": {
+                    "type": "integer"
+                },
+                "percentis": {
+                    "$ref": "#/definitions/uiapi.percentiles"
+                },
+                "sem_correcao": {
+                    "description": "Valores nominais apesar de corrigir, pois o IPCA do mês não está na série",
+                    "type": "boolean"
+                },
+                "valor": {
+                    "description": "Valor do contracheque usado: liquido, base, outras ou descontos",
+                    "type": "string"
+                }
+            }
+        },
         "uiapi.searchResponse": {
             "type": "object",
             "properties": {
@@ -1789,6 +4129,10 @@ const docTemplate = `{
                 "num_rows_if_available": {
                     "type": "integer"
                 },
+                "proximo": {
+                    "description": "Cursor da próxima página, vazio na última página",
+                    "type": "string"
+                },
                 "result": {
                     "type": "array",
                     "items": {
@@ -1861,6 +4205,101 @@ const docTemplate = `{
                 }
             }
         },
+        "uiapi.tetoBreachMonth": {
+            "type": "object",
+            "properties": {
+                "ano": {
+                    "type": "integer"
+                },
+                "excedente": {
+                    "description": "Soma do que os membros receberam acima do teto",
+                    "type": "number"
+                },
+                "id_orgao": {
+                    "type": "string"
+                },
+                "mes": {
+                    "type": "integer"
+                },
+                "num_acima_teto": {
+                    "type": "integer"
+                },
+                "num_membros": {
+                    "type": "integer"
+                },
+                "percentual_acima_teto": {
+                    "description": "Entre 0 e 1",
+                    "type": "number"
+                },
+                "teto": {
+                    "type": "number"
+                }
+            }
+        },
+        "uiapi.tetoBreachTotals": {
+            "type": "object",
+            "properties": {
+                "excedente": {
+                    "type": "number"
+                },
+                "num_acima_teto": {
+                    "type": "integer"
+                },
+                "num_contracheques": {
+                    "description": "Soma dos membros de todos os meses",
+                    "type": "integer"
+                },
+                "percentual_acima_teto": {
+                    "type": "number"
+                }
+            }
+        },
+        "uiapi.tetoBreachesResponse": {
+            "type": "object",
+            "properties": {
+                "meses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uiapi.tetoBreachMonth"
+                    }
+                },
+                "totais": {
+                    "$ref": "#/definitions/uiapi.tetoBreachTotals"
+                },
+                "versao_tabela_teto": {
+                    "type": "string"
+                }
+            }
+        },
+        "uiapi.tetoTableResponse": {
+            "type": "object",
+            "properties": {
+                "valores": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uiapi.tetoValue"
+                    }
+                },
+                "versao": {
+                    "type": "string"
+                }
+            }
+        },
+        "uiapi.tetoValue": {
+            "type": "object",
+            "properties": {
+                "inicio": {
+                    "description": "Mês de início da vigência, no formato AAAA-MM",
+                    "type": "string"
+                },
+                "norma": {
+                    "type": "string"
+                },
+                "valor": {
+                    "type": "number"
+                }
+            }
+        },
         "uiapi.timestamp": {
             "type": "object",
             "properties": {
@@ -1925,6 +4364,9 @@ const docTemplate = `{
                 "ano": {
                     "type": "integer"
                 },
+                "correcao_monetaria": {
+                    "$ref": "#/definitions/ipca.Correction"
+                },
                 "media_por_membro": {
                     "$ref": "#/definitions/uiapi.perCapitaData"
                 },
@@ -1945,6 +4387,16 @@ const docTemplate = `{
         "uiapi.v2MonthTotals": {
             "type": "object",
             "properties": {
+                "ano": {
+                    "description": "Apenas nos totais de um intervalo",
+                    "type": "integer"
+                },
+                "anomalias": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uiapi.anomaly"
+                    }
+                },
                 "descontos": {
                     "type": "number"
                 },
@@ -1981,6 +4433,10 @@ const docTemplate = `{
                 "resumo_rubricas": {
                     "$ref": "#/definitions/uiapi.itemSummary"
                 },
+                "sem_correcao": {
+                    "description": "Valores nominais apesar de corrigir, pois o IPCA do mês não está na série",
+                    "type": "boolean"
+                },
                 "timestamp": {
                     "$ref": "#/definitions/uiapi.timestamp"
                 },
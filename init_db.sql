@@ -9,11 +9,12 @@ CREATE TABLE orgaos(
 );
  
 CREATE TABLE coletas(
-    id VARCHAR(25) PRIMARY KEY,  -- chave primária da coleta: id_orgao/mes/ano
+    id VARCHAR(25) NOT NULL,  -- identificador do mês coletado: id_orgao/mes/ano. Junto com o timestamp, forma a chave primária da coleta
     id_orgao VARCHAR(10) NOT NULL,    -- A sigla do órgão em minúsculo. Exemplos tjal, mpms, mpam...
     mes INT NOT NULL,    -- O mês que os dados coletados se referem. 
     ano INT NOT NULL,    -- O ano que os dados coletados se referem. 
     timestamp TIMESTAMP NOT NULL,    -- Marca temporal em que o dado foi coletado.
+    atual BOOL NOT NULL DEFAULT true,    -- Se é a coleta vigente do mês. Quando o mês é coletado de novo, as coletas anteriores ficam com atual = false.
     repositorio_coletor VARCHAR(150) NOT NULL,    -- URL do repositório do coletor dos dados relacionados a coleta.
     versao_coletor VARCHAR(25) NOT NULL,    -- Versão (identificador do commit) do repositório do coletor dos dados relacionados a coleta
     repositorio_parser VARCHAR(150), -- URL do repositório do parser dos dados relacionados a coleta. Somente preenchido quando o parser é diferente do coletor
@@ -58,10 +59,13 @@ CREATE TABLE coletas(
         }
     }
     */
+    CONSTRAINT coletas_pk PRIMARY KEY (id, timestamp),
     CONSTRAINT coleta_orgao_fk FOREIGN KEY (id_orgao) REFERENCES orgaos(id) ON DELETE CASCADE
 );
 
 CREATE INDEX coletas_indice ON coletas(id_orgao,mes,ano);
+-- Usado pela rota /v2/atualizacoes, que lista as coletas vigentes na ordem do timestamp.
+CREATE INDEX coletas_atualizacoes_indice ON coletas(timestamp,id_orgao,ano,mes) WHERE atual;
 
 CREATE TABLE remuneracoes(
     id_orgao VARCHAR(10) NOT NULL,    -- A sigla do órgão em minúsculo. Exemplos tjal, mpms, mpam...
@@ -0,0 +1,96 @@
+// Command atualizar baixa a série de números-índice do IPCA da API do SIDRA
+// (tabela 1737, variável 2266) e reescreve o arquivo ipca.csv do pacote ipca,
+// a partir de dezembro de 2017. É executado com go generate no pacote ipca.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const sidraURL = "https://apisidra.ibge.gov.br/values/t/1737/n1/all/v/2266/p/all"
+
+// Primeiro mês da série, o anterior ao primeiro mês com dados da API.
+const firstMonth = "201712"
+
+func main() {
+	out := flag.String("o", "ipca.csv", "arquivo de saída")
+	flag.Parse()
+
+	rows, err := fetch()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := write(*out, rows); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%s atualizado até %s", *out, rows[len(rows)-1][0])
+}
+
+// fetch retorna as linhas (mês AAAA-MM, número-índice) em ordem de mês. A
+// primeira linha da resposta do SIDRA descreve as colunas, e a coluna do mês
+// é encontrada pela descrição.
+func fetch() ([][2]string, error) {
+	client := http.Client{Timeout: time.Minute}
+	resp, err := client.Get(sidraURL)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching ipca series: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching ipca series: status %d", resp.StatusCode)
+	}
+	var values []map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&values); err != nil {
+		return nil, fmt.Errorf("error decoding ipca series: %w", err)
+	}
+	if len(values) < 2 {
+		return nil, fmt.Errorf("ipca series is empty")
+	}
+	monthKey := ""
+	for k, v := range values[0] {
+		if v == "Mês (Código)" {
+			monthKey = k
+		}
+	}
+	if monthKey == "" {
+		return nil, fmt.Errorf("month column not found in ipca series: %v", values[0])
+	}
+	var rows [][2]string
+	for _, v := range values[1:] {
+		month := v[monthKey]
+		// Meses ainda não divulgados vêm com "..." ou "-".
+		if _, err := strconv.ParseFloat(v["V"], 64); err != nil || month < firstMonth {
+			continue
+		}
+		rows = append(rows, [2]string{month[:4] + "-" + month[4:], v["V"]})
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("ipca series has no months since %s", firstMonth)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	return rows, nil
+}
+
+func write(path string, rows [][2]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", path, err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	w.Write([]string{"mes", "indice"})
+	for _, r := range rows {
+		w.Write(r[:])
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,229 @@
+// Package ipca corrige valores monetários pela inflação medida pelo IPCA
+// (Índice Nacional de Preços ao Consumidor Amplo, do IBGE).
+//
+// A série de números-índice (dezembro de 1993 = 100) fica no arquivo
+// ipca.csv, embutido no binário. Para atualizá-la com os meses divulgados pelo
+// IBGE (tabela 1737 do SIDRA), execute go generate neste pacote. Enquanto a
+// série não é atualizada, os valores dos meses depois do último mês dela ficam
+// nominais e são marcados nas respostas (ver Correction.Uncorrected).
+package ipca
+
+//go:generate go run ./atualizar -o ipca.csv
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dadosjusbr/api/daterange"
+	"github.com/labstack/echo/v4"
+)
+
+// Índice aceito no parâmetro corrigir.
+const Index = "ipca"
+
+//go:embed ipca.csv
+var data string
+
+// Série de números-índice do IPCA embutida no binário.
+var series = mustParse(data)
+
+// Series guarda os números-índice do IPCA por mês.
+type Series struct {
+	index map[int]float64
+	first int
+	last  int
+}
+
+func formatMonth(n int) string {
+	return daterange.MonthOf(n).String()
+}
+
+// Parse lê uma série no formato de ipca.csv: um cabeçalho seguido de linhas
+// com o mês (AAAA-MM) e o número-índice. Os meses devem ser consecutivos.
+func Parse(r io.Reader) (*Series, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading ipca series: %w", err)
+	}
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("ipca series is empty")
+	}
+	s := &Series{index: map[int]float64{}}
+	for i, row := range rows[1:] {
+		if len(row) != 2 {
+			return nil, fmt.Errorf("invalid ipca row %d: %v", i+2, row)
+		}
+		month, err := time.Parse("2006-01", strings.TrimSpace(row[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ipca month %q: %w", row[0], err)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid ipca index %q", row[1])
+		}
+		n := daterange.Number(month.Year(), int(month.Month()))
+		if i == 0 {
+			s.first = n
+		} else if n != s.last+1 {
+			return nil, fmt.Errorf("ipca series is not consecutive at %s", row[0])
+		}
+		s.last = n
+		s.index[n] = value
+	}
+	return s, nil
+}
+
+func mustParse(data string) *Series {
+	s, err := Parse(strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Last retorna o último mês da série, no formato AAAA-MM.
+func (s *Series) Last() string {
+	return formatMonth(s.last)
+}
+
+// Retorna o número-índice do mês e se o mês está na série.
+func (s *Series) at(n int) (float64, bool) {
+	if n < s.first || n > s.last {
+		return 0, false
+	}
+	return s.index[n], true
+}
+
+// Correction descreve a correção aplicada aos valores de uma resposta.
+type Correction struct {
+	Index string `json:"indice"`
+	Base  string `json:"base"` // Mês base, no formato AAAA-MM
+	// Primeiro e último meses da série, no formato AAAA-MM. Os valores dos
+	// meses fora deste intervalo não são corrigidos.
+	From   string `json:"serie_inicio"`
+	To     string `json:"serie_fim"`
+	series *Series
+	base   float64
+}
+
+// NewCorrection interpreta os parâmetros corrigir e base das consultas. Retorna
+// nil quando corrigir não é informado, ou seja, quando os valores devem ser
+// mantidos nominais. Sem base, é usado o último mês da série.
+func NewCorrection(index, base string) (*Correction, error) {
+	return series.NewCorrection(index, base)
+}
+
+// FromQuery lê os parâmetros corrigir e base da consulta. Quando há correção,
+// ela é informada no cabeçalho Header da resposta, pois nem todas as respostas
+// são objetos em que ela possa ser incluída.
+func FromQuery(c echo.Context) (*Correction, error) {
+	corr, err := NewCorrection(c.QueryParam("corrigir"), c.QueryParam("base"))
+	if err != nil {
+		return nil, err
+	}
+	if corr != nil {
+		c.Response().Header().Set(Header, corr.String())
+	}
+	return corr, nil
+}
+
+// NewCorrection funciona como a função de mesmo nome, mas usando a série s.
+func (s *Series) NewCorrection(index, base string) (*Correction, error) {
+	if index == "" {
+		if base != "" {
+			return nil, fmt.Errorf("parâmetro base só pode ser usado com corrigir=%s!", Index)
+		}
+		return nil, nil
+	}
+	if strings.ToLower(index) != Index {
+		return nil, fmt.Errorf("parâmetro corrigir '%s' é inválido!", index)
+	}
+	if base == "" {
+		base = s.Last()
+	}
+	month, err := time.Parse("2006-01", base)
+	if err != nil {
+		return nil, fmt.Errorf("parâmetro base '%s' é inválido!", base)
+	}
+	n := daterange.Number(month.Year(), int(month.Month()))
+	if n < s.first || n > s.last {
+		return nil, fmt.Errorf("parâmetro base '%s' é inválido! O IPCA está disponível de %s a %s.", base, formatMonth(s.first), s.Last())
+	}
+	return &Correction{Index: Index, Base: base, From: formatMonth(s.first), To: s.Last(), series: s, base: s.index[n]}, nil
+}
+
+// Month retorna o fator que leva os valores do mês para o mês base. Uma
+// correção nil e os meses fora da série mantêm os valores nominais, pois o
+// IPCA desses meses não é conhecido.
+func (c *Correction) Month(year, month int) float64 {
+	if c == nil {
+		return 1
+	}
+	index, ok := c.series.at(daterange.Number(year, month))
+	if !ok {
+		return 1
+	}
+	return c.base / index
+}
+
+// Uncorrected diz se os valores do mês ficam nominais apesar da correção, pois
+// o IPCA do mês não está na série. As respostas marcam esses valores (campo
+// sem_correcao) para que não sejam confundidos com os corrigidos.
+func (c *Correction) Uncorrected(year, month int) bool {
+	if c == nil {
+		return false
+	}
+	_, ok := c.series.at(daterange.Number(year, month))
+	return !ok
+}
+
+// UncorrectedYear diz se algum mês do ano fica nominal (ver Uncorrected). Nesse
+// caso, o fator de Year mistura meses corrigidos e nominais.
+func (c *Correction) UncorrectedYear(year int) bool {
+	for m := 1; m <= 12; m++ {
+		if c.Uncorrected(year, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// Year retorna o fator para valores anuais: a média dos fatores dos meses do
+// ano, em que os meses fora da série não são corrigidos (ver UncorrectedYear).
+// É uma aproximação, pois os valores de cada mês não são conhecidos.
+func (c *Correction) Year(year int) float64 {
+	if c == nil {
+		return 1
+	}
+	total := 0.0
+	for m := 1; m <= 12; m++ {
+		total += c.Month(year, m)
+	}
+	return total / 12
+}
+
+// Items aplica o fator aos valores do resumo das rubricas, retornando um novo mapa.
+func Items(items map[string]float64, factor float64) map[string]float64 {
+	if items == nil || factor == 1 {
+		return items
+	}
+	corrected := make(map[string]float64, len(items))
+	for k, v := range items {
+		corrected[k] = v * factor
+	}
+	return corrected
+}
+
+// Header é o cabeçalho HTTP que informa a correção aplicada à resposta.
+const Header = "X-Correcao-Monetaria"
+
+// String descreve a correção no formato usado em Header. Exemplo:
+// ipca; base=2024-12; serie=2017-12/2025-08
+func (c *Correction) String() string {
+	return fmt.Sprintf("%s; base=%s; serie=%s/%s", c.Index, c.Base, c.From, c.To)
+}
@@ -0,0 +1,145 @@
+package ipca
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dadosjusbr/api/daterange"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := parseTests{}
+	t.Run("Test Parse when series is valid", tests.testWhenSeriesIsValid)
+	t.Run("Test Parse when months are not consecutive", tests.testWhenMonthsAreNotConsecutive)
+	t.Run("Test Parse when index is invalid", tests.testWhenIndexIsInvalid)
+	t.Run("Test bundled series", tests.testBundledSeries)
+}
+
+type parseTests struct{}
+
+func (p parseTests) testWhenSeriesIsValid(t *testing.T) {
+	s, err := Parse(strings.NewReader("mes,indice\n2019-12,100\n2020-01,110\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-01", s.Last())
+}
+
+func (p parseTests) testWhenMonthsAreNotConsecutive(t *testing.T) {
+	_, err := Parse(strings.NewReader("mes,indice\n2019-12,100\n2020-02,110\n"))
+
+	assert.Error(t, err)
+}
+
+func (p parseTests) testWhenIndexIsInvalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("mes,indice\n2019-12,abc\n"))
+
+	assert.Error(t, err)
+}
+
+func (p parseTests) testBundledSeries(t *testing.T) {
+	assert.Equal(t, 5320.25, series.index[daterange.Number(2019, 12)])
+	assert.Equal(t, series.Last(), formatMonth(series.last))
+}
+
+func TestCorrection(t *testing.T) {
+	tests := correctionTests{}
+	t.Run("Test NewCorrection when index is not informed", tests.testWhenIndexIsNotInformed)
+	t.Run("Test NewCorrection when parameters are invalid", tests.testWhenParametersAreInvalid)
+	t.Run("Test NewCorrection when base is not informed", tests.testWhenBaseIsNotInformed)
+	t.Run("Test correction factors", tests.testFactors)
+	t.Run("Test Items", tests.testItems)
+	t.Run("Test FromQuery", tests.testFromQuery)
+}
+
+type correctionTests struct{}
+
+func (c correctionTests) series(t *testing.T) *Series {
+	s, err := Parse(strings.NewReader("mes,indice\n2019-12,100\n2020-01,110\n2020-02,120\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func (c correctionTests) testWhenIndexIsNotInformed(t *testing.T) {
+	corr, err := NewCorrection("", "")
+
+	assert.NoError(t, err)
+	assert.Nil(t, corr)
+	assert.Equal(t, 1.0, corr.Month(2020, 1))
+	assert.Equal(t, 1.0, corr.Year(2020))
+}
+
+func (c correctionTests) testWhenParametersAreInvalid(t *testing.T) {
+	s := c.series(t)
+	for _, params := range [][2]string{{"", "2020-01"}, {"igpm", ""}, {"ipca", "2020-13"}, {"ipca", "2020-03"}, {"ipca", "2019-11"}} {
+		_, err := s.NewCorrection(params[0], params[1])
+
+		assert.Error(t, err, params)
+	}
+}
+
+func (c correctionTests) testWhenBaseIsNotInformed(t *testing.T) {
+	corr, err := c.series(t).NewCorrection("IPCA", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ipca", corr.Index)
+	assert.Equal(t, "2020-02", corr.Base)
+	assert.Equal(t, "2019-12", corr.From)
+	assert.Equal(t, "2020-02", corr.To)
+	assert.Equal(t, "ipca; base=2020-02; serie=2019-12/2020-02", corr.String())
+}
+
+func (c correctionTests) testFactors(t *testing.T) {
+	corr, err := c.series(t).NewCorrection("ipca", "2020-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1.1, corr.Month(2019, 12))
+	assert.Equal(t, 1.0, corr.Month(2020, 1))
+	assert.InDelta(t, 110.0/120, corr.Month(2020, 2), 1e-9)
+	// Meses fora da série não são corrigidos.
+	assert.Equal(t, 1.0, corr.Month(2018, 1))
+	assert.Equal(t, 1.0, corr.Month(2020, 3))
+	assert.Equal(t, 1.0, corr.Year(2021))
+	assert.InDelta(t, (1+110.0/120+10)/12, corr.Year(2020), 1e-9)
+	assert.False(t, corr.Uncorrected(2020, 2))
+	assert.True(t, corr.Uncorrected(2020, 3))
+	assert.True(t, corr.UncorrectedYear(2020))
+	var none *Correction
+	assert.False(t, none.Uncorrected(2030, 1))
+	assert.False(t, none.UncorrectedYear(2030))
+}
+
+func (c correctionTests) testItems(t *testing.T) {
+	items := map[string]float64{"auxilio_saude": 100}
+
+	assert.Equal(t, map[string]float64{"auxilio_saude": 150}, Items(items, 1.5))
+	assert.Equal(t, map[string]float64{"auxilio_saude": 100}, items)
+	assert.Nil(t, Items(nil, 1.5))
+}
+
+func (c correctionTests) testFromQuery(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/?corrigir=ipca&base=2020-01", nil), recorder)
+
+	corr, err := FromQuery(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-01", corr.Base)
+	assert.Equal(t, corr.String(), recorder.Header().Get(Header))
+
+	recorder = httptest.NewRecorder()
+	ctx = echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), recorder)
+
+	corr, err = FromQuery(ctx)
+
+	assert.NoError(t, err)
+	assert.Nil(t, corr)
+	assert.Empty(t, recorder.Header().Get(Header))
+}
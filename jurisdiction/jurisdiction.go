@@ -0,0 +1,46 @@
+// Package jurisdiction relaciona os grupos de órgãos aceitos pelas rotas da
+// API, usados também nas URLs do site, às jurisdições dos órgãos no banco de
+// dados (campo Type dos órgãos). As duas APIs usam este mapa, para que aceitem
+// os mesmos grupos e os associem aos mesmos órgãos.
+package jurisdiction
+
+import (
+	"strings"
+
+	"github.com/dadosjusbr/storage/models"
+)
+
+// Groups associa cada grupo de órgãos à jurisdição correspondente no banco.
+var Groups = map[string]string{
+	"justica-eleitoral":    "Eleitoral",
+	"ministerios-publicos": "Ministério",
+	"justica-estadual":     "Estadual",
+	"justica-do-trabalho":  "Trabalho",
+	"justica-federal":      "Federal",
+	"justica-militar":      "Militar",
+	"justica-superior":     "Superior",
+	"conselhos-de-justica": "Conselho",
+}
+
+// Of retorna a jurisdição do grupo e se o grupo existe. Maiúsculas e espaços
+// nas extremidades são ignorados.
+func Of(group string) (string, bool) {
+	j, ok := Groups[strings.ToLower(strings.TrimSpace(group))]
+	return j, ok
+}
+
+// GroupOf retorna o grupo de uma jurisdição do banco de dados. Jurisdições
+// sem grupo são retornadas sem alteração.
+func GroupOf(jurisdiction string) string {
+	for g, j := range Groups {
+		if j == jurisdiction {
+			return g
+		}
+	}
+	return jurisdiction
+}
+
+// Match indica se o órgão pertence à jurisdição.
+func Match(jurisdiction string, a models.Agency) bool {
+	return a.Type == jurisdiction
+}
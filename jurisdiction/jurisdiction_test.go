@@ -0,0 +1,37 @@
+package jurisdiction
+
+import (
+	"testing"
+
+	"github.com/dadosjusbr/storage/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJurisdiction(t *testing.T) {
+	tests := jurisdictionTests{}
+	t.Run("Test Of", tests.testOf)
+	t.Run("Test GroupOf", tests.testGroupOf)
+	t.Run("Test Match", tests.testMatch)
+}
+
+type jurisdictionTests struct{}
+
+func (j jurisdictionTests) testOf(t *testing.T) {
+	jurisdiction, ok := Of(" Justica-Estadual ")
+	assert.True(t, ok)
+	assert.Equal(t, "Estadual", jurisdiction)
+	_, ok = Of("Estadual")
+	assert.False(t, ok)
+}
+
+func (j jurisdictionTests) testGroupOf(t *testing.T) {
+	for group, jurisdiction := range Groups {
+		assert.Equal(t, group, GroupOf(jurisdiction))
+	}
+	assert.Equal(t, "Outra", GroupOf("Outra"))
+}
+
+func (j jurisdictionTests) testMatch(t *testing.T) {
+	assert.True(t, Match("Estadual", models.Agency{ID: "tjal", Type: "Estadual"}))
+	assert.False(t, Match("Estadual", models.Agency{ID: "tjal", Entity: "Estadual"}))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -30,6 +31,24 @@ type config struct {
 	AwsS3Bucket string `envconfig:"AWS_S3_BUCKET" required:"true"`
 	AwsRegion   string `envconfig:"AWS_REGION" required:"true"`
 
+	// Origem dos arquivos zip de remunerações: s3, s3-compatible ou local
+	ZipStore      string `envconfig:"ZIP_STORE" default:"s3"`
+	AwsS3Endpoint string `envconfig:"AWS_S3_ENDPOINT"`
+	ZipStoreDir   string `envconfig:"ZIP_STORE_DIR"`
+	// Tamanho máximo, em MB, do cache em memória dos arquivos zip. Zero desativa o cache.
+	ZipCacheSizeMB int64 `envconfig:"ZIP_CACHE_SIZE_MB"`
+
+	// Destino dos arquivos das exportações assíncronas: local, s3 ou s3-compatible
+	ExportStore    string `envconfig:"EXPORT_STORE" default:"local"`
+	ExportStoreDir string `envconfig:"EXPORT_STORE_DIR" default:"exportacoes"`
+	// Bucket e prefixo das exportações quando EXPORT_STORE é s3 ou s3-compatible
+	ExportS3Bucket string `envconfig:"EXPORT_S3_BUCKET"`
+	ExportS3Prefix string `envconfig:"EXPORT_S3_PREFIX"`
+	ExportWorkers  int    `envconfig:"EXPORT_WORKERS" default:"2"`
+	// Tempo que as exportações ficam guardadas e quantidade de exportações em andamento por cliente
+	ExportTTL        time.Duration `envconfig:"EXPORT_TTL" default:"24h"`
+	ExportsPerClient int           `envconfig:"EXPORTS_PER_CLIENT" default:"3"`
+
 	// Omited fields
 	EnvOmittedFields []string `envconfig:"ENV_OMITTED_FIELDS"`
 
@@ -48,6 +67,9 @@ type config struct {
 	SearchLimit   int `envconfig:"SEARCH_LIMIT"`
 	DownloadLimit int `envconfig:"DOWNLOAD_LIMIT"`
 
+	// Chaves de acesso que removem o limite de linhas do download
+	DownloadAPIKeys []string `envconfig:"DOWNLOAD_API_KEYS"`
+
 	// Newrelic config
 	NewRelicApp     string `envconfig:"NEWRELIC_APP_NAME"`
 	NewRelicLicense string `envconfig:"NEWRELIC_LICENSE"`
@@ -82,6 +104,49 @@ func newS3Client(c config) (*file_storage.S3Client, error) {
 	return s3Client, nil
 }
 
+func newZipStore(c config) (uiapi.ZipStore, error) {
+	switch c.ZipStore {
+	case "s3":
+		return uiapi.NewS3ZipStore(c.AwsRegion, c.AwsS3Bucket, "")
+	case "s3-compatible":
+		if c.AwsS3Endpoint == "" {
+			return nil, fmt.Errorf("AWS_S3_ENDPOINT is required when ZIP_STORE is s3-compatible")
+		}
+		return uiapi.NewS3ZipStore(c.AwsRegion, c.AwsS3Bucket, c.AwsS3Endpoint)
+	case "local":
+		if c.ZipStoreDir == "" {
+			return nil, fmt.Errorf("ZIP_STORE_DIR is required when ZIP_STORE is local")
+		}
+		return uiapi.NewDirZipStore(c.ZipStoreDir, c.AwsS3Bucket), nil
+	default:
+		return nil, fmt.Errorf("invalid ZIP_STORE: %q", c.ZipStore)
+	}
+}
+
+func newExportStore(c config) (uiapi.ExportStore, error) {
+	switch c.ExportStore {
+	case "local":
+		return uiapi.NewDirExportStore(c.ExportStoreDir), nil
+	case "s3", "s3-compatible":
+		// As exportações nunca são misturadas aos arquivos de remunerações.
+		if c.ExportS3Bucket == "" {
+			return nil, fmt.Errorf("EXPORT_S3_BUCKET is required when EXPORT_STORE is %s", c.ExportStore)
+		}
+		if c.ExportS3Bucket == c.AwsS3Bucket && c.ExportS3Prefix == "" {
+			return nil, fmt.Errorf("EXPORT_S3_PREFIX is required when EXPORT_S3_BUCKET is the same as AWS_S3_BUCKET")
+		}
+		if c.ExportStore == "s3" {
+			return uiapi.NewS3ExportStore(c.AwsRegion, c.ExportS3Bucket, c.ExportS3Prefix, "")
+		}
+		if c.AwsS3Endpoint == "" {
+			return nil, fmt.Errorf("AWS_S3_ENDPOINT is required when EXPORT_STORE is s3-compatible")
+		}
+		return uiapi.NewS3ExportStore(c.AwsRegion, c.ExportS3Bucket, c.ExportS3Prefix, c.AwsS3Endpoint)
+	default:
+		return nil, fmt.Errorf("invalid EXPORT_STORE: %q", c.ExportStore)
+	}
+}
+
 // @title			API do dadosjusbr.org
 // @version		1.0
 // @contact.name	DadosJusBr
@@ -159,23 +224,37 @@ func main() {
 				"http://dadosjusbr-site-v2.us-east-1.elasticbeanstalk.com",
 				"http://www.dadosjusbr-site-v2.us-east-1.elasticbeanstalk.com",
 			},
-			AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderContentLength},
+			AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderContentLength, echo.HeaderAuthorization},
 		}))
 		log.Println("Using production CORS")
 	} else {
 		uiAPIGroup.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 			AllowOrigins: []string{"*"},
-			AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderContentLength, echo.HeaderAccessControlAllowOrigin},
+			AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderContentLength, echo.HeaderAccessControlAllowOrigin, echo.HeaderAuthorization},
 		}))
 	}
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 	e.GET("/doc", func(c echo.Context) error {
 		return c.Redirect(http.StatusMovedPermanently, "/swagger/index.html")
 	})
-	uiApiHandler, err := uiapi.NewHandler(pgS3Client, conn, nr, conf.AwsRegion, conf.AwsS3Bucket, loc, conf.EnvOmittedFields, conf.SearchLimit, conf.DownloadLimit)
+	zipStore, err := newZipStore(conf)
+	if err != nil {
+		log.Fatalf("Error creating zip store: %q", err)
+	}
+	if conf.ZipCacheSizeMB > 0 {
+		zipStore = uiapi.NewCachedZipStore(zipStore, conf.ZipCacheSizeMB*1024*1024, nr)
+	}
+	exportStore, err := newExportStore(conf)
+	if err != nil {
+		log.Fatalf("Error creating export store: %q", err)
+	}
+	uiApiHandler, err := uiapi.NewHandler(pgS3Client, conn, nr, zipStore, exportStore, loc, conf.EnvOmittedFields, conf.SearchLimit, conf.DownloadLimit, conf.DownloadAPIKeys)
 	if err != nil {
 		log.Fatalf("Error creating uiapi handler: %q", err)
 	}
+	if err := uiApiHandler.StartExports(context.Background(), conf.ExportWorkers, conf.ExportsPerClient, conf.ExportTTL); err != nil {
+		log.Fatalf("Error starting exports: %q", err)
+	}
 	// Return a summary of an agency. This information will be used in the head of the agency page.
 	uiAPIGroup.GET("/v1/orgao/resumo/:orgao/:ano/:mes", uiApiHandler.GetSummaryOfAgency)
 	uiAPIGroup.GET("/v2/orgao/resumo/:orgao/:ano/:mes", uiApiHandler.V2GetSummaryOfAgency)
@@ -188,6 +267,8 @@ func main() {
 	// Return the total of salary of every month of a year of a agency. The salary is divided in Wage, Perks and Others. This will be used to plot the bars chart at the state page.
 	uiAPIGroup.GET("/v1/orgao/totais/:orgao/:ano", uiApiHandler.GetTotalsOfAgencyYear)
 	uiAPIGroup.GET("/v2/orgao/totais/:orgao/:ano", uiApiHandler.V2GetTotalsOfAgencyYear)
+	// Retorna os totais mensais de um órgão em um intervalo de meses
+	uiAPIGroup.GET("/v2/orgao/totais/:orgao", uiApiHandler.GetTotalsOfAgencyRange)
 	// Return basic information of a type or state
 	uiAPIGroup.GET("/v1/orgao/:grupo", uiApiHandler.GetBasicInfoOfType)
 	uiAPIGroup.GET("/v2/orgao/:grupo", uiApiHandler.V2GetBasicInfoOfType)
@@ -199,12 +280,34 @@ func main() {
 	uiAPIGroup.GET("/v2/pesquisar", uiApiHandler.SearchByUrl)
 	// Baixa um conjunto de dados a partir de filtros informados por query params
 	uiAPIGroup.GET("/v2/download", uiApiHandler.DownloadByUrl)
+	// Retorna os totais mensais dos contracheques de um membro, pela matrícula ou pelo nome
+	uiAPIGroup.GET("/v2/membro/:orgao/:matricula", uiApiHandler.GetMemberByRegistration)
+	uiAPIGroup.GET("/v2/membro/:orgao", uiApiHandler.GetMembersByName)
+	// Lista as rubricas conhecidas e retorna os totais mensais de uma rubrica por órgão
+	uiAPIGroup.GET("/v2/rubricas", uiApiHandler.GetItems)
+	uiAPIGroup.GET("/v2/rubricas/:rubrica", uiApiHandler.GetItemTimeSeries)
+	// Compara as séries mensais de vários órgãos
+	uiAPIGroup.GET("/v2/comparar", uiApiHandler.CompareAgencies)
+	// Classifica os órgãos por uma métrica
+	uiAPIGroup.GET("/v2/ranking", uiApiHandler.GetRanking)
+	// Retorna a distribuição dos contracheques de um órgão em um mês
+	uiAPIGroup.GET("/v2/distribuicao/:orgao/:ano/:mes", uiApiHandler.GetRemunerationDistribution)
+	// Lista as anomalias dos totais mensais dos órgãos
+	uiAPIGroup.GET("/v2/anomalias", uiApiHandler.GetAnomalies)
+	// Retorna a tabela do teto constitucional e os membros que receberam acima dele
+	uiAPIGroup.GET("/v2/teto", uiApiHandler.GetTetoTable)
+	uiAPIGroup.GET("/v2/teto/excedentes", uiApiHandler.GetTetoBreaches)
+	// Exportações assíncronas, para downloads grandes demais para uma requisição
+	uiAPIGroup.POST("/v2/exportacoes", uiApiHandler.CreateExport)
+	uiAPIGroup.GET("/v2/exportacoes/:id", uiApiHandler.GetExport)
+	uiAPIGroup.GET("/v2/exportacoes/:id/arquivo", uiApiHandler.DownloadExport)
 	// Baixa o readme do pacote de dados
 	uiAPIGroup.GET("/v2/readme", uiApiHandler.DownloadReadme)
 	// Retorna a média (base, benefícios, descontos e remuneração) de cada órgão em um ano
 	uiAPIGroup.GET("/v2/orgao/media/:ano", uiApiHandler.GetAveragePerAgency)
 
-	apiHandler := papi.NewHandler(pgS3Client, conf.DadosJusURL, conf.PackageRepoURL)
+	apiHandler := papi.NewHandler(pgS3Client, conn, nr, exportStore, loc, conf.DadosJusURL, conf.PackageRepoURL)
+	apiHandler.StartSnapshots(context.Background())
 	// Public API configuration
 	apiGroup := e.Group("/v1", middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
@@ -225,6 +328,8 @@ func main() {
 	}))
 	apiGroupV2.GET("/orgao/:orgao", apiHandler.V2GetAgencyById)
 	apiGroupV2.GET("/orgaos", apiHandler.V2GetAllAgencies)
+	// Return MIs of many agencies
+	apiGroupV2.GET("/dados", apiHandler.GetMonthlyInfosOfAgencies)
 	// Return MIs by year
 	apiGroupV2.GET("/dados/:orgao/:ano", apiHandler.GetMonthlyInfosByYear)
 	// Return MIs by month
@@ -237,6 +342,12 @@ func main() {
 	apiGroupV2.GET("/indices/:ano", apiHandler.V2GetAggregateIndexesWithParams)
 	apiGroupV2.GET("/indices/:ano/:mes", apiHandler.V2GetAggregateIndexesWithParams)
 	apiGroupV2.GET("/dados/:orgao", apiHandler.V2GetAllAgencyInformation)
+	// Return monthly data aggregated by the given dimensions
+	apiGroupV2.GET("/agregados", apiHandler.GetAggregates)
+	// Return a daily snapshot of all data, with a manifest of SHA-256 hashes
+	apiGroupV2.GET("/snapshot", apiHandler.GetSnapshot)
+	// Return the agency months collected or re-collected since a timestamp
+	apiGroupV2.GET("/atualizacoes", apiHandler.GetUpdates)
 
 	s := &http.Server{
 		Addr:         fmt.Sprintf(":%d", conf.Port),
@@ -0,0 +1,73 @@
+package papi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/dadosjusbr/api/jurisdiction"
+	"github.com/dadosjusbr/storage/models"
+)
+
+// Filtro de órgãos por sigla, jurisdição e UF. Os filtros são combinados entre
+// si e os que estão vazios aceitam qualquer órgão.
+type agencyFilter struct {
+	IDs   []string
+	Types []string // Tipos dos órgãos no banco de dados, convertidos das jurisdições
+	UFs   []string
+}
+
+// newAgencyFilter lê as listas de siglas, jurisdições e UFs, separadas por vírgula.
+func newAgencyFilter(ids, groups, ufs string) (agencyFilter, error) {
+	var f agencyFilter
+	for _, id := range strings.Split(ids, ",") {
+		if id = strings.ToLower(strings.TrimSpace(id)); id != "" {
+			f.IDs = append(f.IDs, id)
+		}
+	}
+	for _, g := range strings.Split(groups, ",") {
+		if g = strings.TrimSpace(g); g == "" {
+			continue
+		}
+		t, ok := jurisdiction.Of(g)
+		if !ok {
+			return agencyFilter{}, fmt.Errorf("jurisdição '%s' é inválida!", g)
+		}
+		f.Types = append(f.Types, t)
+	}
+	for _, uf := range strings.Split(ufs, ",") {
+		if uf = strings.ToUpper(strings.TrimSpace(uf)); uf != "" {
+			f.UFs = append(f.UFs, uf)
+		}
+	}
+	return f, nil
+}
+
+func (f agencyFilter) match(a models.Agency) bool {
+	return (len(f.IDs) == 0 || slices.Contains(f.IDs, a.ID)) &&
+		(len(f.Types) == 0 || slices.ContainsFunc(f.Types, func(t string) bool { return jurisdiction.Match(t, a) })) &&
+		(len(f.UFs) == 0 || slices.Contains(f.UFs, strings.ToUpper(a.UF)))
+}
+
+// filterAgencies retorna os órgãos que atendem ao filtro, indexados pela sigla,
+// e a lista, ordenada, das siglas no formato usado por GetMonthlyInfo.
+func (h handler) filterAgencies(f agencyFilter) (map[string]models.Agency, []models.Agency, error) {
+	all, err := h.client.Db.GetAllAgencies()
+	if err != nil {
+		return nil, nil, err
+	}
+	agencies := map[string]models.Agency{}
+	var ids []models.Agency
+	for _, a := range all {
+		if f.match(a) {
+			agencies[a.ID] = a
+			ids = append(ids, models.Agency{ID: a.ID})
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].ID < ids[j].ID
+	})
+	return agencies, ids, nil
+}
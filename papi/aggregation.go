@@ -0,0 +1,296 @@
+package papi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/dadosjusbr/api/jurisdiction"
+	"github.com/dadosjusbr/storage/models"
+)
+
+// Dimensões pelas quais os dados mensais podem ser agregados.
+var aggregationDimensions = []string{"orgao", "grupo", "uf", "ano", "mes"}
+
+// Medidas calculadas para cada agregado.
+var aggregationMeasures = []string{"totais", "medias", "quantidades", "rubricas"}
+
+// Medidas usadas quando a consulta não informa nenhuma.
+var defaultAggregationMeasures = []string{"totais", "medias", "quantidades"}
+
+// newSummaryzedMI converte os dados mensais de um órgão no resumo retornado
+// pela API. Retorna false quando a coleta informou que os dados estão
+// indisponíveis, caso em que o mês deve ser exibido como se não houvesse dados.
+func (h handler) newSummaryzedMI(mi models.AgencyMonthlyInfo) (summaryzedMI, bool) {
+	sumMI := summaryzedMI{
+		AgencyID:         mi.AgencyID,
+		Month:            mi.Month,
+		Year:             mi.Year,
+		ManualCollection: mi.ManualCollection,
+		Inconsistent:     mi.Inconsistent,
+	}
+	// Fazemos duas checagens no formato do ProcInfo para saber se ele é vazio pois alguns dados diferem, no banco de dados, quando o procinfo é nulo.
+	if mi.ProcInfo != nil && mi.ProcInfo.String() != "" {
+		//O status 4 informa que os dados estão indisponíveis. Ao removê-los dos resultados da API, garantimos que eles sejam exibidos como se não houvesse dados.
+		if mi.ProcInfo.Status == 4 {
+			return summaryzedMI{}, false
+		}
+		sumMI.Error = &miError{
+			ErrorMessage: mi.ProcInfo.Stderr,
+			Status:       mi.ProcInfo.Status,
+			Cmd:          mi.ProcInfo.Cmd,
+		}
+		return sumMI, true
+	}
+	if mi.Package != nil {
+		sumMI.Package = &backup{
+			URL:  h.formatDownloadUrl(mi.Package.URL),
+			Hash: mi.Package.Hash,
+			Size: mi.Package.Size,
+		}
+	}
+	if mi.Summary != nil {
+		sumMI.Summary = &summaries{
+			MemberActive: summary{
+				Count:              mi.Summary.Count,
+				BaseRemuneration:   newDataSummary(mi.Summary.BaseRemuneration),
+				OtherRemunerations: newDataSummary(mi.Summary.OtherRemunerations),
+				Discounts:          newDataSummary(mi.Summary.Discounts),
+				Remunerations:      newDataSummary(mi.Summary.Remunerations),
+				ItemSummary:        itemSummary(mi.Summary.ItemSummary),
+			},
+		}
+	}
+	if mi.Meta != nil {
+		sumMI.Metadata = &metadata{
+			OpenFormat:       mi.Meta.OpenFormat,
+			Access:           mi.Meta.Access,
+			Extension:        mi.Meta.Extension,
+			StrictlyTabular:  mi.Meta.StrictlyTabular,
+			ConsistentFormat: mi.Meta.ConsistentFormat,
+			HasEnrollment:    mi.Meta.HaveEnrollment,
+			HasCapacity:      mi.Meta.ThereIsACapacity,
+			HasPosition:      mi.Meta.HasPosition,
+			BaseRevenue:      mi.Meta.BaseRevenue,
+			OtherRecipes:     mi.Meta.OtherRecipes,
+			Expenditure:      mi.Meta.Expenditure,
+		}
+	}
+	if mi.Score != nil {
+		sumMI.Score = &score{
+			Score:             mi.Score.Score,
+			CompletenessScore: mi.Score.CompletenessScore,
+			EasinessScore:     mi.Score.EasinessScore,
+		}
+	}
+	sumMI.Collect = &collect{
+		Duration:       mi.Duration,
+		CrawlerRepo:    mi.CrawlerRepo,
+		CrawlerVersion: mi.CrawlerVersion,
+		ParserRepo:     mi.ParserRepo,
+		ParserVersion:  mi.ParserVersion,
+	}
+	return sumMI, true
+}
+
+// newV1SummaryzedMI monta o resumo mensal no formato da rota v1, anterior às
+// rubricas identificadas e à marcação de inconsistência: resumo_rubricas é
+// omitido e inconsistente é sempre false.
+func (h handler) newV1SummaryzedMI(mi models.AgencyMonthlyInfo) (summaryzedMI, bool) {
+	sumMI, ok := h.newSummaryzedMI(mi)
+	sumMI.Inconsistent = false
+	if sumMI.Summary != nil {
+		sumMI.Summary.MemberActive.ItemSummary = nil
+	}
+	return sumMI, ok
+}
+
+// parseAggregationList lê uma lista de valores separados por vírgula, aceitando
+// apenas os valores informados e ignorando repetições.
+func parseAggregationList(name, qp string, accepted []string) ([]string, error) {
+	var values []string
+	for _, v := range strings.Split(qp, ",") {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "" {
+			continue
+		}
+		if !slices.Contains(accepted, v) {
+			return nil, fmt.Errorf("parâmetro %s '%s' é inválido! Valores aceitos: %s.", name, v, strings.Join(accepted, ", "))
+		}
+		if !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// parseAggregationNumbers lê uma lista de números separados por vírgula entre
+// min e max.
+func parseAggregationNumbers(name, qp string, min, max int) ([]int, error) {
+	var values []int
+	for _, v := range strings.Split(qp, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < min || n > max {
+			return nil, fmt.Errorf("parâmetro %s '%s' é inválido!", name, v)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func newDataSummary(d models.DataSummary) dataSummary {
+	return dataSummary{
+		Max:     d.Max,
+		Min:     d.Min,
+		Average: d.Average,
+		Total:   d.Total,
+	}
+}
+
+// Chave de um agregado. Os campos das dimensões que não foram pedidas ficam vazios.
+type aggregationKey struct {
+	AgencyID string
+	Group    string
+	UF       string
+	Year     int
+	Month    int
+}
+
+func newAggregationKey(dimensions []string, agency models.Agency, sumMI summaryzedMI) aggregationKey {
+	var k aggregationKey
+	for _, d := range dimensions {
+		switch d {
+		case "orgao":
+			k.AgencyID = sumMI.AgencyID
+		case "grupo":
+			k.Group = jurisdiction.GroupOf(agency.Type)
+		case "uf":
+			k.UF = strings.ToUpper(agency.UF)
+		case "ano":
+			k.Year = sumMI.Year
+		case "mes":
+			k.Month = sumMI.Month
+		}
+	}
+	return k
+}
+
+// Valores acumulados de um agregado.
+type aggregationAccumulator struct {
+	totals       aggregateValues
+	items        itemSummary
+	agencies     map[string]bool
+	months       map[[2]int]bool
+	agencyMonths int
+	memberMonths int
+	uncorrected  bool
+}
+
+// newAggregation agrega os resumos mensais, já corrigidos, pelas dimensões
+// informadas e calcula as medidas pedidas. Apenas os meses com dados de
+// remuneração são considerados. As médias são por membro e por mês, ou seja,
+// os totais divididos pela soma da quantidade de membros de cada mês, e a
+// quantidade de membros é a média mensal da soma dos membros dos órgãos. Os
+// agregados são ordenados pelas dimensões, na ordem em que foram informadas.
+func newAggregation(dimensions, measures []string, agencies map[string]models.Agency, sumMIs []summaryzedMI) []aggregationRow {
+	accs := map[aggregationKey]*aggregationAccumulator{}
+	for _, sumMI := range sumMIs {
+		if sumMI.Error != nil || sumMI.Summary == nil {
+			continue
+		}
+		k := newAggregationKey(dimensions, agencies[sumMI.AgencyID], sumMI)
+		acc, ok := accs[k]
+		if !ok {
+			acc = &aggregationAccumulator{agencies: map[string]bool{}, months: map[[2]int]bool{}}
+			accs[k] = acc
+		}
+		m := sumMI.Summary.MemberActive
+		acc.totals.BaseRemuneration += m.BaseRemuneration.Total
+		acc.totals.OtherRemunerations += m.OtherRemunerations.Total
+		acc.totals.Discounts += m.Discounts.Total
+		acc.totals.Remunerations += m.Remunerations.Total
+		for item, v := range m.ItemSummary {
+			if acc.items == nil {
+				acc.items = itemSummary{}
+			}
+			acc.items[item] += v
+		}
+		acc.agencies[sumMI.AgencyID] = true
+		acc.months[[2]int{sumMI.Year, sumMI.Month}] = true
+		acc.agencyMonths++
+		acc.memberMonths += m.Count
+		acc.uncorrected = acc.uncorrected || sumMI.Uncorrected
+	}
+
+	rows := []aggregationRow{}
+	for k, acc := range accs {
+		row := aggregationRow{AgencyID: k.AgencyID, Group: k.Group, UF: k.UF, Year: k.Year, Month: k.Month, Uncorrected: acc.uncorrected}
+		for _, m := range measures {
+			switch m {
+			case "totais":
+				totals := acc.totals
+				row.Totals = &totals
+			case "medias":
+				averages := aggregateValues{}
+				if acc.memberMonths > 0 {
+					n := float64(acc.memberMonths)
+					averages = aggregateValues{
+						BaseRemuneration:   acc.totals.BaseRemuneration / n,
+						OtherRemunerations: acc.totals.OtherRemunerations / n,
+						Discounts:          acc.totals.Discounts / n,
+						Remunerations:      acc.totals.Remunerations / n,
+					}
+				}
+				row.Averages = &averages
+			case "quantidades":
+				row.Counts = &aggregateCounts{
+					Agencies:     len(acc.agencies),
+					AgencyMonths: acc.agencyMonths,
+					Members:      float64(acc.memberMonths) / float64(len(acc.months)),
+				}
+			case "rubricas":
+				row.Items = acc.items
+				if row.Items == nil {
+					row.Items = itemSummary{}
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		for _, d := range dimensions {
+			switch d {
+			case "orgao":
+				if a.AgencyID != b.AgencyID {
+					return a.AgencyID < b.AgencyID
+				}
+			case "grupo":
+				if a.Group != b.Group {
+					return a.Group < b.Group
+				}
+			case "uf":
+				if a.UF != b.UF {
+					return a.UF < b.UF
+				}
+			case "ano":
+				if a.Year != b.Year {
+					return a.Year < b.Year
+				}
+			case "mes":
+				if a.Month != b.Month {
+					return a.Month < b.Month
+				}
+			}
+		}
+		return false
+	})
+	return rows
+}
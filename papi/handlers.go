@@ -4,25 +4,41 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/slices"
 
+	"github.com/dadosjusbr/api/blobstore"
+	"github.com/dadosjusbr/api/daterange"
+	"github.com/dadosjusbr/api/ipca"
+	"github.com/dadosjusbr/api/jurisdiction"
 	"github.com/dadosjusbr/storage"
 	"github.com/dadosjusbr/storage/models"
 	"github.com/labstack/echo/v4"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"gorm.io/gorm"
 )
 
 type handler struct {
 	client         *storage.Client
+	conn           *gorm.DB
+	newrelic       *newrelic.Application
+	snapshots      blobstore.Store // nil quando os snapshots não estão disponíveis
+	loc            *time.Location
 	dadosJusURL    string
 	packageRepoURL string
 }
 
-func NewHandler(client *storage.Client, dadosJusURL, packageRepoURL string) *handler {
+func NewHandler(client *storage.Client, conn *gorm.DB, newrelic *newrelic.Application, snapshots blobstore.Store, loc *time.Location, dadosJusURL, packageRepoURL string) *handler {
 	return &handler{
 		client:         client,
+		conn:           conn,
+		newrelic:       newrelic,
+		snapshots:      snapshots,
+		loc:            loc,
 		dadosJusURL:    dadosJusURL,
 		packageRepoURL: packageRepoURL,
 	}
@@ -170,94 +186,8 @@ func (h handler) GetMonthlyInfo(c echo.Context) error {
 	var sumMI []summaryzedMI
 	for i := range monthlyInfo {
 		for _, mi := range monthlyInfo[i] {
-			// Fazemos duas checagens no formato do ProcInfo para saber se ele é vazio pois alguns dados diferem, no banco de dados, quando o procinfo é nulo.
-			if mi.ProcInfo == nil || mi.ProcInfo.String() == "" {
-				sumMI = append(
-					sumMI,
-					summaryzedMI{
-						AgencyID: mi.AgencyID,
-						Error:    nil,
-						Month:    mi.Month,
-						Year:     mi.Year,
-						Package: &backup{
-							URL:  h.formatDownloadUrl(mi.Package.URL),
-							Hash: mi.Package.Hash,
-							Size: mi.Package.Size,
-						},
-						Summary: &summaries{
-							MemberActive: summary{
-								Count: mi.Summary.Count,
-								BaseRemuneration: dataSummary{
-									Max:     mi.Summary.BaseRemuneration.Max,
-									Min:     mi.Summary.BaseRemuneration.Min,
-									Average: mi.Summary.BaseRemuneration.Average,
-									Total:   mi.Summary.BaseRemuneration.Total,
-								},
-								OtherRemunerations: dataSummary{
-									Max:     mi.Summary.OtherRemunerations.Max,
-									Min:     mi.Summary.OtherRemunerations.Min,
-									Average: mi.Summary.OtherRemunerations.Average,
-									Total:   mi.Summary.OtherRemunerations.Total,
-								},
-								Discounts: dataSummary{
-									Max:     mi.Summary.Discounts.Max,
-									Min:     mi.Summary.Discounts.Min,
-									Average: mi.Summary.Discounts.Average,
-									Total:   mi.Summary.Discounts.Total,
-								},
-								Remunerations: dataSummary{
-									Max:     mi.Summary.Remunerations.Max,
-									Min:     mi.Summary.Remunerations.Min,
-									Average: mi.Summary.Remunerations.Average,
-									Total:   mi.Summary.Remunerations.Total,
-								},
-							},
-						},
-						Metadata: &metadata{
-							OpenFormat:       mi.Meta.OpenFormat,
-							Access:           mi.Meta.Access,
-							Extension:        mi.Meta.Extension,
-							StrictlyTabular:  mi.Meta.StrictlyTabular,
-							ConsistentFormat: mi.Meta.ConsistentFormat,
-							HasEnrollment:    mi.Meta.HaveEnrollment,
-							HasCapacity:      mi.Meta.ThereIsACapacity,
-							HasPosition:      mi.Meta.HasPosition,
-							BaseRevenue:      mi.Meta.BaseRevenue,
-							OtherRecipes:     mi.Meta.OtherRecipes,
-							Expenditure:      mi.Meta.Expenditure,
-						},
-						Score: &score{
-							Score:             mi.Score.Score,
-							CompletenessScore: mi.Score.CompletenessScore,
-							EasinessScore:     mi.Score.EasinessScore,
-						},
-						Collect: &collect{
-							Duration:       mi.Duration,
-							CrawlerRepo:    mi.CrawlerRepo,
-							CrawlerVersion: mi.CrawlerVersion,
-							ParserRepo:     mi.ParserRepo,
-							ParserVersion:  mi.ParserVersion,
-						},
-						ManualCollection: mi.ManualCollection,
-					})
-				// The status 4 is a report from crawlers that data is unavailable or malformed. By removing them from the API results, we make sure they are displayed as if there is no data.
-			} else if mi.ProcInfo.Status != 4 {
-				sumMI = append(
-					sumMI,
-					summaryzedMI{
-						AgencyID: mi.AgencyID,
-						Error: &miError{
-							ErrorMessage: mi.ProcInfo.Stderr,
-							Status:       mi.ProcInfo.Status,
-							Cmd:          mi.ProcInfo.Cmd,
-						},
-						Month:            mi.Month,
-						Year:             mi.Year,
-						Package:          nil,
-						Summary:          nil,
-						Metadata:         nil,
-						ManualCollection: mi.ManualCollection,
-					})
+			if s, ok := h.newV1SummaryzedMI(mi); ok {
+				sumMI = append(sumMI, s)
 			}
 		}
 	}
@@ -274,6 +204,8 @@ func (h handler) GetMonthlyInfo(c echo.Context) error {
 //	@Param			ano		path		int				true	"Ano para o qual os dados estão sendo solicitados (dados disponíveis a partir de 2018)."
 //	@Param			orgao	path		string			true	"Sigla do órgão para o qual os dados estão sendo solicitados. Ex.: tjal, tjba, mppb"
 //	@Param			mes		path		int				true	"Mês para o qual os dados estão sendo solicitados (1-12)."
+//	@Param			corrigir	query		string			false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao."
+//	@Param			base		query		string			false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
 //	@Router			/v2/dados/{orgao}/{ano}/{mes} [get]
 func (h handler) V2GetMonthlyInfo(c echo.Context) error {
 	year, err := strconv.Atoi(c.Param("ano"))
@@ -286,6 +218,10 @@ func (h handler) V2GetMonthlyInfo(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, fmt.Sprintf("Parâmetro mes=%d inválido", month))
 	}
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
 
 	var monthlyInfo *models.AgencyMonthlyInfo
 	monthlyInfo, _, err = h.client.Db.GetOMA(month, year, agencyName)
@@ -296,96 +232,11 @@ func (h handler) V2GetMonthlyInfo(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, "Error getting OMA data")
 	}
 
-	var sumMI summaryzedMI
-	if monthlyInfo.ProcInfo.String() == "" {
-		sumMI =
-			summaryzedMI{
-				AgencyID: monthlyInfo.AgencyID,
-				Error:    nil,
-				Month:    monthlyInfo.Month,
-				Year:     monthlyInfo.Year,
-				Package: &backup{
-					URL:  h.formatDownloadUrl(monthlyInfo.Package.URL),
-					Hash: monthlyInfo.Package.Hash,
-					Size: monthlyInfo.Package.Size,
-				},
-				Summary: &summaries{
-					MemberActive: summary{
-						Count: monthlyInfo.Summary.Count,
-						BaseRemuneration: dataSummary{
-							Max:     monthlyInfo.Summary.BaseRemuneration.Max,
-							Min:     monthlyInfo.Summary.BaseRemuneration.Min,
-							Average: monthlyInfo.Summary.BaseRemuneration.Average,
-							Total:   monthlyInfo.Summary.BaseRemuneration.Total,
-						},
-						OtherRemunerations: dataSummary{
-							Max:     monthlyInfo.Summary.OtherRemunerations.Max,
-							Min:     monthlyInfo.Summary.OtherRemunerations.Min,
-							Average: monthlyInfo.Summary.OtherRemunerations.Average,
-							Total:   monthlyInfo.Summary.OtherRemunerations.Total,
-						},
-						Discounts: dataSummary{
-							Max:     monthlyInfo.Summary.Discounts.Max,
-							Min:     monthlyInfo.Summary.Discounts.Min,
-							Average: monthlyInfo.Summary.Discounts.Average,
-							Total:   monthlyInfo.Summary.Discounts.Total,
-						},
-						Remunerations: dataSummary{
-							Max:     monthlyInfo.Summary.Remunerations.Max,
-							Min:     monthlyInfo.Summary.Remunerations.Min,
-							Average: monthlyInfo.Summary.Remunerations.Average,
-							Total:   monthlyInfo.Summary.Remunerations.Total,
-						},
-						ItemSummary: itemSummary(monthlyInfo.Summary.ItemSummary),
-					},
-				},
-				Metadata: &metadata{
-					OpenFormat:       monthlyInfo.Meta.OpenFormat,
-					Access:           monthlyInfo.Meta.Access,
-					Extension:        monthlyInfo.Meta.Extension,
-					StrictlyTabular:  monthlyInfo.Meta.StrictlyTabular,
-					ConsistentFormat: monthlyInfo.Meta.ConsistentFormat,
-					HasEnrollment:    monthlyInfo.Meta.HaveEnrollment,
-					HasCapacity:      monthlyInfo.Meta.ThereIsACapacity,
-					HasPosition:      monthlyInfo.Meta.HasPosition,
-					BaseRevenue:      monthlyInfo.Meta.BaseRevenue,
-					OtherRecipes:     monthlyInfo.Meta.OtherRecipes,
-					Expenditure:      monthlyInfo.Meta.Expenditure,
-				},
-				Score: &score{
-					Score:             monthlyInfo.Score.Score,
-					CompletenessScore: monthlyInfo.Score.CompletenessScore,
-					EasinessScore:     monthlyInfo.Score.EasinessScore,
-				},
-				Collect: &collect{
-					Duration:       monthlyInfo.Duration,
-					CrawlerRepo:    monthlyInfo.CrawlerRepo,
-					CrawlerVersion: monthlyInfo.CrawlerVersion,
-					ParserRepo:     monthlyInfo.ParserRepo,
-					ParserVersion:  monthlyInfo.ParserVersion,
-				},
-				ManualCollection: monthlyInfo.ManualCollection,
-				Inconsistent:    monthlyInfo.Inconsistent,
-			}
-		//O status 4 informa que os dados estão indisponíveis. Ao removê-los dos resultados da API, garantimos que eles sejam exibidos como se não houvesse dados.
-	} else if monthlyInfo.ProcInfo.Status != 4 {
-		sumMI = summaryzedMI{
-			AgencyID: monthlyInfo.AgencyID,
-			Error: &miError{
-				ErrorMessage: monthlyInfo.ProcInfo.Stderr,
-				Status:       monthlyInfo.ProcInfo.Status,
-				Cmd:          monthlyInfo.ProcInfo.Cmd,
-			},
-			Month:            monthlyInfo.Month,
-			Year:             monthlyInfo.Year,
-			Package:          nil,
-			Summary:          nil,
-			Metadata:         nil,
-			ManualCollection: monthlyInfo.ManualCollection,
-		}
-	} else {
+	sumMI, ok := h.newSummaryzedMI(*monthlyInfo)
+	if !ok {
 		return c.NoContent(http.StatusNoContent)
 	}
+	sumMI.correct(corr)
 	return c.JSON(http.StatusOK, sumMI)
 }
 
@@ -398,6 +249,8 @@ func (h handler) V2GetMonthlyInfo(c echo.Context) error {
 //	@Failure		404		{string}	string			"Não existem dados para os parâmetros informados"
 //	@Param			ano		path		int				true	"Ano para o qual os dados estão sendo solicitados (dados disponíveis a partir de 2018)."
 //	@Param			orgao	path		string			true	"Sigla do órgão para o qual os dados estão sendo solicitados. Ex.: tjal, tjba, mppb"
+//	@Param			corrigir	query		string			false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao."
+//	@Param			base		query		string			false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
 //	@Router			/v2/dados/{orgao}/{ano} [get]
 func (h handler) GetMonthlyInfosByYear(c echo.Context) error {
 	year, err := strconv.Atoi(c.Param("ano"))
@@ -405,6 +258,11 @@ func (h handler) GetMonthlyInfosByYear(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, fmt.Sprintf("Parâmetro ano=%d inválido", year))
 	}
 
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
 	agencyName := strings.ToLower(c.Param("orgao"))
 	var monthlyInfo map[string][]models.AgencyMonthlyInfo
 	monthlyInfo, err = h.client.Db.GetMonthlyInfo([]models.Agency{{ID: agencyName}}, year)
@@ -420,97 +278,14 @@ func (h handler) GetMonthlyInfosByYear(c echo.Context) error {
 	var sumMI []summaryzedMI
 	for i := range monthlyInfo {
 		for _, mi := range monthlyInfo[i] {
-			if mi.ProcInfo.String() == "" {
-				sumMI = append(
-					sumMI,
-					summaryzedMI{
-						AgencyID: mi.AgencyID,
-						Error:    nil,
-						Month:    mi.Month,
-						Year:     mi.Year,
-						Package: &backup{
-							URL:  h.formatDownloadUrl(mi.Package.URL),
-							Hash: mi.Package.Hash,
-							Size: mi.Package.Size,
-						},
-						Summary: &summaries{
-							MemberActive: summary{
-								Count: mi.Summary.Count,
-								BaseRemuneration: dataSummary{
-									Max:     mi.Summary.BaseRemuneration.Max,
-									Min:     mi.Summary.BaseRemuneration.Min,
-									Average: mi.Summary.BaseRemuneration.Average,
-									Total:   mi.Summary.BaseRemuneration.Total,
-								},
-								OtherRemunerations: dataSummary{
-									Max:     mi.Summary.OtherRemunerations.Max,
-									Min:     mi.Summary.OtherRemunerations.Min,
-									Average: mi.Summary.OtherRemunerations.Average,
-									Total:   mi.Summary.OtherRemunerations.Total,
-								},
-								Discounts: dataSummary{
-									Max:     mi.Summary.Discounts.Max,
-									Min:     mi.Summary.Discounts.Min,
-									Average: mi.Summary.Discounts.Average,
-									Total:   mi.Summary.Discounts.Total,
-								},
-								Remunerations: dataSummary{
-									Max:     mi.Summary.Remunerations.Max,
-									Min:     mi.Summary.Remunerations.Min,
-									Average: mi.Summary.Remunerations.Average,
-									Total:   mi.Summary.Remunerations.Total,
-								},
-								ItemSummary: itemSummary(mi.Summary.ItemSummary),
-							},
-						},
-						Metadata: &metadata{
-							OpenFormat:       mi.Meta.OpenFormat,
-							Access:           mi.Meta.Access,
-							Extension:        mi.Meta.Extension,
-							StrictlyTabular:  mi.Meta.StrictlyTabular,
-							ConsistentFormat: mi.Meta.ConsistentFormat,
-							HasEnrollment:    mi.Meta.HaveEnrollment,
-							HasCapacity:      mi.Meta.ThereIsACapacity,
-							HasPosition:      mi.Meta.HasPosition,
-							BaseRevenue:      mi.Meta.BaseRevenue,
-							OtherRecipes:     mi.Meta.OtherRecipes,
-							Expenditure:      mi.Meta.Expenditure,
-						},
-						Score: &score{
-							Score:             mi.Score.Score,
-							CompletenessScore: mi.Score.CompletenessScore,
-							EasinessScore:     mi.Score.EasinessScore,
-						},
-						Collect: &collect{
-							Duration:       mi.Duration,
-							CrawlerRepo:    mi.CrawlerRepo,
-							CrawlerVersion: mi.CrawlerVersion,
-							ParserRepo:     mi.ParserRepo,
-							ParserVersion:  mi.ParserVersion,
-						},
-						ManualCollection: mi.ManualCollection,
-					})
-				//O status 4 informa que os dados estão indisponíveis. Ao removê-los dos resultados da API, garantimos que eles sejam exibidos como se não houvesse dados.
-			} else if mi.ProcInfo.Status != 4 {
-				sumMI = append(
-					sumMI,
-					summaryzedMI{
-						AgencyID: mi.AgencyID,
-						Error: &miError{
-							ErrorMessage: mi.ProcInfo.Stderr,
-							Status:       mi.ProcInfo.Status,
-							Cmd:          mi.ProcInfo.Cmd,
-						},
-						Month:            mi.Month,
-						Year:             mi.Year,
-						Package:          nil,
-						Summary:          nil,
-						Metadata:         nil,
-						ManualCollection: mi.ManualCollection,
-					})
+			if s, ok := h.newSummaryzedMI(mi); ok {
+				sumMI = append(sumMI, s)
 			}
 		}
 	}
+	for i := range sumMI {
+		sumMI[i].correct(corr)
+	}
 	return c.JSON(http.StatusOK, sumMI)
 }
 
@@ -525,6 +300,8 @@ func (h handler) GetMonthlyInfosByYear(c echo.Context) error {
 //	@Param			valor						path		string				true	"Jurisdição ou sigla do órgao. Ex.: tjal, mpdft, justica-estadual, etc."
 //	@Param			agregado					query		boolean				false	"Alterna entre o Índice de Transparência geral de todos os órgãos (true) ou o detalhamento do índice de cada órgão mês a mês."
 //	@Param			detalhe						query		boolean				false	"Define se os metadados utilizados para calcular o índice serão retornados ou não."
+//	@Param			de							query		string				false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com ano e mês. Padrão: 2018-01."
+//	@Param			ate							query		string				false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com ano e mês. Padrão: mês atual."
 //	@Router			/v2/indice/{param}/{valor}	[get]
 func (h handler) V2GetAggregateIndexesWithParams(c echo.Context) error {
 	param := c.Param("param")
@@ -534,24 +311,14 @@ func (h handler) V2GetAggregateIndexesWithParams(c echo.Context) error {
 	agregado := c.QueryParam("agregado")
 	detalhe := c.QueryParam("detalhe")
 
-	groupMap := map[string]string{
-		"justica-eleitoral":    "Eleitoral",
-		"ministerios-publicos": "Ministério",
-		"justica-estadual":     "Estadual",
-		"justica-do-trabalho":  "Trabalho",
-		"justica-federal":      "Federal",
-		"justica-militar":      "Militar",
-		"justica-superior":     "Superior",
-		"conselhos-de-justica": "Conselho",
-	}
-
 	// porJurisdicao tbm será usada para verificar a possibilidade de uma BadRequest
 	var porJurisdicao bool
 
 	// Verificamos se o parâmetro é válido.
 	if param == "grupo" {
-		if _, porJurisdicao = groupMap[valor]; porJurisdicao {
-			valor = groupMap[valor]
+		var j string
+		if j, porJurisdicao = jurisdiction.Of(valor); porJurisdicao {
+			valor = j
 		} else {
 			return c.JSON(http.StatusBadRequest, fmt.Sprintf("Jurisdição inválida: %s.", valor))
 		}
@@ -583,9 +350,23 @@ func (h handler) V2GetAggregateIndexesWithParams(c echo.Context) error {
 		}
 	}
 
+	dateRange, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if dateRange != nil && ano != "" {
+		return c.JSON(http.StatusBadRequest, "Os parâmetros de e ate não podem ser usados com ano e mês.")
+	}
+
 	var indexes map[string][]models.IndexInformation
 
-	if ano != "" && mes != "" {
+	if dateRange != nil {
+		// Caso um intervalo de meses seja informado
+		indexes, err = h.indexInformationInRange(valor, dateRange)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, fmt.Sprintf("Erro consultando os índices de %s %s", dateRange, msg[param]))
+		}
+	} else if ano != "" && mes != "" {
 		// Caso o ano e o mês sejam informados
 		indexes, err = h.client.Db.GetIndexInformation(valor, mesInt, anoInt)
 		if err != nil {
@@ -714,6 +495,8 @@ func (h handler) V2GetAggregateIndexesWithParamsByYearAndMonth(c echo.Context) e
 //	@Produce		json
 //	@Param			agregado	query		boolean						false	"Alterna entre o Índice de Transparência geral de todos os órgãos (true) ou o detalhamento do índice de cada órgão mês a mês."
 //	@Param			detalhe		query		boolean						false	"Define se os metadados utilizados para calcular o índice serão retornados ou não."
+//	@Param			de			query		string						false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Padrão: 2018-01."
+//	@Param			ate			query		string						false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Padrão: mês atual."
 //	@Success		200			{object}	[]aggregateIndexesByGroup	"Requisição bem sucedida."
 //	@Failure		500			{string}	string						"Erro interno do servidor."
 //	@Router			/v2/indice 																																																																																																																																																																																																																																																																																																																																																			[get]
@@ -721,7 +504,16 @@ func (h handler) V2GetAggregateIndexes(c echo.Context) error {
 	agregado := c.QueryParam("agregado")
 	detalhe := c.QueryParam("detalhe")
 
-	indexes, err := h.client.Db.GetIndexInformation("", 0, 0)
+	dateRange, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	var indexes map[string][]models.IndexInformation
+	if dateRange != nil {
+		indexes, err = h.indexInformationInRange("", dateRange)
+	} else {
+		indexes, err = h.client.Db.GetIndexInformation("", 0, 0)
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, "Erro consultando os índices para todos os órgãos.")
 	}
@@ -827,18 +619,39 @@ func (h handler) V2GetAggregateIndexes(c echo.Context) error {
 //	@Success		200					{object}	allAgencyInformation	"Requisição bem sucedida."
 //	@Failure		400					{string}	string					"Requisição inválida."
 //	@Param			orgao				path		string					true	"Sigla do órgão para o qual os dados estão sendo solicitados. Ex.: tjal, tjba, mppb"
+//	@Param			corrigir			query		string					false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao."
+//	@Param			base				query		string					false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
+//	@Param			de					query		string					false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Padrão: 2018-01."
+//	@Param			ate					query		string					false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Padrão: mês atual."
 //	@Router			/v2/dados/{orgao} 	[get]
 func (h handler) V2GetAllAgencyInformation(c echo.Context) error {
 	agency := strings.ToLower(c.Param("orgao"))
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	dateRange, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
 
 	ag, err := h.client.Db.GetAgency(agency)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fmt.Sprintf("Órgão não encontrado: %s", strings.ToUpper(agency)))
 	}
-	collections, err := h.client.Db.GetAllAgencyCollection(agency)
+	allCollections, err := h.client.Db.GetAllAgencyCollection(agency)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, fmt.Sprintf("Não encontramos dados para o órgão %s", strings.ToUpper(agency)))
 	}
+	var collections []models.AgencyMonthlyInfo
+	for _, mi := range allCollections {
+		if dateRange.Contains(mi.Year, mi.Month) {
+			collections = append(collections, mi)
+		}
+	}
+	if len(collections) == 0 {
+		return c.JSON(http.StatusNotFound, fmt.Sprintf("Não encontramos dados para o órgão %s", strings.ToUpper(agency)))
+	}
 
 	aggregateScore := 0.0
 	aggregateEasinessScore := 0.0
@@ -847,67 +660,17 @@ func (h handler) V2GetAllAgencyInformation(c echo.Context) error {
 	var result []summaryzedMI
 
 	for _, c := range collections {
-		if c.ProcInfo == nil || c.ProcInfo.String() == "" {
-			result = append(result, summaryzedMI{
-				Error: nil,
-				Month: c.Month,
-				Year:  c.Year,
-				Summary: &summaries{
-					MemberActive: summary{
-						Count: c.Summary.Count,
-						BaseRemuneration: dataSummary{
-							Max:     c.Summary.BaseRemuneration.Max,
-							Min:     c.Summary.BaseRemuneration.Min,
-							Average: c.Summary.BaseRemuneration.Average,
-							Total:   c.Summary.BaseRemuneration.Total,
-						},
-						OtherRemunerations: dataSummary{
-							Max:     c.Summary.OtherRemunerations.Max,
-							Min:     c.Summary.OtherRemunerations.Min,
-							Average: c.Summary.OtherRemunerations.Average,
-							Total:   c.Summary.OtherRemunerations.Total,
-						},
-						Discounts: dataSummary{
-							Max:     c.Summary.Discounts.Max,
-							Min:     c.Summary.Discounts.Min,
-							Average: c.Summary.Discounts.Average,
-							Total:   c.Summary.Discounts.Total,
-						},
-						Remunerations: dataSummary{
-							Max:     c.Summary.Remunerations.Max,
-							Min:     c.Summary.Remunerations.Min,
-							Average: c.Summary.Remunerations.Average,
-							Total:   c.Summary.Remunerations.Total,
-						},
-						ItemSummary: itemSummary(c.Summary.ItemSummary),
-					},
-				},
-				Metadata: &metadata{
-					OpenFormat:       c.Meta.OpenFormat,
-					Access:           c.Meta.Access,
-					Extension:        c.Meta.Extension,
-					StrictlyTabular:  c.Meta.StrictlyTabular,
-					ConsistentFormat: c.Meta.ConsistentFormat,
-					HasEnrollment:    c.Meta.HaveEnrollment,
-					HasCapacity:      c.Meta.ThereIsACapacity,
-					HasPosition:      c.Meta.HasPosition,
-					BaseRevenue:      c.Meta.BaseRevenue,
-					OtherRecipes:     c.Meta.OtherRecipes,
-					Expenditure:      c.Meta.Expenditure,
-				},
-				Score: &score{
-					Score:             c.Score.Score,
-					CompletenessScore: c.Score.CompletenessScore,
-					EasinessScore:     c.Score.EasinessScore,
-				},
-				ManualCollection: c.ManualCollection,
-			})
+		if s, ok := h.newSummaryzedMI(c); ok && s.Error == nil {
+			result = append(result, s)
 			numMonthsWithData++
 		}
 		aggregateScore += c.Score.Score
 		aggregateCompletenessScore += c.Score.CompletenessScore
 		aggregateEasinessScore += c.Score.EasinessScore
 	}
+	for i := range result {
+		result[i].correct(corr)
+	}
 
 	var collect []collecting
 	for _, c := range ag.Collecting {
@@ -939,6 +702,295 @@ func (h handler) V2GetAllAgencyInformation(c echo.Context) error {
 	return c.JSON(http.StatusOK, agencyInfo)
 }
 
+//	@ID				GetMonthlyInfosOfAgencies
+//	@Tags			public_api
+//	@Description	Busca os dados mensais de vários órgãos de uma só vez, no mesmo formato de /v2/dados/{orgao}/{ano}, para todos os anos do intervalo informado. Os dados são buscados com uma consulta ao banco por ano do intervalo, por isso os anos devem estar entre 2018 e o ano atual. Os órgãos podem ser filtrados por sigla, jurisdição e UF; sem filtros, todos os órgãos são retornados. Os dados são ordenados por ano, órgão e mês e enviados à medida que são lidos, como um array JSON (formato=json) ou com um objeto JSON por linha (formato=jsonl). Se houver um erro depois que o envio começou, a conexão é interrompida, para que uma resposta incompleta não pareça completa.
+//	@Produce		json
+//	@Produce		application/x-ndjson
+//	@Success		200			{object}	[]summaryzedMI	"Requisição bem-sucedida com dados mensais"
+//	@Failure		400			{string}	string			"Parâmetros inválidos"
+//	@Failure		500			{string}	string			"Erro interno do servidor"
+//	@Param			orgaos		query		string			false	"Siglas dos órgãos, separadas por vírgula. Ex.: tjal,tjba"
+//	@Param			grupos		query		string			false	"Jurisdições dos órgãos, separadas por vírgula. Ex.: justica-estadual,ministerios-publicos"
+//	@Param			ufs			query		string			false	"UFs dos órgãos, separadas por vírgula. Ex.: AL,BA"
+//	@Param			grupo		query		string			false	"Obsoleto: use grupos. Aceito apenas quando grupos não é informado."
+//	@Param			uf			query		string			false	"Obsoleto: use ufs. Aceito apenas quando ufs não é informado."
+//	@Param			ano_inicio	query		int				false	"Primeiro ano do intervalo, a partir de 2018. Padrão: 2018."
+//	@Param			ano_fim		query		int				false	"Último ano do intervalo, até o ano atual. Padrão: ano atual."
+//	@Param			de			query		string			false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com ano_inicio e ano_fim."
+//	@Param			ate			query		string			false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com ano_inicio e ano_fim."
+//	@Param			formato		query		string			false	"Formato da resposta: json (padrão) ou jsonl."
+//	@Param			corrigir	query		string			false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao."
+//	@Param			base		query		string			false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
+//	@Router			/v2/dados [get]
+func (h handler) GetMonthlyInfosOfAgencies(c echo.Context) error {
+	// Os parâmetros grupos e ufs têm os mesmos nomes de /v2/agregados. Os nomes
+	// grupo e uf, usados antes, continuam aceitos.
+	groups, ufs := c.QueryParam("grupos"), c.QueryParam("ufs")
+	if groups == "" {
+		groups = c.QueryParam("grupo")
+	}
+	if ufs == "" {
+		ufs = c.QueryParam("uf")
+	}
+	filter, err := newAgencyFilter(c.QueryParam("orgaos"), groups, ufs)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	dateRange, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	firstYear, lastYear := firstDataYear, time.Now().Year()
+	if dateRange != nil {
+		if c.QueryParam("ano_inicio") != "" || c.QueryParam("ano_fim") != "" {
+			return c.JSON(http.StatusBadRequest, "os parâmetros de e ate não podem ser usados com ano_inicio e ano_fim!")
+		}
+		firstYear, lastYear = dateRange.From.Year, dateRange.To.Year
+	}
+	if qp := c.QueryParam("ano_inicio"); qp != "" {
+		if firstYear, err = strconv.Atoi(qp); err != nil {
+			return c.JSON(http.StatusBadRequest, fmt.Sprintf("parâmetro ano_inicio '%s' é inválido!", qp))
+		}
+	}
+	if qp := c.QueryParam("ano_fim"); qp != "" {
+		if lastYear, err = strconv.Atoi(qp); err != nil {
+			return c.JSON(http.StatusBadRequest, fmt.Sprintf("parâmetro ano_fim '%s' é inválido!", qp))
+		}
+	}
+	// Cada ano do intervalo é uma consulta ao banco, então o intervalo fica
+	// limitado aos anos em que pode haver dados.
+	if firstYear < firstDataYear || firstYear > time.Now().Year() {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("parâmetro ano_inicio '%d' é inválido! Os dados estão disponíveis de %d a %d.", firstYear, firstDataYear, time.Now().Year()))
+	}
+	if lastYear < firstDataYear || lastYear > time.Now().Year() {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("parâmetro ano_fim '%d' é inválido! Os dados estão disponíveis de %d a %d.", lastYear, firstDataYear, time.Now().Year()))
+	}
+	if firstYear > lastYear {
+		return c.JSON(http.StatusBadRequest, "parâmetro ano_inicio deve ser menor ou igual a ano_fim!")
+	}
+	format := c.QueryParam("formato")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "jsonl" {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("formato inválido: '%s'. Os formatos aceitos são json e jsonl", format))
+	}
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	_, agencies, err := h.filterAgencies(filter)
+	if err != nil {
+		log.Printf("[monthly infos of agencies] error getting agencies: %q", err)
+		return c.JSON(http.StatusInternalServerError, "Erro consultando os órgãos.")
+	}
+
+	if format == "jsonl" {
+		c.Response().Header().Set(echo.HeaderContentType, "application/x-ndjson; charset=utf-8")
+	} else {
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	}
+	c.Response().WriteHeader(http.StatusOK)
+	out := newSummaryzedMIWriter(c.Response(), format == "jsonl")
+	// Os dados de todos os órgãos são buscados em uma única consulta por ano e
+	// enviados ao cliente ao fim de cada ano.
+	for year := firstYear; year <= lastYear && len(agencies) > 0; year++ {
+		monthlyInfo, err := h.client.Db.GetMonthlyInfo(agencies, year)
+		if err != nil {
+			// Neste ponto o cabeçalho da resposta já foi enviado. A conexão é
+			// interrompida para que o cliente não receba dados incompletos
+			// como se a resposta estivesse completa.
+			log.Printf("[monthly infos of agencies] error getting data (ano:%d): %q", year, err)
+			panic(http.ErrAbortHandler)
+		}
+		for _, a := range agencies {
+			mis := monthlyInfo[a.ID]
+			sort.Slice(mis, func(i, j int) bool {
+				return mis[i].Month < mis[j].Month
+			})
+			for _, mi := range mis {
+				if !dateRange.Contains(mi.Year, mi.Month) {
+					continue
+				}
+				sumMI, ok := h.newSummaryzedMI(mi)
+				if !ok {
+					continue
+				}
+				sumMI.correct(corr)
+				if err := out.write(sumMI); err != nil {
+					log.Printf("[monthly infos of agencies] error writing data: %q", err)
+					panic(http.ErrAbortHandler)
+				}
+			}
+		}
+		c.Response().Flush()
+	}
+	if err := out.close(); err != nil {
+		log.Printf("[monthly infos of agencies] error writing data: %q", err)
+	}
+	return nil
+}
+
+//	@ID				GetAggregates
+//	@Tags			public_api
+//	@Description	Agrega os dados mensais dos órgãos pelas dimensões informadas (órgão, jurisdição, UF, ano e mês), calculando para cada agregado as medidas pedidas: totais (remuneração base/salário, outras remunerações/benefícios, descontos e remunerações líquidas), médias mensais por membro, quantidades (órgãos, meses com dados e média mensal de membros) e gastos por rubrica. Sem dimensões, todos os dados são agregados em um único item. Os meses sem dados de remuneração são ignorados.
+//	@Produce		json
+//	@Success		200			{object}	aggregation	"Requisição bem sucedida."
+//	@Failure		400			{string}	string		"Parâmetros inválidos."
+//	@Failure		500			{string}	string		"Erro interno do servidor."
+//	@Param			anos		query		string		false	"Anos, separados por vírgula. Ex.: 2022,2023. Obrigatório se de e ate não forem informados."
+//	@Param			meses		query		string		false	"Meses (1-12), separados por vírgula. Padrão: todos os meses."
+//	@Param			de			query		string		false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01."
+//	@Param			ate			query		string		false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual."
+//	@Param			dimensoes	query		string		false	"Dimensões da agregação, separadas por vírgula: orgao, grupo, uf, ano e mes."
+//	@Param			medidas		query		string		false	"Medidas, separadas por vírgula: totais, medias, quantidades e rubricas. Padrão: totais, medias e quantidades."
+//	@Param			orgaos		query		string		false	"Siglas dos órgãos, separadas por vírgula. Ex.: tjal,tjba"
+//	@Param			grupos		query		string		false	"Jurisdições dos órgãos, separadas por vírgula. Ex.: justica-estadual,ministerios-publicos"
+//	@Param			ufs			query		string		false	"UFs dos órgãos, separadas por vírgula. Ex.: AL,BA"
+//	@Param			corrigir	query		string		false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao."
+//	@Param			base		query		string		false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
+//	@Router			/v2/agregados [get]
+func (h handler) GetAggregates(c echo.Context) error {
+	dimensions, err := parseAggregationList("dimensoes", c.QueryParam("dimensoes"), aggregationDimensions)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	measures, err := parseAggregationList("medidas", c.QueryParam("medidas"), aggregationMeasures)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if len(measures) == 0 {
+		measures = defaultAggregationMeasures
+	}
+	years, err := parseAggregationNumbers("anos", c.QueryParam("anos"), firstDataYear, time.Now().Year())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	months, err := parseAggregationNumbers("meses", c.QueryParam("meses"), 1, 12)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	dateRange, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if dateRange != nil {
+		if len(years) > 0 || len(months) > 0 {
+			return c.JSON(http.StatusBadRequest, "os parâmetros de e ate não podem ser usados com anos e meses!")
+		}
+		years = dateRange.Years()
+	}
+	if len(years) == 0 {
+		return c.JSON(http.StatusBadRequest, "parâmetro anos ou intervalo de/ate é obrigatório!")
+	}
+	filter, err := newAgencyFilter(c.QueryParam("orgaos"), c.QueryParam("grupos"), c.QueryParam("ufs"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	agencies, filtered, err := h.filterAgencies(filter)
+	if err != nil {
+		log.Printf("[aggregates] error getting agencies: %q", err)
+		return c.JSON(http.StatusInternalServerError, "Erro consultando os órgãos.")
+	}
+
+	var sumMIs []summaryzedMI
+	if len(filtered) > 0 {
+		for _, year := range years {
+			monthlyInfo, err := h.client.Db.GetMonthlyInfo(filtered, year)
+			if err != nil {
+				log.Printf("[aggregates] error getting monthly info (ano:%d): %q", year, err)
+				return c.JSON(http.StatusInternalServerError, fmt.Sprintf("Erro consultando os dados de %d.", year))
+			}
+			for _, mis := range monthlyInfo {
+				for _, mi := range mis {
+					if (len(months) > 0 && !slices.Contains(months, mi.Month)) || !dateRange.Contains(mi.Year, mi.Month) {
+						continue
+					}
+					if s, ok := h.newSummaryzedMI(mi); ok {
+						s.correct(corr)
+						sumMIs = append(sumMIs, s)
+					}
+				}
+			}
+		}
+	}
+	if dimensions == nil {
+		dimensions = []string{}
+	}
+	return c.JSON(http.StatusOK, aggregation{
+		Dimensions: dimensions,
+		Measures:   measures,
+		Correction: corr,
+		Rows:       newAggregation(dimensions, measures, agencies, sumMIs),
+	})
+}
+
+//	@ID				GetUpdates
+//	@Tags			public_api
+//	@Description	Lista os meses dos órgãos coletados ou recoletados depois de uma marca temporal, em ordem de coleta, com as versões do coletor e do parser. Apenas a coleta vigente de cada mês é listada.
+//	@Description	- Resultados paginados: enquanto a resposta tiver o campo proximo, ele deve ser passado no parâmetro de mesmo nome, com o mesmo desde, para obter a página seguinte
+//	@Description	- Para sincronizar uma cópia dos dados, use o campo ultima_coleta da última página como o parâmetro desde da próxima consulta
+//	@Produce		json
+//	@Param			desde	query		string				true	"Marca temporal no formato RFC 3339 (ex.: 2023-01-02T15:04:05Z) ou data no formato AAAA-MM-DD, considerada à meia-noite no horário de Brasília"
+//	@Param			proximo	query		string				false	"Cursor da próxima página, retornado no campo proximo da página anterior"
+//	@Success		200		{object}	collectionUpdates	"Requisição bem sucedida"
+//	@Failure		400		{string}	string				"Parâmetro desde ou proximo inválido"
+//	@Failure		500		{string}	string				"Erro interno do servidor"
+//	@Router			/v2/atualizacoes [get]
+func (h handler) GetUpdates(c echo.Context) error {
+	sinceQp := c.QueryParam("desde")
+	if sinceQp == "" {
+		return c.JSON(http.StatusBadRequest, "parâmetro desde é obrigatório!")
+	}
+	since, err := parseSince(sinceQp, h.loc)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	cursor, err := decodeUpdatesCursor(c.QueryParam("proximo"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	// Uma linha a mais indica se há próxima página.
+	results, err := h.updates(since, cursor, updatesPageSize+1)
+	if err != nil {
+		log.Printf("[updates] error getting updates (desde:%s proximo:%s): %q", sinceQp, c.QueryParam("proximo"), err)
+		return c.JSON(http.StatusInternalServerError, "erro ao buscar as atualizações")
+	}
+	return c.JSON(http.StatusOK, newCollectionUpdates(since, c.Request().Host, results, updatesPageSize))
+}
+
+// parseDateRange lê o intervalo de meses dos parâmetros de e ate da consulta.
+// Retorna nil se eles não forem informados.
+func parseDateRange(c echo.Context) (*daterange.Range, error) {
+	return daterange.Parse(c.QueryParam(daterange.FromParam), c.QueryParam(daterange.ToParam))
+}
+
+// indexInformationInRange busca os índices de transparência dos anos do
+// intervalo, descartando os meses fora dele.
+func (h handler) indexInformationInRange(name string, r *daterange.Range) (map[string][]models.IndexInformation, error) {
+	indexes := map[string][]models.IndexInformation{}
+	for _, year := range r.Years() {
+		yearIndexes, err := h.client.Db.GetIndexInformation(name, 0, year)
+		if err != nil {
+			return nil, err
+		}
+		for id, index := range yearIndexes {
+			for _, i := range index {
+				if r.Contains(i.Year, i.Month) {
+					indexes[id] = append(indexes[id], i)
+				}
+			}
+		}
+	}
+	return indexes, nil
+}
+
 func (h handler) formatDownloadUrl(url string) string {
 	return strings.Replace(url, h.packageRepoURL, h.dadosJusURL, -1)
 }
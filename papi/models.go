@@ -1,5 +1,11 @@
 package papi
 
+import (
+	"time"
+
+	"github.com/dadosjusbr/api/ipca"
+)
+
 type backup struct {
 	URL  string `json:"url,omitempty"`
 	Hash string `json:"hash,omitempty"`
@@ -88,6 +94,30 @@ type summaryzedMI struct {
 	ManualCollection bool       `json:"coleta_manual"`
 	Error            *miError   `json:"error,omitempty"`
 	Inconsistent 	 bool 		`json:"inconsistente"`
+	Correction       *ipca.Correction `json:"correcao_monetaria,omitempty"`
+	Uncorrected      bool             `json:"sem_correcao,omitempty"` // Valores nominais apesar de corrigir, pois o IPCA do mês não está na série
+}
+
+// correct corrige os valores monetários do resumo pela inflação do mês. Os
+// meses sem IPCA mantêm os valores nominais e são marcados como sem correção.
+func (s *summaryzedMI) correct(corr *ipca.Correction) {
+	if corr == nil || s.Summary == nil {
+		return
+	}
+	if corr.Uncorrected(s.Year, s.Month) {
+		s.Uncorrected = true
+		return
+	}
+	f := corr.Month(s.Year, s.Month)
+	m := &s.Summary.MemberActive
+	for _, d := range []*dataSummary{&m.BaseRemuneration, &m.OtherRemunerations, &m.Discounts, &m.Remunerations} {
+		d.Max *= f
+		d.Min *= f
+		d.Average *= f
+		d.Total *= f
+	}
+	m.ItemSummary = ipca.Items(m.ItemSummary, f)
+	s.Correction = corr
 }
 
 type agency struct {
@@ -148,3 +178,142 @@ type allAgencyInformation struct {
 	Score             *score         `json:"indice_transparencia,omitempty"`
 	Collections       []summaryzedMI `json:"coletas"`
 }
+
+type aggregateValues struct {
+	BaseRemuneration   float64 `json:"remuneracao_base"`
+	OtherRemunerations float64 `json:"outras_remuneracoes"`
+	Discounts          float64 `json:"descontos"`
+	Remunerations      float64 `json:"remuneracoes"`
+}
+
+type aggregateCounts struct {
+	Agencies     int     `json:"orgaos"`               // Quantidade de órgãos com dados
+	AgencyMonths int     `json:"meses_com_dados"`      // Quantidade de meses com dados, somando todos os órgãos
+	Members      float64 `json:"media_mensal_membros"` // Média mensal da soma dos membros dos órgãos
+}
+
+type aggregationRow struct {
+	AgencyID string           `json:"id_orgao,omitempty"`
+	Group    string           `json:"grupo,omitempty"`
+	UF       string           `json:"uf,omitempty"`
+	Year     int              `json:"ano,omitempty"`
+	Month    int              `json:"mes,omitempty"`
+	Totals   *aggregateValues `json:"totais,omitempty"`
+	Averages *aggregateValues `json:"medias,omitempty"` // Médias mensais por membro
+	Counts   *aggregateCounts `json:"quantidades,omitempty"`
+	Items    itemSummary      `json:"rubricas,omitempty"`
+	// Algum mês do agregado tem valores nominais, pois seu IPCA não está na série.
+	Uncorrected bool `json:"sem_correcao,omitempty"`
+}
+
+type aggregation struct {
+	Dimensions []string         `json:"dimensoes"`
+	Measures   []string         `json:"medidas"`
+	Correction *ipca.Correction `json:"correcao_monetaria,omitempty"`
+	Rows       []aggregationRow `json:"agregados"`
+}
+
+type updateDetails struct {
+	Orgao              string    `db:"orgao"`
+	Ano                int       `db:"ano"`
+	Mes                int       `db:"mes"`
+	Timestamp          time.Time `db:"timestamp"`
+	RepositorioColetor string    `db:"repositorio_coletor"`
+	VersaoColetor      string    `db:"versao_coletor"`
+	RepositorioParser  *string   `db:"repositorio_parser"`
+	VersaoParser       *string   `db:"versao_parser"`
+}
+
+// Meses dos órgãos coletados ou recoletados depois de uma marca temporal
+type collectionUpdates struct {
+	Since          time.Time          `json:"desde"`
+	LastCollection *time.Time         `json:"ultima_coleta"` // Marca da coleta mais recente da página, null se não há atualizações
+	Updates        []collectionUpdate `json:"atualizacoes"`
+	Next           string             `json:"proximo,omitempty"` // Cursor da próxima página, vazio na última página
+}
+
+type collectionUpdate struct {
+	AgencyID       string    `json:"id_orgao"`
+	Year           int       `json:"ano"`
+	Month          int       `json:"mes"`
+	Timestamp      time.Time `json:"timestamp_coleta"`
+	CrawlerRepo    string    `json:"repositorio_coletor"`
+	CrawlerVersion string    `json:"versao_coletor"`
+	ParserRepo     string    `json:"repositorio_parser,omitempty"` // Apenas quando o parser é diferente do coletor
+	ParserVersion  string    `json:"versao_parser,omitempty"`
+	URL            string    `json:"url"` // Dados do mês na API pública
+}
+
+// Manifesto de um snapshot, com os arquivos e seus hashes SHA-256
+type snapshotManifest struct {
+	Date      string         `json:"data_snapshot"` // AAAA-MM-DD
+	CreatedAt time.Time      `json:"gerado_em"`
+	Files     []snapshotFile `json:"arquivos"`
+}
+
+type snapshotFile struct {
+	Name   string `json:"nome"`
+	Rows   int    `json:"linhas"`
+	Size   int64  `json:"tamanho"` // Em bytes
+	SHA256 string `json:"sha256"`
+}
+
+// Linha da tabela orgaos do snapshot
+type snapshotAgency struct {
+	ID            string `json:"id_orgao" csv:"id_orgao"`
+	Name          string `json:"nome" csv:"nome"`
+	Type          string `json:"jurisdicao" csv:"jurisdicao"`
+	Entity        string `json:"entidade" csv:"entidade"`
+	UF            string `json:"uf" csv:"uf"`
+	TwitterHandle string `json:"twitter_handle" csv:"twitter_handle"`
+	OmbudsmanURL  string `json:"ouvidoria" csv:"ouvidoria"`
+	HasData       bool   `json:"possui_dados" csv:"possui_dados"`
+}
+
+// Linha da tabela coletas do snapshot, com o resumo e os índices de um mês de um órgão
+type snapshotCollection struct {
+	AgencyID           string   `json:"id_orgao" csv:"id_orgao"`
+	Year               int      `json:"ano" csv:"ano"`
+	Month              int      `json:"mes" csv:"mes"`
+	CrawlingTimestamp  string   `json:"timestamp_coleta" csv:"timestamp_coleta"` // RFC 3339
+	Status             int      `json:"status_coleta" csv:"status_coleta"`       // Status do erro da coleta, 0 se não houve erro
+	MemberCount        int      `json:"num_membros" csv:"num_membros"`
+	BaseRemuneration   float64  `json:"remuneracao_base" csv:"remuneracao_base"`
+	OtherRemunerations float64  `json:"outras_remuneracoes" csv:"outras_remuneracoes"`
+	Discounts          float64  `json:"descontos" csv:"descontos"`
+	Remunerations      float64  `json:"remuneracoes" csv:"remuneracoes"`
+	Score              *float64 `json:"indice_transparencia" csv:"indice_transparencia"`
+	CompletenessScore  *float64 `json:"indice_completude" csv:"indice_completude"`
+	EasinessScore      *float64 `json:"indice_facilidade" csv:"indice_facilidade"`
+	PackageURL         string   `json:"pacote_url" csv:"pacote_url"`
+	PackageHash        string   `json:"pacote_hash" csv:"pacote_hash"`
+	PackageSize        int64    `json:"pacote_tamanho" csv:"pacote_tamanho"`
+	CrawlerRepo        string   `json:"repositorio_coletor" csv:"repositorio_coletor"`
+	CrawlerVersion     string   `json:"versao_coletor" csv:"versao_coletor"`
+	ParserRepo         string   `json:"repositorio_parser" csv:"repositorio_parser"`
+	ParserVersion      string   `json:"versao_parser" csv:"versao_parser"`
+	ManualCollection   bool     `json:"coleta_manual" csv:"coleta_manual"`
+	Inconsistent       bool     `json:"inconsistente" csv:"inconsistente"`
+}
+
+// Linha da tabela remuneracoes_zips do snapshot
+type snapshotRemunerationZip struct {
+	AgencyID     string `json:"id_orgao" csv:"id_orgao"`
+	Year         int    `json:"ano" csv:"ano"`
+	Month        int    `json:"mes" csv:"mes"`
+	BaseRows     int    `json:"linhas_base" csv:"linhas_base"`
+	OtherRows    int    `json:"linhas_outras" csv:"linhas_outras"`
+	DiscountRows int    `json:"linhas_descontos" csv:"linhas_descontos"`
+	ZipURL       string `json:"zip_url" csv:"zip_url"`
+}
+
+// Linha da tabela remuneracoes_zips, como lida do banco
+type remunerationZip struct {
+	Orgao     string `db:"orgao"`
+	Ano       int    `db:"ano"`
+	Mes       int    `db:"mes"`
+	Base      int    `db:"base"`
+	Outras    int    `db:"outras"`
+	Descontos int    `db:"descontos"`
+	ZipUrl    string `db:"zip_url"`
+}
@@ -0,0 +1,51 @@
+package papi
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Primeiro ano com dados disponíveis.
+const firstDataYear = 2018
+
+// summaryzedMIWriter escreve os dados mensais à medida que são lidos, como um
+// array JSON ou com um objeto JSON por linha (jsonl).
+type summaryzedMIWriter struct {
+	w       io.Writer
+	enc     *json.Encoder
+	jsonl   bool
+	written int
+}
+
+func newSummaryzedMIWriter(w io.Writer, jsonl bool) *summaryzedMIWriter {
+	return &summaryzedMIWriter{w: w, enc: json.NewEncoder(w), jsonl: jsonl}
+}
+
+func (s *summaryzedMIWriter) write(sumMI summaryzedMI) error {
+	if !s.jsonl {
+		sep := ","
+		if s.written == 0 {
+			sep = "["
+		}
+		if _, err := io.WriteString(s.w, sep); err != nil {
+			return err
+		}
+	}
+	s.written++
+	// O Encoder termina cada objeto com uma quebra de linha, o que separa as
+	// linhas no formato jsonl e é ignorado no array.
+	return s.enc.Encode(sumMI)
+}
+
+// close termina o array JSON. Sem nenhum dado, escreve um array vazio.
+func (s *summaryzedMIWriter) close() error {
+	if s.jsonl {
+		return nil
+	}
+	end := "]\n"
+	if s.written == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(s.w, end)
+	return err
+}
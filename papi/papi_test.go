@@ -1,12 +1,23 @@
 package papi
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/dadosjusbr/api/blobstore"
+	"github.com/dadosjusbr/api/ipca"
+	"github.com/dadosjusbr/proto/coleta"
 	"github.com/dadosjusbr/storage"
 	"github.com/dadosjusbr/storage/models"
 	"github.com/dadosjusbr/storage/repo/database"
@@ -54,7 +65,7 @@ func (g getAgencyById) testGetAgencyByIdWhenAgencyExists(t *testing.T) {
 	ctx.SetParamValues(agencyId)
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler := NewHandler(client, "", "")
+	handler := NewHandler(client, nil, nil, nil, nil, "", "")
 	handler.V2GetAgencyById(ctx)
 
 	expectedHttpCode := 200
@@ -96,7 +107,7 @@ func (g getAgencyById) testGetAgencyByIdWhenAgencyDoesNotExist(t *testing.T) {
 	ctx.SetParamValues(agencyId)
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler := NewHandler(client, "", "")
+	handler := NewHandler(client, nil, nil, nil, nil, "", "")
 	handler.V2GetAgencyById(ctx)
 
 	expectedHttpCode := 404
@@ -168,7 +179,7 @@ func (g getAllAgencies) testGetAllAgenciesWhenAgenciesExists(t *testing.T) {
 	ctx := e.NewContext(request, recoder)
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler := NewHandler(client, "", "")
+	handler := NewHandler(client, nil, nil, nil, nil, "", "")
 	handler.V2GetAllAgencies(ctx)
 
 	expectedHttpCode := 200
@@ -233,7 +244,7 @@ func (g getAllAgencies) testGetAllAgenciesWhenAgenciesDoesNotExist(t *testing.T)
 	ctx := e.NewContext(request, recoder)
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler := NewHandler(client, "", "")
+	handler := NewHandler(client, nil, nil, nil, nil, "", "")
 	handler.V2GetAllAgencies(ctx)
 
 	expectedHttpCode := 200
@@ -242,3 +253,693 @@ func (g getAllAgencies) testGetAllAgenciesWhenAgenciesDoesNotExist(t *testing.T)
 	assert.Equal(t, expectedHttpCode, recoder.Code)
 	assert.Equal(t, expectedJson, strings.Trim(recoder.Body.String(), "\n"))
 }
+
+func TestSummaryzedMICorrect(t *testing.T) {
+	corr, err := ipca.NewCorrection("ipca", "2020-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mi := summaryzedMI{
+		Month: 1,
+		Year:  2020,
+		Summary: &summaries{
+			MemberActive: summary{
+				Count:            10,
+				BaseRemuneration: dataSummary{Max: 200, Min: 100, Average: 150, Total: 1500},
+				ItemSummary:      itemSummary{"outras": 100},
+			},
+		},
+	}
+	withError := summaryzedMI{Month: 1, Year: 2020, Error: &miError{Status: 1}}
+	// O IPCA de 2100 não está na série.
+	future := summaryzedMI{Month: 1, Year: 2100, Summary: &summaries{MemberActive: summary{BaseRemuneration: dataSummary{Total: 1500}}}}
+
+	mi.correct(corr)
+	withError.correct(corr)
+	future.correct(corr)
+
+	// IPCA de janeiro de 2020: 5331.42. IPCA de fevereiro de 2020: 5344.75.
+	factor := 5344.75 / 5331.42
+	assert.Equal(t, corr, mi.Correction)
+	assert.Equal(t, 10, mi.Summary.MemberActive.Count)
+	assert.InDelta(t, 1500*factor, mi.Summary.MemberActive.BaseRemuneration.Total, 0.001)
+	assert.InDelta(t, 100*factor, mi.Summary.MemberActive.BaseRemuneration.Min, 0.001)
+	assert.InDelta(t, 100*factor, mi.Summary.MemberActive.ItemSummary["outras"], 0.001)
+	assert.Nil(t, withError.Correction)
+	assert.False(t, mi.Uncorrected)
+	assert.True(t, future.Uncorrected)
+	assert.Nil(t, future.Correction)
+	assert.Equal(t, 1500.0, future.Summary.MemberActive.BaseRemuneration.Total)
+}
+
+func TestNewSummaryzedMI(t *testing.T) {
+	tests := newSummaryzedMITests{}
+	t.Run("Test newSummaryzedMI when data was collected", tests.testWhenDataWasCollected)
+	t.Run("Test newSummaryzedMI when collection failed", tests.testWhenCollectionFailed)
+	t.Run("Test newSummaryzedMI when data is unavailable", tests.testWhenDataIsUnavailable)
+	t.Run("Test newV1SummaryzedMI keeps the v1 shape", tests.testV1Shape)
+}
+
+type newSummaryzedMITests struct{}
+
+func (n newSummaryzedMITests) testWhenDataWasCollected(t *testing.T) {
+	handler := NewHandler(nil, nil, nil, nil, nil, "https://dadosjusbr.org", "https://repo")
+	mi := models.AgencyMonthlyInfo{
+		AgencyID:     "tjal",
+		Year:         2020,
+		Month:        1,
+		Summary:      &models.Summary{Count: 10, BaseRemuneration: models.DataSummary{Total: 1500}, ItemSummary: models.ItemSummary{"ferias": 100}},
+		Package:      &models.Backup{URL: "https://repo/tjal-2020-1.zip", Hash: "abc"},
+		Score:        &models.Score{Score: 0.5},
+		ParserRepo:   "parser",
+		Inconsistent: true,
+	}
+
+	s, ok := handler.newSummaryzedMI(mi)
+
+	assert.True(t, ok)
+	assert.Equal(t, "tjal", s.AgencyID)
+	assert.Equal(t, "https://dadosjusbr.org/tjal-2020-1.zip", s.Package.URL)
+	assert.Equal(t, 1500.0, s.Summary.MemberActive.BaseRemuneration.Total)
+	assert.Equal(t, itemSummary{"ferias": 100}, s.Summary.MemberActive.ItemSummary)
+	assert.Equal(t, 0.5, s.Score.Score)
+	assert.Equal(t, "parser", s.Collect.ParserRepo)
+	assert.Nil(t, s.Metadata)
+	assert.Nil(t, s.Error)
+	assert.True(t, s.Inconsistent)
+}
+
+func (n newSummaryzedMITests) testWhenCollectionFailed(t *testing.T) {
+	handler := NewHandler(nil, nil, nil, nil, nil, "", "")
+	mi := models.AgencyMonthlyInfo{AgencyID: "tjal", Year: 2020, Month: 1, ProcInfo: &coleta.ProcInfo{Status: 1, Stderr: "erro"}}
+
+	s, ok := handler.newSummaryzedMI(mi)
+
+	assert.True(t, ok)
+	assert.Equal(t, &miError{ErrorMessage: "erro", Status: 1}, s.Error)
+	assert.Nil(t, s.Summary)
+	assert.Nil(t, s.Package)
+}
+
+func (n newSummaryzedMITests) testWhenDataIsUnavailable(t *testing.T) {
+	handler := NewHandler(nil, nil, nil, nil, nil, "", "")
+	mi := models.AgencyMonthlyInfo{AgencyID: "tjal", Year: 2020, Month: 1, ProcInfo: &coleta.ProcInfo{Status: 4, Stderr: "indisponível"}}
+
+	_, ok := handler.newSummaryzedMI(mi)
+
+	assert.False(t, ok)
+}
+
+func (n newSummaryzedMITests) testV1Shape(t *testing.T) {
+	handler := NewHandler(nil, nil, nil, nil, nil, "", "")
+	mi := models.AgencyMonthlyInfo{
+		AgencyID:     "tjal",
+		Year:         2020,
+		Month:        1,
+		Summary:      &models.Summary{Count: 10, ItemSummary: models.ItemSummary{"ferias": 100}},
+		Inconsistent: true,
+	}
+
+	s, ok := handler.newV1SummaryzedMI(mi)
+	b, err := json.Marshal(s)
+
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "resumo_rubricas")
+	assert.Contains(t, string(b), `"inconsistente":false`)
+	assert.Equal(t, 10, s.Summary.MemberActive.Count)
+}
+
+func TestGetAggregates(t *testing.T) {
+	tests := getAggregatesTests{}
+	t.Run("Test GetAggregates by group and month", tests.testByGroupAndMonth)
+	t.Run("Test GetAggregates without dimensions", tests.testWithoutDimensions)
+	t.Run("Test GetAggregates with a date range", tests.testWithADateRange)
+	t.Run("Test GetAggregates when parameters are invalid", tests.testWhenParametersAreInvalid)
+}
+
+type getAggregatesTests struct{}
+
+func (g getAggregatesTests) monthlyInfo(agencyID string, month, count int, base float64) models.AgencyMonthlyInfo {
+	return models.AgencyMonthlyInfo{
+		AgencyID: agencyID,
+		Year:     2020,
+		Month:    month,
+		Summary: &models.Summary{
+			Count:            count,
+			BaseRemuneration: models.DataSummary{Total: base},
+			Remunerations:    models.DataSummary{Total: base},
+			ItemSummary:      models.ItemSummary{"ferias": base / 10},
+		},
+	}
+}
+
+func (g getAggregatesTests) request(t *testing.T, query string) *httptest.ResponseRecorder {
+	mockCtrl := gomock.NewController(t)
+	dbMock := database.NewMockInterface(mockCtrl)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+	agencies := []models.Agency{
+		{ID: "tjal", Type: "Estadual", UF: "AL"},
+		{ID: "tjba", Type: "Estadual", UF: "BA"},
+		{ID: "mpal", Type: "Ministério", UF: "AL"},
+	}
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+	dbMock.EXPECT().GetAllAgencies().Return(agencies, nil).AnyTimes()
+	dbMock.EXPECT().GetMonthlyInfo(gomock.Any(), 2020).DoAndReturn(func(agencies []models.Agency, year int) (map[string][]models.AgencyMonthlyInfo, error) {
+		all := map[string][]models.AgencyMonthlyInfo{
+			"tjal": {g.monthlyInfo("tjal", 1, 10, 1000), g.monthlyInfo("tjal", 2, 10, 2000)},
+			"tjba": {g.monthlyInfo("tjba", 1, 30, 3000), {AgencyID: "tjba", Year: 2020, Month: 2, ProcInfo: &coleta.ProcInfo{Status: 4}}},
+			"mpal": {g.monthlyInfo("mpal", 1, 5, 500)},
+		}
+		result := map[string][]models.AgencyMonthlyInfo{}
+		for _, a := range agencies {
+			result[a.ID] = all[a.ID]
+		}
+		return result, nil
+	}).AnyTimes()
+	client, _ := storage.NewClient(dbMock, fsMock)
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/v2/agregados?"+query, nil), recorder)
+
+	NewHandler(client, nil, nil, nil, nil, "", "").GetAggregates(ctx)
+
+	return recorder
+}
+
+func (g getAggregatesTests) testByGroupAndMonth(t *testing.T) {
+	recorder := g.request(t, "anos=2020&dimensoes=grupo,mes&medidas=totais,quantidades,rubricas&ufs=al,ba")
+
+	expectedJson := `
+		{
+			"dimensoes": ["grupo", "mes"],
+			"medidas": ["totais", "quantidades", "rubricas"],
+			"agregados": [
+				{
+					"grupo": "justica-estadual",
+					"mes": 1,
+					"totais": {"remuneracao_base": 4000, "outras_remuneracoes": 0, "descontos": 0, "remuneracoes": 4000},
+					"quantidades": {"orgaos": 2, "meses_com_dados": 2, "media_mensal_membros": 40},
+					"rubricas": {"ferias": 400}
+				},
+				{
+					"grupo": "justica-estadual",
+					"mes": 2,
+					"totais": {"remuneracao_base": 2000, "outras_remuneracoes": 0, "descontos": 0, "remuneracoes": 2000},
+					"quantidades": {"orgaos": 1, "meses_com_dados": 1, "media_mensal_membros": 10},
+					"rubricas": {"ferias": 200}
+				},
+				{
+					"grupo": "ministerios-publicos",
+					"mes": 1,
+					"totais": {"remuneracao_base": 500, "outras_remuneracoes": 0, "descontos": 0, "remuneracoes": 500},
+					"quantidades": {"orgaos": 1, "meses_com_dados": 1, "media_mensal_membros": 5},
+					"rubricas": {"ferias": 50}
+				}
+			]
+		}
+	`
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, expectedJson, recorder.Body.String())
+}
+
+func (g getAggregatesTests) testWithoutDimensions(t *testing.T) {
+	recorder := g.request(t, "anos=2020&orgaos=tjal,tjba&medidas=medias,quantidades")
+
+	expectedJson := `
+		{
+			"dimensoes": [],
+			"medidas": ["medias", "quantidades"],
+			"agregados": [
+				{
+					"medias": {"remuneracao_base": 120, "outras_remuneracoes": 0, "descontos": 0, "remuneracoes": 120},
+					"quantidades": {"orgaos": 2, "meses_com_dados": 3, "media_mensal_membros": 25}
+				}
+			]
+		}
+	`
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, expectedJson, recorder.Body.String())
+}
+
+func (g getAggregatesTests) testWithADateRange(t *testing.T) {
+	recorder := g.request(t, "de=2020-02&ate=2020-02&dimensoes=orgao&medidas=totais")
+
+	expectedJson := `
+		{
+			"dimensoes": ["orgao"],
+			"medidas": ["totais"],
+			"agregados": [
+				{
+					"id_orgao": "tjal",
+					"totais": {"remuneracao_base": 2000, "outras_remuneracoes": 0, "descontos": 0, "remuneracoes": 2000}
+				}
+			]
+		}
+	`
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, expectedJson, recorder.Body.String())
+}
+
+func (g getAggregatesTests) testWhenParametersAreInvalid(t *testing.T) {
+	for _, query := range []string{"", "anos=2020&dimensoes=cargo", "anos=2020&medidas=mediana", "anos=2020&meses=13", "anos=2020&grupos=justica", "anos=abc", "anos=2017", "anos=9999", "de=2020-01&anos=2020", "de=2020-13"} {
+		recorder := g.request(t, query)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
+
+func TestGetMonthlyInfosOfAgencies(t *testing.T) {
+	tests := getMonthlyInfosOfAgenciesTests{}
+	t.Run("Test GetMonthlyInfosOfAgencies as a JSON array", tests.testAsJSONArray)
+	t.Run("Test GetMonthlyInfosOfAgencies as JSON lines", tests.testAsJSONLines)
+	t.Run("Test GetMonthlyInfosOfAgencies with a date range", tests.testWithADateRange)
+	t.Run("Test GetMonthlyInfosOfAgencies when no agency matches", tests.testWhenNoAgencyMatches)
+	t.Run("Test GetMonthlyInfosOfAgencies when parameters are invalid", tests.testWhenParametersAreInvalid)
+	t.Run("Test GetMonthlyInfosOfAgencies when a query fails after the response started", tests.testWhenAQueryFailsAfterTheResponseStarted)
+}
+
+type getMonthlyInfosOfAgenciesTests struct{}
+
+func (g getMonthlyInfosOfAgenciesTests) request(t *testing.T, query string, expect func(dbMock *database.MockInterface)) *httptest.ResponseRecorder {
+	mockCtrl := gomock.NewController(t)
+	dbMock := database.NewMockInterface(mockCtrl)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+	dbMock.EXPECT().GetAllAgencies().Return([]models.Agency{
+		{ID: "tjba", Type: "Estadual", UF: "BA"},
+		{ID: "tjal", Type: "Estadual", UF: "AL"},
+		{ID: "mpal", Type: "Ministério", UF: "AL"},
+	}, nil).AnyTimes()
+	if expect != nil {
+		expect(dbMock)
+	}
+	client, _ := storage.NewClient(dbMock, fsMock)
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/v2/dados?"+query, nil), recorder)
+
+	NewHandler(client, nil, nil, nil, nil, "", "").GetMonthlyInfosOfAgencies(ctx)
+
+	return recorder
+}
+
+// Espera uma consulta por ano, com os dois tribunais estaduais.
+func (g getMonthlyInfosOfAgenciesTests) expectStateCourts(dbMock *database.MockInterface) {
+	agencies := []models.Agency{{ID: "tjal"}, {ID: "tjba"}}
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2020).Return(map[string][]models.AgencyMonthlyInfo{
+		"tjba": {{AgencyID: "tjba", Year: 2020, Month: 1}},
+		"tjal": {
+			{AgencyID: "tjal", Year: 2020, Month: 2},
+			{AgencyID: "tjal", Year: 2020, Month: 1},
+			{AgencyID: "tjal", Year: 2020, Month: 3, ProcInfo: &coleta.ProcInfo{Status: 4}},
+		},
+	}, nil).Times(1)
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2021).Return(map[string][]models.AgencyMonthlyInfo{
+		"tjal": {{AgencyID: "tjal", Year: 2021, Month: 1, ProcInfo: &coleta.ProcInfo{Status: 1, Stderr: "erro"}}},
+	}, nil).Times(1)
+}
+
+func (g getMonthlyInfosOfAgenciesTests) testAsJSONArray(t *testing.T) {
+	recorder := g.request(t, "grupos=justica-estadual&ano_inicio=2020&ano_fim=2021", g.expectStateCourts)
+
+	expectedJson := `
+		[
+			{"id_orgao": "tjal", "ano": 2020, "mes": 1, "dados_coleta": {}, "coleta_manual": false, "inconsistente": false},
+			{"id_orgao": "tjal", "ano": 2020, "mes": 2, "dados_coleta": {}, "coleta_manual": false, "inconsistente": false},
+			{"id_orgao": "tjba", "ano": 2020, "mes": 1, "dados_coleta": {}, "coleta_manual": false, "inconsistente": false},
+			{"id_orgao": "tjal", "ano": 2021, "mes": 1, "error": {"err_msg": "erro", "status": 1}, "coleta_manual": false, "inconsistente": false}
+		]
+	`
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, echo.MIMEApplicationJSONCharsetUTF8, recorder.Header().Get(echo.HeaderContentType))
+	assert.JSONEq(t, expectedJson, recorder.Body.String())
+}
+
+func (g getMonthlyInfosOfAgenciesTests) testAsJSONLines(t *testing.T) {
+	recorder := g.request(t, "orgaos=tjal,tjba&ano_inicio=2020&ano_fim=2021&formato=jsonl", g.expectStateCourts)
+
+	lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/x-ndjson; charset=utf-8", recorder.Header().Get(echo.HeaderContentType))
+	assert.Len(t, lines, 4)
+	assert.JSONEq(t, `{"id_orgao": "tjba", "ano": 2020, "mes": 1, "dados_coleta": {}, "coleta_manual": false, "inconsistente": false}`, lines[2])
+}
+
+func (g getMonthlyInfosOfAgenciesTests) testWithADateRange(t *testing.T) {
+	recorder := g.request(t, "orgaos=tjal,tjba&de=2020-02&ate=2021-01", g.expectStateCourts)
+
+	expectedJson := `
+		[
+			{"id_orgao": "tjal", "ano": 2020, "mes": 2, "dados_coleta": {}, "coleta_manual": false, "inconsistente": false},
+			{"id_orgao": "tjal", "ano": 2021, "mes": 1, "error": {"err_msg": "erro", "status": 1}, "coleta_manual": false, "inconsistente": false}
+		]
+	`
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, expectedJson, recorder.Body.String())
+}
+
+func (g getMonthlyInfosOfAgenciesTests) testWhenNoAgencyMatches(t *testing.T) {
+	// O nome antigo do parâmetro, uf, continua aceito.
+	for _, query := range []string{"ufs=SP&ano_inicio=2020&ano_fim=2021", "uf=SP&ano_inicio=2020&ano_fim=2021"} {
+		recorder := g.request(t, query, nil)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, query)
+		assert.Equal(t, "[]", strings.TrimSpace(recorder.Body.String()), query)
+	}
+}
+
+func (g getMonthlyInfosOfAgenciesTests) testWhenParametersAreInvalid(t *testing.T) {
+	for _, query := range []string{"ano_inicio=abc", "ano_fim=abc", "ano_inicio=1", "ano_inicio=2017", "ano_fim=9999", "ano_inicio=2021&ano_fim=2020", "formato=csv", "grupos=justica", "grupo=justica", "corrigir=igpm", "de=2020-01&ano_inicio=2020", "de=2021-01&ate=2020-01"} {
+		recorder := g.request(t, query, nil)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
+
+func (g getMonthlyInfosOfAgenciesTests) testWhenAQueryFailsAfterTheResponseStarted(t *testing.T) {
+	agencies := []models.Agency{{ID: "tjal"}}
+	expect := func(dbMock *database.MockInterface) {
+		dbMock.EXPECT().GetMonthlyInfo(agencies, 2020).Return(map[string][]models.AgencyMonthlyInfo{
+			"tjal": {{AgencyID: "tjal", Year: 2020, Month: 1}},
+		}, nil).Times(1)
+		dbMock.EXPECT().GetMonthlyInfo(agencies, 2021).Return(nil, fmt.Errorf("connection reset")).Times(1)
+	}
+
+	// A conexão é interrompida, em vez de a resposta terminar como se estivesse completa.
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		g.request(t, "orgaos=tjal&ano_inicio=2020&ano_fim=2021", expect)
+	})
+}
+
+func TestUpdates(t *testing.T) {
+	tests := updatesTests{}
+	t.Run("Test parseSince", tests.testParseSince)
+	t.Run("Test newCollectionUpdates", tests.testNewCollectionUpdates)
+	t.Run("Test newCollectionUpdates when there are no updates", tests.testWhenThereAreNoUpdates)
+	t.Run("Test newCollectionUpdates when there is a next page", tests.testWhenThereIsANextPage)
+	t.Run("Test decodeUpdatesCursor", tests.testDecodeUpdatesCursor)
+	t.Run("Test GetUpdates when since is invalid", tests.testWhenSinceIsInvalid)
+	t.Run("Test GetUpdates when the cursor is invalid", tests.testWhenCursorIsInvalid)
+}
+
+type updatesTests struct{}
+
+func (u updatesTests) loc(t *testing.T) *time.Location {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func (u updatesTests) testParseSince(t *testing.T) {
+	since, err := parseSince("2023-01-02T15:04:05Z", u.loc(t))
+	assert.NoError(t, err)
+	assert.True(t, since.Equal(time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)))
+
+	since, err = parseSince("2023-01-02", u.loc(t))
+	assert.NoError(t, err)
+	assert.True(t, since.Equal(time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC)))
+
+	_, err = parseSince("02/01/2023", u.loc(t))
+	assert.EqualError(t, err, "parâmetro desde '02/01/2023' é inválido! Use uma data AAAA-MM-DD ou uma marca temporal RFC 3339.")
+}
+
+func (u updatesTests) testNewCollectionUpdates(t *testing.T) {
+	since := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	parserRepo, parserVersion := "https://github.com/dadosjusbr/parser-tjal", "def456"
+	results := []updateDetails{
+		{Orgao: "tjal", Ano: 2022, Mes: 12, Timestamp: time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC), RepositorioColetor: "https://github.com/dadosjusbr/coletor-tjal", VersaoColetor: "abc123"},
+		{Orgao: "tjba", Ano: 2020, Mes: 1, Timestamp: time.Date(2023, 1, 3, 10, 0, 0, 0, time.UTC), RepositorioColetor: "https://github.com/dadosjusbr/coletor-tjba", VersaoColetor: "abc123", RepositorioParser: &parserRepo, VersaoParser: &parserVersion},
+	}
+
+	updates := newCollectionUpdates(since, "api.dadosjusbr.org", results, 2)
+
+	data, err := json.Marshal(updates)
+	assert.NoError(t, err)
+	expectedJson := `
+		{
+			"desde": "2023-01-01T00:00:00Z",
+			"ultima_coleta": "2023-01-03T10:00:00Z",
+			"atualizacoes": [
+				{
+					"id_orgao": "tjal",
+					"ano": 2022,
+					"mes": 12,
+					"timestamp_coleta": "2023-01-02T10:00:00Z",
+					"repositorio_coletor": "https://github.com/dadosjusbr/coletor-tjal",
+					"versao_coletor": "abc123",
+					"url": "api.dadosjusbr.org/v2/dados/tjal/2022/12"
+				},
+				{
+					"id_orgao": "tjba",
+					"ano": 2020,
+					"mes": 1,
+					"timestamp_coleta": "2023-01-03T10:00:00Z",
+					"repositorio_coletor": "https://github.com/dadosjusbr/coletor-tjba",
+					"versao_coletor": "abc123",
+					"repositorio_parser": "https://github.com/dadosjusbr/parser-tjal",
+					"versao_parser": "def456",
+					"url": "api.dadosjusbr.org/v2/dados/tjba/2020/1"
+				}
+			]
+		}
+	`
+	assert.JSONEq(t, expectedJson, string(data))
+}
+
+func (u updatesTests) testWhenThereAreNoUpdates(t *testing.T) {
+	since := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	data, err := json.Marshal(newCollectionUpdates(since, "api.dadosjusbr.org", nil, 2))
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"desde": "2023-01-01T00:00:00Z", "ultima_coleta": null, "atualizacoes": []}`, string(data))
+}
+
+func (u updatesTests) testWhenThereIsANextPage(t *testing.T) {
+	since := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	results := []updateDetails{
+		{Orgao: "tjal", Ano: 2022, Mes: 12, Timestamp: time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{Orgao: "tjba", Ano: 2020, Mes: 1, Timestamp: time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{Orgao: "tjba", Ano: 2020, Mes: 2, Timestamp: time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)},
+	}
+
+	updates := newCollectionUpdates(since, "api.dadosjusbr.org", results, 2)
+
+	assert.Len(t, updates.Updates, 2)
+	assert.True(t, updates.LastCollection.Equal(time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)))
+	cursor, err := decodeUpdatesCursor(updates.Next)
+	assert.NoError(t, err)
+	assert.Equal(t, "tjba", cursor.AgencyID)
+	assert.Equal(t, 2020, cursor.Year)
+	assert.Equal(t, 1, cursor.Month)
+	assert.True(t, cursor.Timestamp.Equal(time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)))
+}
+
+func (u updatesTests) testDecodeUpdatesCursor(t *testing.T) {
+	cursor, err := decodeUpdatesCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, cursor)
+
+	for _, s := range []string{"abc", "e30", "!!!"} {
+		_, err := decodeUpdatesCursor(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func (u updatesTests) testWhenSinceIsInvalid(t *testing.T) {
+	for _, query := range []string{"", "desde=ontem"} {
+		recorder := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/v2/atualizacoes?"+query, nil), recorder)
+
+		handler{loc: u.loc(t)}.GetUpdates(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
+
+func (u updatesTests) testWhenCursorIsInvalid(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/v2/atualizacoes?desde=2023-01-01&proximo=abc", nil), recorder)
+
+	handler{loc: u.loc(t)}.GetUpdates(ctx)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, `"parâmetro proximo 'abc' é inválido!"`, strings.TrimSpace(recorder.Body.String()))
+}
+
+func TestSnapshot(t *testing.T) {
+	tests := snapshotTests{}
+	t.Run("Test writeSnapshot", tests.testWriteSnapshot)
+	t.Run("Test writeSnapshot is deterministic", tests.testWriteSnapshotIsDeterministic)
+	t.Run("Test GetSnapshot when snapshot is prebuilt", tests.testWhenSnapshotIsPrebuilt)
+	t.Run("Test GetSnapshot when snapshot does not exist", tests.testWhenSnapshotDoesNotExist)
+	t.Run("Test GetSnapshot when date is invalid", tests.testWhenDateIsInvalid)
+	t.Run("Test GetSnapshot when snapshot of today is not ready", tests.testWhenSnapshotOfTodayIsNotReady)
+	t.Run("Test buildSnapshot when snapshot exists", tests.testBuildSnapshotWhenSnapshotExists)
+}
+
+type snapshotTests struct{}
+
+func (s snapshotTests) write(t *testing.T) []byte {
+	score := &models.Score{Score: 0.5, CompletenessScore: 0.4, EasinessScore: 0.6}
+	agencies := []models.Agency{
+		{ID: "tjba", Name: "Tribunal de Justiça do Estado da Bahia", UF: "BA"},
+		{ID: "tjal", Name: "Tribunal de Justiça do Estado de Alagoas", UF: "AL", Collecting: []models.Collecting{{Collecting: true}}},
+	}
+	infos := []models.AgencyMonthlyInfo{
+		{AgencyID: "tjal", Year: 2020, Month: 2, Summary: &models.Summary{Count: 10, Remunerations: models.DataSummary{Total: 1000}}, Score: score, Package: &models.Backup{URL: "https://dadosjusbr.org/tjal-2020-2.zip", Hash: "abc", Size: 10}},
+		{AgencyID: "tjal", Year: 2020, Month: 1, ProcInfo: &coleta.ProcInfo{Status: 4}},
+	}
+	zips := []remunerationZip{{Orgao: "tjal", Ano: 2020, Mes: 2, Base: 10, Outras: 20, Descontos: 5, ZipUrl: "https://dadosjusbr.org/tjal-2020-2-remuneracoes.zip"}}
+	content, err := writeSnapshot("2023-01-02", time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC), agencies, infos, zips)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+// Lê os arquivos do zip do snapshot.
+func (s snapshotTests) files(t *testing.T, content []byte) map[string][]byte {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = io.ReadAll(r)
+		r.Close()
+	}
+	return files
+}
+
+func (s snapshotTests) testWriteSnapshot(t *testing.T) {
+	files := s.files(t, s.write(t))
+
+	assert.Len(t, files, 7)
+	var manifest snapshotManifest
+	assert.NoError(t, json.Unmarshal(files["manifesto.json"], &manifest))
+	assert.Equal(t, "2023-01-02", manifest.Date)
+	assert.Len(t, manifest.Files, 6)
+	for _, f := range manifest.Files {
+		hash := sha256.Sum256(files[f.Name])
+		assert.Equal(t, hex.EncodeToString(hash[:]), f.SHA256, f.Name)
+		assert.Equal(t, int64(len(files[f.Name])), f.Size, f.Name)
+	}
+	assert.Equal(t, snapshotFile{Name: "coletas.csv", Rows: 2, Size: manifest.Files[3].Size, SHA256: manifest.Files[3].SHA256}, manifest.Files[3])
+
+	var agencies []snapshotAgency
+	assert.NoError(t, json.Unmarshal(files["orgaos.json"], &agencies))
+	assert.Equal(t, []string{"tjal", "tjba"}, []string{agencies[0].ID, agencies[1].ID})
+	assert.True(t, agencies[0].HasData)
+	lines := strings.Split(strings.TrimSpace(string(files["coletas.csv"])), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "id_orgao,ano,mes,timestamp_coleta,status_coleta,num_membros,"))
+	assert.True(t, strings.HasPrefix(lines[1], "tjal,2020,1,,4,0,"))
+	assert.Contains(t, lines[2], ",0.5,0.4,0.6,https://dadosjusbr.org/tjal-2020-2.zip,abc,10,")
+	assert.Equal(t, "id_orgao,ano,mes,linhas_base,linhas_outras,linhas_descontos,zip_url\ntjal,2020,2,10,20,5,https://dadosjusbr.org/tjal-2020-2-remuneracoes.zip\n", string(files["remuneracoes_zips.csv"]))
+}
+
+func (s snapshotTests) testWriteSnapshotIsDeterministic(t *testing.T) {
+	assert.Equal(t, s.write(t), s.write(t))
+}
+
+func (s snapshotTests) request(t *testing.T, store blobstore.Store, query string) *httptest.ResponseRecorder {
+	handler := NewHandler(nil, nil, nil, store, time.UTC, "", "")
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/v2/snapshot?"+query, nil), recorder)
+
+	handler.GetSnapshot(ctx)
+
+	return recorder
+}
+
+func (s snapshotTests) testWhenSnapshotIsPrebuilt(t *testing.T) {
+	store := memoryStore{}
+	content := s.write(t)
+	assert.NoError(t, store.Put(context.Background(), "snapshots/dadosjusbr-snapshot-2023-01-02.zip", bytes.NewReader(content)))
+
+	recorder := s.request(t, store, "data=2023-01-02")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/zip", recorder.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "2023-01-02", recorder.Header().Get("X-Snapshot-Data"))
+	assert.Equal(t, content, recorder.Body.Bytes())
+}
+
+func (s snapshotTests) testWhenSnapshotDoesNotExist(t *testing.T) {
+	recorder := s.request(t, memoryStore{}, "data=2023-01-03")
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "\"snapshot de 2023-01-03 não encontrado\"\n", recorder.Body.String())
+}
+
+func (s snapshotTests) testWhenDateIsInvalid(t *testing.T) {
+	recorder := s.request(t, nil, "data=2023-13-01")
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func (s snapshotTests) testWhenSnapshotOfTodayIsNotReady(t *testing.T) {
+	recorder := s.request(t, memoryStore{}, "")
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "600", recorder.Header().Get("Retry-After"))
+}
+
+func (s snapshotTests) testBuildSnapshotWhenSnapshotExists(t *testing.T) {
+	store := memoryStore{}
+	content := s.write(t)
+	assert.NoError(t, store.Put(context.Background(), "snapshots/dadosjusbr-snapshot-2023-01-02.zip", bytes.NewReader(content)))
+	// Sem cliente do banco, o handler falharia se tentasse gerar o snapshot.
+	handler := NewHandler(nil, nil, nil, store, time.UTC, "", "")
+
+	assert.NoError(t, handler.buildSnapshot(context.Background(), "2023-01-02"))
+}
+
+// memoryStore é um blobstore.Store em memória, usado nos testes dos snapshots.
+type memoryStore map[string][]byte
+
+func (m memoryStore) Put(_ context.Context, key string, r io.Reader) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m[key] = content
+	return nil
+}
+
+func (m memoryStore) Create(_ context.Context, key string, content []byte) error {
+	if _, ok := m[key]; ok {
+		return blobstore.ErrExists
+	}
+	m[key] = content
+	return nil
+}
+
+func (m memoryStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	content, ok := m[key]
+	if !ok {
+		return nil, blobstore.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (m memoryStore) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for k := range m {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (m memoryStore) Delete(_ context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
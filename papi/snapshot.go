@@ -0,0 +1,365 @@
+package papi
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/dadosjusbr/api/blobstore"
+	"github.com/dadosjusbr/api/daterange"
+	"github.com/dadosjusbr/storage/models"
+	"github.com/gocarina/gocsv"
+	"github.com/labstack/echo/v4"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// Prefixo das chaves dos snapshots no armazenamento.
+const snapshotsPrefix = "snapshots/"
+
+// Formato da data dos snapshots.
+const snapshotDateLayout = "2006-01-02"
+
+// Nome do arquivo do manifesto dentro do snapshot.
+const snapshotManifestName = "manifesto.json"
+
+func snapshotKey(date string) string {
+	return snapshotsPrefix + "dadosjusbr-snapshot-" + date + ".zip"
+}
+
+// Intervalo entre as verificações do snapshot do dia.
+const snapshotInterval = time.Hour
+
+// Tempo sugerido, em segundos, para tentar baixar de novo o snapshot do dia
+// enquanto ele é gerado.
+const snapshotRetryAfter = "600"
+
+//	@ID				GetSnapshot
+//	@Tags			public_api
+//	@Description	Baixa um snapshot de todos os dados do DadosJusBr: um arquivo zip com as tabelas orgaos, coletas (resumos mensais e índices de transparência) e remuneracoes_zips (links para os arquivos de remunerações), em JSON e CSV, e um manifesto com a data do snapshot e o hash SHA-256 de cada arquivo. Um snapshot é gerado por dia, em segundo plano, e guardado, para que uma versão exata dos dados possa ser citada e baixada novamente. Enquanto o snapshot do dia não fica pronto, a rota responde 503.
+//	@Description
+//	@Description	A tabela coletas traz os mesmos meses das rotas de dados mensais: os meses em que a coleta falhou não fazem parte dela.
+//	@Produce		application/zip
+//	@Param			data	query		string	false	"Data do snapshot, no formato AAAA-MM-DD. Padrão: data de hoje."
+//	@Success		200		{file}		file	"Arquivo zip do snapshot"
+//	@Failure		400		{string}	string	"Parâmetro data inválido"
+//	@Failure		404		{string}	string	"Snapshot não encontrado"
+//	@Failure		500		{string}	string	"Erro interno do servidor"
+//	@Failure		503		{string}	string	"Snapshot do dia ainda não gerado"
+//	@Router			/v2/snapshot [get]
+func (h handler) GetSnapshot(c echo.Context) error {
+	today := time.Now().In(h.loc).Format(snapshotDateLayout)
+	date := today
+	if d := c.QueryParam("data"); d != "" {
+		if _, err := time.Parse(snapshotDateLayout, d); err != nil {
+			return c.JSON(http.StatusBadRequest, fmt.Sprintf("parâmetro data '%s' é inválido! Use o formato AAAA-MM-DD.", d))
+		}
+		date = d
+	}
+	if h.snapshots == nil {
+		return c.JSON(http.StatusServiceUnavailable, "os snapshots não estão disponíveis")
+	}
+	r, err := h.snapshots.Get(c.Request().Context(), snapshotKey(date))
+	if errors.Is(err, blobstore.ErrNotFound) {
+		if date == today {
+			c.Response().Header().Set("Retry-After", snapshotRetryAfter)
+			return c.JSON(http.StatusServiceUnavailable, fmt.Sprintf("o snapshot de %s ainda está sendo gerado, tente novamente mais tarde", date))
+		}
+		return c.JSON(http.StatusNotFound, fmt.Sprintf("snapshot de %s não encontrado", date))
+	}
+	if err != nil {
+		log.Printf("[snapshot] error getting snapshot of %s: %q", date, err)
+		return c.JSON(http.StatusInternalServerError, "erro ao buscar o snapshot")
+	}
+	defer r.Close()
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=dadosjusbr-snapshot-%s.zip", date))
+	c.Response().Header().Set("X-Snapshot-Data", date)
+	return c.Stream(http.StatusOK, "application/zip", r)
+}
+
+// StartSnapshots gera em segundo plano o snapshot do dia, se ele ainda não
+// existir, e verifica a cada snapshotInterval se o snapshot de um novo dia
+// precisa ser gerado. As requisições nunca geram snapshots.
+func (h handler) StartSnapshots(ctx context.Context) {
+	if h.snapshots == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(snapshotInterval)
+		defer ticker.Stop()
+		for {
+			date := time.Now().In(h.loc).Format(snapshotDateLayout)
+			if err := h.buildSnapshot(ctx, date); err != nil {
+				log.Printf("[snapshot] error building snapshot of %s: %q", date, err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// buildSnapshot gera e guarda o snapshot da data, se ele ainda não existir.
+// Com várias instâncias da API, a escrita condicional mantém o primeiro
+// snapshot guardado, para que todos baixem o mesmo arquivo.
+func (h handler) buildSnapshot(ctx context.Context, date string) error {
+	r, err := h.snapshots.Get(ctx, snapshotKey(date))
+	if err == nil {
+		return r.Close()
+	}
+	if !errors.Is(err, blobstore.ErrNotFound) {
+		return fmt.Errorf("error getting snapshot: %w", err)
+	}
+	content, err := h.newSnapshot(date)
+	if err != nil {
+		return fmt.Errorf("error creating snapshot: %w", err)
+	}
+	err = h.snapshots.Create(ctx, snapshotKey(date), content)
+	if err != nil && !errors.Is(err, blobstore.ErrExists) {
+		return fmt.Errorf("error saving snapshot: %w", err)
+	}
+	return nil
+}
+
+// newSnapshot busca todos os órgãos, os dados mensais de todos os anos e os
+// arquivos de remunerações e gera o arquivo zip do snapshot. Os dados mensais
+// vêm de GetMonthlyInfo, o mesmo das rotas de dados mensais, que não retorna
+// os meses em que a coleta falhou.
+func (h handler) newSnapshot(date string) ([]byte, error) {
+	agencies, err := h.client.Db.GetAllAgencies()
+	if err != nil {
+		return nil, fmt.Errorf("error getting agencies: %w", err)
+	}
+	_, first, err := h.client.Db.GetFirstDateWithMonthlyInfo()
+	if err != nil {
+		return nil, fmt.Errorf("error getting first date with monthly info: %w", err)
+	}
+	_, last, err := h.client.Db.GetLastDateWithMonthlyInfo()
+	if err != nil {
+		return nil, fmt.Errorf("error getting last date with monthly info: %w", err)
+	}
+	var monthlyInfos []models.AgencyMonthlyInfo
+	for y := first; y <= last && len(agencies) > 0; y++ {
+		infos, err := h.client.Db.GetMonthlyInfo(agencies, y)
+		if err != nil {
+			return nil, fmt.Errorf("error getting monthly info (ano:%d): %w", y, err)
+		}
+		for _, mis := range infos {
+			monthlyInfos = append(monthlyInfos, mis...)
+		}
+	}
+	zips, err := h.remunerationZips()
+	if err != nil {
+		return nil, fmt.Errorf("error getting remuneration zips: %w", err)
+	}
+	return writeSnapshot(date, time.Now().In(h.loc), agencies, monthlyInfos, zips)
+}
+
+// remunerationZips lista os arquivos de remunerações de todos os órgãos e meses.
+func (h handler) remunerationZips() ([]remunerationZip, error) {
+	results := []remunerationZip{}
+	txn := h.newrelic.StartTransaction("pg.RemunerationZips")
+	defer txn.End()
+	ctx := newrelic.NewContext(context.Background(), txn)
+	err := h.conn.WithContext(ctx).Raw(`SELECT
+			id_orgao as orgao,
+			ano as ano,
+			mes as mes,
+			linhas_base as base,
+			linhas_outras as outras,
+			linhas_descontos as descontos,
+			zip_url as zip_url
+		FROM remuneracoes_zips`).Scan(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar os arquivos de remunerações: %v", err)
+	}
+	return results, nil
+}
+
+// writeSnapshot gera o arquivo zip do snapshot com as tabelas, em JSON e CSV,
+// e o manifesto. As linhas são ordenadas e os arquivos do zip usam a data do
+// snapshot, para que os mesmos dados gerem os mesmos hashes.
+func writeSnapshot(date string, createdAt time.Time, agencies []models.Agency, monthlyInfos []models.AgencyMonthlyInfo, zips []remunerationZip) ([]byte, error) {
+	modified, err := time.Parse(snapshotDateLayout, date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot date (%s): %w", date, err)
+	}
+
+	agencyRows := []snapshotAgency{}
+	for _, a := range agencies {
+		row := snapshotAgency{
+			ID:            a.ID,
+			Name:          a.Name,
+			Type:          a.Type,
+			Entity:        a.Entity,
+			UF:            a.UF,
+			TwitterHandle: a.TwitterHandle,
+			OmbudsmanURL:  a.OmbudsmanURL,
+		}
+		for _, c := range a.Collecting {
+			row.HasData = c.Collecting
+		}
+		agencyRows = append(agencyRows, row)
+	}
+	sort.Slice(agencyRows, func(i, j int) bool { return agencyRows[i].ID < agencyRows[j].ID })
+
+	collectionRows := []snapshotCollection{}
+	for _, mi := range monthlyInfos {
+		collectionRows = append(collectionRows, newSnapshotCollection(mi))
+	}
+	sort.Slice(collectionRows, func(i, j int) bool {
+		a, b := collectionRows[i], collectionRows[j]
+		if a.AgencyID != b.AgencyID {
+			return a.AgencyID < b.AgencyID
+		}
+		return daterange.Number(a.Year, a.Month) < daterange.Number(b.Year, b.Month)
+	})
+
+	zipRows := []snapshotRemunerationZip{}
+	for _, z := range zips {
+		zipRows = append(zipRows, snapshotRemunerationZip{
+			AgencyID:     z.Orgao,
+			Year:         z.Ano,
+			Month:        z.Mes,
+			BaseRows:     z.Base,
+			OtherRows:    z.Outras,
+			DiscountRows: z.Descontos,
+			ZipURL:       z.ZipUrl,
+		})
+	}
+	sort.Slice(zipRows, func(i, j int) bool {
+		a, b := zipRows[i], zipRows[j]
+		if a.AgencyID != b.AgencyID {
+			return a.AgencyID < b.AgencyID
+		}
+		return daterange.Number(a.Year, a.Month) < daterange.Number(b.Year, b.Month)
+	})
+
+	var buf bytes.Buffer
+	w := snapshotWriter{zw: zip.NewWriter(&buf), modified: modified}
+	manifest := &snapshotManifest{Date: date, CreatedAt: createdAt, Files: []snapshotFile{}}
+	for _, table := range []struct {
+		name string
+		rows interface{}
+		n    int
+	}{
+		{"orgaos", agencyRows, len(agencyRows)},
+		{"coletas", collectionRows, len(collectionRows)},
+		{"remuneracoes_zips", zipRows, len(zipRows)},
+	} {
+		files, err := w.writeTable(table.name, table.rows, table.n)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, files...)
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error encoding manifest: %w", err)
+	}
+	if err := w.write(snapshotManifestName, content); err != nil {
+		return nil, err
+	}
+	if err := w.zw.Close(); err != nil {
+		return nil, fmt.Errorf("error closing snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func newSnapshotCollection(mi models.AgencyMonthlyInfo) snapshotCollection {
+	row := snapshotCollection{
+		AgencyID:         mi.AgencyID,
+		Year:             mi.Year,
+		Month:            mi.Month,
+		CrawlerRepo:      mi.CrawlerRepo,
+		CrawlerVersion:   mi.CrawlerVersion,
+		ParserRepo:       mi.ParserRepo,
+		ParserVersion:    mi.ParserVersion,
+		ManualCollection: mi.ManualCollection,
+		Inconsistent:     mi.Inconsistent,
+	}
+	if mi.CrawlingTimestamp != nil {
+		row.CrawlingTimestamp = mi.CrawlingTimestamp.AsTime().UTC().Format(time.RFC3339)
+	}
+	if mi.ProcInfo != nil {
+		row.Status = int(mi.ProcInfo.Status)
+	}
+	if mi.Summary != nil {
+		row.MemberCount = mi.Summary.Count
+		row.BaseRemuneration = mi.Summary.BaseRemuneration.Total
+		row.OtherRemunerations = mi.Summary.OtherRemunerations.Total
+		row.Discounts = mi.Summary.Discounts.Total
+		row.Remunerations = mi.Summary.Remunerations.Total
+	}
+	if mi.Score != nil {
+		row.Score = &mi.Score.Score
+		row.CompletenessScore = &mi.Score.CompletenessScore
+		row.EasinessScore = &mi.Score.EasinessScore
+	}
+	if mi.Package != nil {
+		row.PackageURL = mi.Package.URL
+		row.PackageHash = mi.Package.Hash
+		row.PackageSize = mi.Package.Size
+	}
+	return row
+}
+
+// snapshotWriter escreve os arquivos do snapshot no zip.
+type snapshotWriter struct {
+	zw       *zip.Writer
+	modified time.Time
+}
+
+// writeTable escreve a tabela em JSON e em CSV e retorna os arquivos para o
+// manifesto.
+func (s snapshotWriter) writeTable(name string, rows interface{}, n int) ([]snapshotFile, error) {
+	jsonContent, err := json.Marshal(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding %s as json: %w", name, err)
+	}
+	csvContent, err := gocsv.MarshalBytes(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding %s as csv: %w", name, err)
+	}
+	var files []snapshotFile
+	for _, f := range []struct {
+		name    string
+		content []byte
+	}{
+		{name + ".json", jsonContent},
+		{name + ".csv", csvContent},
+	} {
+		if err := s.write(f.name, f.content); err != nil {
+			return nil, err
+		}
+		hash := sha256.Sum256(f.content)
+		files = append(files, snapshotFile{
+			Name:   f.name,
+			Rows:   n,
+			Size:   int64(len(f.content)),
+			SHA256: hex.EncodeToString(hash[:]),
+		})
+	}
+	return files, nil
+}
+
+func (s snapshotWriter) write(name string, content []byte) error {
+	w, err := s.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: s.modified})
+	if err != nil {
+		return fmt.Errorf("error creating %s in snapshot: %w", name, err)
+	}
+	if _, err := w.Write(content); err != nil {
+		return fmt.Errorf("error writing %s in snapshot: %w", name, err)
+	}
+	return nil
+}
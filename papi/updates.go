@@ -0,0 +1,132 @@
+package papi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// Quantidade máxima de atualizações retornadas em uma página.
+const updatesPageSize = 1000
+
+// updatesQuery lista as coletas vigentes a partir de uma posição, na ordem do
+// cursor. O predicado de posição (%s) depende de a página ser a primeira ou
+// não (ver updatesCursor).
+const updatesQuery = `SELECT
+		id_orgao as orgao,
+		ano as ano,
+		mes as mes,
+		timestamp as timestamp,
+		repositorio_coletor as repositorio_coletor,
+		versao_coletor as versao_coletor,
+		repositorio_parser as repositorio_parser,
+		versao_parser as versao_parser
+	FROM coletas
+	WHERE atual = true AND %s
+	ORDER BY timestamp, id_orgao, ano, mes
+	LIMIT ?`
+
+// updatesCursor é a última coleta de uma página de atualizações. A página
+// seguinte começa logo depois dela. Para os clientes, ele é um texto opaco.
+type updatesCursor struct {
+	Timestamp time.Time `json:"t"`
+	AgencyID  string    `json:"o"`
+	Year      int       `json:"a"`
+	Month     int       `json:"m"`
+}
+
+func (c updatesCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeUpdatesCursor lê o cursor recebido no parâmetro "proximo". Um texto
+// vazio corresponde à primeira página e retorna nil.
+func decodeUpdatesCursor(s string) (*updatesCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var c updatesCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("parâmetro proximo '%s' é inválido!", s)
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.Timestamp.IsZero() || c.AgencyID == "" {
+		return nil, fmt.Errorf("parâmetro proximo '%s' é inválido!", s)
+	}
+	return &c, nil
+}
+
+// parseSince lê o parâmetro desde, que pode ser uma marca temporal no formato
+// RFC 3339 ou uma data AAAA-MM-DD, considerada à meia-noite no fuso loc.
+func parseSince(since string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", since, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("parâmetro desde '%s' é inválido! Use uma data AAAA-MM-DD ou uma marca temporal RFC 3339.", since)
+}
+
+// updates busca até limit coletas vigentes feitas depois de since ou, quando
+// há cursor, depois da última coleta da página anterior.
+func (h handler) updates(since time.Time, cursor *updatesCursor, limit int) ([]updateDetails, error) {
+	results := []updateDetails{}
+	txn := h.newrelic.StartTransaction("pg.Updates")
+	defer txn.End()
+	ctx := newrelic.NewContext(context.Background(), txn)
+	// O timestamp da tabela coletas é guardado em UTC, sem fuso.
+	q, args := fmt.Sprintf(updatesQuery, "timestamp > ?"), []interface{}{since.UTC(), limit}
+	if cursor != nil {
+		q = fmt.Sprintf(updatesQuery, "(timestamp, id_orgao, ano, mes) > (?, ?, ?, ?)")
+		args = []interface{}{cursor.Timestamp.UTC(), cursor.AgencyID, cursor.Year, cursor.Month, limit}
+	}
+	if err := h.conn.WithContext(ctx).Raw(q, args...).Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar as atualizações: %v", err)
+	}
+	return results, nil
+}
+
+// newCollectionUpdates converte as linhas da query de atualizações na resposta
+// da API. Os links apontam para os dados de cada mês na API pública, em host.
+// Quando há mais de pageSize linhas, a página é cortada e o campo proximo
+// recebe o cursor da última atualização retornada.
+func newCollectionUpdates(since time.Time, host string, results []updateDetails, pageSize int) collectionUpdates {
+	updates := collectionUpdates{Since: since, Updates: []collectionUpdate{}}
+	hasNext := len(results) > pageSize
+	if hasNext {
+		results = results[:pageSize]
+	}
+	for _, r := range results {
+		u := collectionUpdate{
+			AgencyID:       r.Orgao,
+			Year:           r.Ano,
+			Month:          r.Mes,
+			Timestamp:      r.Timestamp.UTC(),
+			CrawlerRepo:    r.RepositorioColetor,
+			CrawlerVersion: r.VersaoColetor,
+			URL:            fmt.Sprintf("%s/v2/dados/%s/%d/%d", host, r.Orgao, r.Ano, r.Mes),
+		}
+		if r.RepositorioParser != nil {
+			u.ParserRepo = *r.RepositorioParser
+		}
+		if r.VersaoParser != nil {
+			u.ParserVersion = *r.VersaoParser
+		}
+		updates.Updates = append(updates.Updates, u)
+		if updates.LastCollection == nil || u.Timestamp.After(*updates.LastCollection) {
+			last := u.Timestamp
+			updates.LastCollection = &last
+		}
+	}
+	if hasNext {
+		last := updates.Updates[len(updates.Updates)-1]
+		updates.Next = updatesCursor{Timestamp: last.Timestamp, AgencyID: last.AgencyID, Year: last.Year, Month: last.Month}.encode()
+	}
+	return updates
+}
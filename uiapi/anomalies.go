@@ -0,0 +1,136 @@
+package uiapi
+
+import (
+	"math"
+	"sort"
+
+	strModels "github.com/dadosjusbr/storage/models"
+)
+
+// Parâmetros da detecção de anomalias. Cada mês é comparado com a mediana dos
+// meses anteriores com dados do mesmo órgão (mediana móvel).
+const (
+	// Quantidade máxima de meses anteriores usados na mediana móvel.
+	anomalyWindow = 6
+	// Quantidade mínima de meses anteriores para que o mês seja avaliado.
+	anomalyMinHistory = 3
+	// Desvio relativo a partir do qual o total de remunerações é anômalo.
+	remunerationsDeviation = 0.5
+	// Desvio relativo a partir do qual a quantidade de membros é anômala.
+	memberCountDeviation = 0.3
+	// Quantas vezes a mediana móvel uma rubrica precisa atingir para ser um pico.
+	itemSpikeFactor = 3
+	// Parcela das remunerações (salário + benefícios) que uma rubrica que não
+	// aparecia nos meses anteriores precisa atingir para ser um pico.
+	newItemShare = 0.1
+)
+
+// Tipos de anomalia.
+const (
+	anomalyRemunerations = "remuneracoes"
+	anomalyMemberCount   = "num_membros"
+	anomalyItem          = "rubrica"
+)
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return percentile(sorted, 50)
+}
+
+// Retorna a anomalia se o valor se afastar da mediana mais do que o desvio
+// relativo permitido.
+func deviationAnomaly(kind string, value, med, maxDeviation float64) *anomaly {
+	if med <= 0 {
+		return nil
+	}
+	deviation := (value - med) / med
+	if math.Abs(deviation) <= maxDeviation {
+		return nil
+	}
+	return &anomaly{Type: kind, Value: value, Median: med, Deviation: &deviation}
+}
+
+// detectAnomalies procura anomalias nos meses de um órgão, comparando cada mês
+// com a mediana móvel dos meses anteriores com dados. Os meses sem dados de
+// remuneração são ignorados. Os sinais são:
+//   - o total de remunerações se afasta mais de 50% da mediana;
+//   - a quantidade de membros se afasta mais de 30% da mediana;
+//   - uma rubrica atinge três vezes a sua mediana ou, se não aparecia nos meses
+//     anteriores, passa a representar 10% das remunerações do mês.
+func detectAnomalies(infos []strModels.AgencyMonthlyInfo) map[yearMonth][]anomaly {
+	var months []strModels.AgencyMonthlyInfo
+	for _, mi := range infos {
+		if mi.Summary != nil && mi.Summary.BaseRemuneration.Total+mi.Summary.OtherRemunerations.Total > 0 {
+			months = append(months, mi)
+		}
+	}
+	sort.Slice(months, func(i, j int) bool {
+		if months[i].Year != months[j].Year {
+			return months[i].Year < months[j].Year
+		}
+		return months[i].Month < months[j].Month
+	})
+
+	anomalies := map[yearMonth][]anomaly{}
+	for i, mi := range months {
+		window := months[max(0, i-anomalyWindow):i]
+		if len(window) < anomalyMinHistory {
+			continue
+		}
+		var remunerations, counts []float64
+		for _, w := range window {
+			remunerations = append(remunerations, w.Summary.Remunerations.Total)
+			counts = append(counts, float64(w.Summary.Count))
+		}
+		var found []anomaly
+		if a := deviationAnomaly(anomalyRemunerations, mi.Summary.Remunerations.Total, median(remunerations), remunerationsDeviation); a != nil {
+			found = append(found, *a)
+		}
+		if a := deviationAnomaly(anomalyMemberCount, float64(mi.Summary.Count), median(counts), memberCountDeviation); a != nil {
+			found = append(found, *a)
+		}
+
+		var items []string
+		for item := range mi.Summary.ItemSummary {
+			items = append(items, item)
+		}
+		sort.Strings(items)
+		for _, item := range items {
+			value := mi.Summary.ItemSummary[item]
+			if value <= 0 {
+				continue
+			}
+			var history []float64
+			for _, w := range window {
+				history = append(history, w.Summary.ItemSummary[item])
+			}
+			med := median(history)
+			switch {
+			case med > 0 && value >= itemSpikeFactor*med:
+				deviation := (value - med) / med
+				found = append(found, anomaly{Type: anomalyItem, Item: item, Value: value, Median: med, Deviation: &deviation})
+			case allZero(history) && value >= newItemShare*(mi.Summary.BaseRemuneration.Total+mi.Summary.OtherRemunerations.Total):
+				found = append(found, anomaly{Type: anomalyItem, Item: item, Value: value})
+			}
+		}
+		if len(found) > 0 {
+			for j := range found {
+				found[j].AgencyID = mi.AgencyID
+				found[j].Year = mi.Year
+				found[j].Month = mi.Month
+			}
+			anomalies[yearMonth{Year: mi.Year, Month: mi.Month}] = found
+		}
+	}
+	return anomalies
+}
+
+func allZero(values []float64) bool {
+	for _, v := range values {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package uiapi
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/dadosjusbr/api/ipca"
+	strModels "github.com/dadosjusbr/storage/models"
+)
+
+// newAgencyComparison alinha as séries mensais dos órgãos: todas as séries têm
+// um item para cada mês em que ao menos um dos órgãos tem dados, com nil nos
+// meses em que o órgão não tem. Os órgãos são classificados em cada mês e no
+// período pela remuneração líquida por membro, da maior para a menor. Os
+// órgãos mantêm a ordem em que foram informados. Apenas os meses que atendem
+// aos filtros de meses e de intervalo de params são comparados.
+func newAgencyComparison(agencies []string, monthlyInfos map[string][]strModels.AgencyMonthlyInfo, params *searchParams, corr *ipca.Correction) agencyComparison {
+	type monthKey struct{ year, month int }
+	byAgency := map[string]map[monthKey]*comparisonTotals{}
+	keys := map[monthKey]bool{}
+	for _, aID := range agencies {
+		byAgency[aID] = map[monthKey]*comparisonTotals{}
+		for _, mi := range monthlyInfos[aID] {
+			if mi.Summary == nil || mi.Summary.BaseRemuneration.Total+mi.Summary.OtherRemunerations.Total <= 0 {
+				continue
+			}
+			if !params.matchMonth(mi.Year, mi.Month) {
+				continue
+			}
+			f := corr.Month(mi.Year, mi.Month)
+			k := monthKey{mi.Year, mi.Month}
+			keys[k] = true
+			byAgency[aID][k] = &comparisonTotals{
+				MemberCount:                 mi.Summary.Count,
+				BaseRemuneration:            mi.Summary.BaseRemuneration.Total * f,
+				BaseRemunerationPerCapita:   mi.Summary.BaseRemuneration.Average * f,
+				OtherRemunerations:          mi.Summary.OtherRemunerations.Total * f,
+				OtherRemunerationsPerCapita: mi.Summary.OtherRemunerations.Average * f,
+				Discounts:                   mi.Summary.Discounts.Total * f,
+				DiscountsPerCapita:          mi.Summary.Discounts.Average * f,
+				Remunerations:               mi.Summary.Remunerations.Total * f,
+				RemunerationsPerCapita:      mi.Summary.Remunerations.Average * f,
+				Uncorrected:                 corr.Uncorrected(mi.Year, mi.Month),
+			}
+		}
+	}
+	var sortedKeys []monthKey
+	for k := range keys {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Slice(sortedKeys, func(i, j int) bool {
+		if sortedKeys[i].year != sortedKeys[j].year {
+			return sortedKeys[i].year < sortedKeys[j].year
+		}
+		return sortedKeys[i].month < sortedKeys[j].month
+	})
+
+	comparison := agencyComparison{Months: []comparisonMonth{}, Agencies: []agencyComparisonSeries{}, Correction: corr}
+	for _, k := range sortedKeys {
+		comparison.Months = append(comparison.Months, comparisonMonth{Year: k.year, Month: k.month})
+		var ranked []*comparisonTotals
+		for _, aID := range agencies {
+			if t := byAgency[aID][k]; t != nil {
+				ranked = append(ranked, t)
+			}
+		}
+		// A ordenação estável desempata pela ordem em que os órgãos foram informados.
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return ranked[i].RemunerationsPerCapita > ranked[j].RemunerationsPerCapita
+		})
+		for i, t := range ranked {
+			t.Position = i + 1
+		}
+	}
+
+	var ranked []*agencyComparisonSeries
+	for _, aID := range agencies {
+		series := agencyComparisonSeries{AgencyID: aID, Months: make([]*comparisonTotals, len(sortedKeys))}
+		for i, k := range sortedKeys {
+			if t := byAgency[aID][k]; t != nil {
+				series.Months[i] = t
+				series.RemunerationsPerCapita += t.RemunerationsPerCapita
+				series.MonthsWithData++
+				series.Uncorrected = series.Uncorrected || t.Uncorrected
+			}
+		}
+		if series.MonthsWithData > 0 {
+			series.RemunerationsPerCapita /= float64(series.MonthsWithData)
+		}
+		comparison.Agencies = append(comparison.Agencies, series)
+	}
+	for i := range comparison.Agencies {
+		if comparison.Agencies[i].MonthsWithData > 0 {
+			ranked = append(ranked, &comparison.Agencies[i])
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].RemunerationsPerCapita > ranked[j].RemunerationsPerCapita
+	})
+	for i, s := range ranked {
+		s.Position = i + 1
+	}
+	return comparison
+}
+
+// Retorna se n está entre os valores, já validados, de um filtro da pesquisa.
+// Um filtro vazio aceita qualquer valor.
+func containsInt(values []string, n int) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if i, _ := strconv.Atoi(v); i == n {
+			return true
+		}
+	}
+	return false
+}
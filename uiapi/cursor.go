@@ -0,0 +1,47 @@
+package uiapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// searchCursor indica a partir de onde a próxima página da pesquisa deve ser
+// lida. Para os clientes, ele é um texto opaco (ver encode).
+type searchCursor struct {
+	// Índice do arquivo zip na lista ordenada de searchDetails e linha do
+	// remuneracoes.csv (contando todas as linhas, filtradas ou não).
+	Zip int `json:"z,omitempty"`
+	Row int `json:"l,omitempty"`
+	// Chave de ordenação da última linha retornada, usada quando há
+	// ordenação, pois nesse caso a posição não depende dos arquivos. A página
+	// seguinte começa pela primeira linha depois dela na ordem pedida.
+	After *sortKey `json:"k,omitempty"`
+}
+
+func (c searchCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeSearchCursor lê o cursor recebido no parâmetro "proximo". Um texto
+// vazio corresponde ao início da pesquisa.
+func decodeSearchCursor(s string) (searchCursor, error) {
+	var c searchCursor
+	if s == "" {
+		return c, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("parâmetro proximo '%s' é inválido!", s)
+	}
+	// Campos desconhecidos indicam um cursor de outra versão da API, que não
+	// pode ser usado para continuar a pesquisa.
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&c); err != nil || c.Zip < 0 || c.Row < 0 || (c.After != nil && (c.After.Zip < 0 || c.After.Row < 0)) {
+		return searchCursor{}, fmt.Errorf("parâmetro proximo '%s' é inválido!", s)
+	}
+	return c, nil
+}
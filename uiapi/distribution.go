@@ -0,0 +1,132 @@
+package uiapi
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Faixas padrão do histograma da distribuição: de R$ 10.000,00 em R$ 10.000,00
+// até R$ 100.000,00.
+var defaultBinEdges = []float64{0, 10000, 20000, 30000, 40000, 50000, 60000, 70000, 80000, 90000, 100000}
+
+// Valores dos contracheques dos membros que podem ser usados na distribuição.
+var distributionValues = map[string]func(memberResult) float64{
+	"liquido":   func(m memberResult) float64 { return m.Liquido },
+	"base":      func(m memberResult) float64 { return m.Base },
+	"outras":    func(m memberResult) float64 { return m.Outras },
+	"descontos": func(m memberResult) float64 { return m.Descontos },
+}
+
+// parseBinEdges lê os limites das faixas do histograma, separados por vírgula
+// e em ordem crescente.
+func parseBinEdges(qp string) ([]float64, error) {
+	if qp == "" {
+		return defaultBinEdges, nil
+	}
+	var edges []float64
+	for _, e := range strings.Split(qp, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(e), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("parâmetro faixas '%s' é inválido!", e)
+		}
+		if len(edges) > 0 && v <= edges[len(edges)-1] {
+			return nil, fmt.Errorf("parâmetro faixas '%s' é inválido! Os limites devem estar em ordem crescente.", qp)
+		}
+		edges = append(edges, v)
+	}
+	return edges, nil
+}
+
+// memberValues retorna o valor escolhido de cada membro nos arquivos de
+// remunerações, multiplicado por factor.
+func (h handler) memberValues(ctx context.Context, results []searchDetails, value func(memberResult) float64, factor float64) ([]float64, error) {
+	var values []float64
+	err := h.remunerations.streamMemberResults(ctx, results, nil, searchCursor{}, func(m memberResult, _ searchCursor) error {
+		values = append(values, value(m)*factor)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// percentile calcula o percentil p (entre 0 e 100) dos valores ordenados,
+// interpolando linearmente entre os dois valores mais próximos.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// gini calcula o coeficiente de Gini dos valores ordenados. Retorna nil se a
+// soma dos valores não for positiva, caso em que o coeficiente não faz sentido.
+func gini(sorted []float64) *float64 {
+	var total, weighted float64
+	for i, v := range sorted {
+		total += v
+		weighted += float64(i+1) * v
+	}
+	if total <= 0 {
+		return nil
+	}
+	n := float64(len(sorted))
+	g := 2*weighted/(n*total) - (n+1)/n
+	return &g
+}
+
+// newHistogram conta os valores em cada faixa. A primeira faixa tem os valores
+// abaixo do primeiro limite e a última, os valores a partir do último limite.
+// As demais incluem o limite inferior e excluem o superior.
+func newHistogram(sorted []float64, edges []float64) []histogramBin {
+	bins := make([]histogramBin, len(edges)+1)
+	for i := range bins {
+		if i > 0 {
+			bins[i].Start = &edges[i-1]
+		}
+		if i < len(edges) {
+			bins[i].End = &edges[i]
+		}
+	}
+	for _, v := range sorted {
+		i := sort.Search(len(edges), func(i int) bool { return edges[i] > v })
+		bins[i].Count++
+	}
+	return bins
+}
+
+// newDistribution calcula as estatísticas da distribuição dos valores.
+func newDistribution(values []float64, edges []float64) remunerationDistribution {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	d := remunerationDistribution{Members: len(sorted), Histogram: newHistogram(sorted, edges)}
+	if len(sorted) == 0 {
+		return d
+	}
+	var total float64
+	for _, v := range sorted {
+		total += v
+	}
+	d.Mean = total / float64(len(sorted))
+	d.Median = percentile(sorted, 50)
+	d.Min = sorted[0]
+	d.Max = sorted[len(sorted)-1]
+	d.Percentiles = percentiles{
+		P10: percentile(sorted, 10),
+		P25: percentile(sorted, 25),
+		P50: d.Median,
+		P75: percentile(sorted, 75),
+		P90: percentile(sorted, 90),
+		P99: percentile(sorted, 99),
+	}
+	d.Gini = gini(sorted)
+	return d
+}
@@ -0,0 +1,316 @@
+package uiapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gocarina/gocsv"
+)
+
+// Quantidade de linhas escritas de uma vez no arquivo de download.
+const downloadBatchSize = 1000
+
+// remunerationWriter escreve as linhas da pesquisa em um dos formatos de download.
+type remunerationWriter interface {
+	Write(rem searchResult) error
+	// Flush escreve as linhas que ainda estão em memória, quando o formato permite.
+	Flush() error
+	// Close finaliza o arquivo. Deve ser chamado mesmo que nenhuma linha tenha sido escrita.
+	Close() error
+}
+
+// memberWriter escreve os contracheques agrupados por membro em um dos formatos de download.
+type memberWriter interface {
+	Write(m memberResult) error
+	Flush() error
+	Close() error
+}
+
+// rowWriter é implementado por remunerationWriter e memberWriter.
+type rowWriter[T any] interface {
+	Write(row T) error
+	Flush() error
+	Close() error
+}
+
+// writeRows escreve em w as linhas enviadas por stream e finaliza o arquivo.
+// A cada downloadBatchSize linhas, as linhas em memória são escritas e flush é
+// chamado. Retorna a quantidade de linhas escritas.
+func writeRows[T any](w rowWriter[T], flush func(), stream func(fn func(T) error) error) (int, error) {
+	rows := 0
+	err := stream(func(row T) error {
+		if err := w.Write(row); err != nil {
+			return err
+		}
+		rows++
+		if rows%downloadBatchSize == 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, w.Close()
+}
+
+type downloadFormat struct {
+	ContentType     string
+	Extension       string
+	NewWriter       func(w io.Writer) (remunerationWriter, error)
+	NewMemberWriter func(w io.Writer) (memberWriter, error)
+}
+
+// Formatos aceitos pelo parâmetro "formato" da rota de download.
+var downloadFormats = map[string]downloadFormat{
+	"csv": {
+		ContentType:     "text/csv; charset=utf-8",
+		Extension:       "csv",
+		NewWriter:       newCSVRemunerationWriter,
+		NewMemberWriter: newCSVMemberWriter,
+	},
+	"jsonl": {
+		ContentType:     "application/x-ndjson; charset=utf-8",
+		Extension:       "jsonl",
+		NewWriter:       newJSONLRemunerationWriter,
+		NewMemberWriter: newJSONLMemberWriter,
+	},
+	"parquet": {
+		ContentType:     "application/vnd.apache.parquet",
+		Extension:       "parquet",
+		NewWriter:       newParquetRemunerationWriter,
+		NewMemberWriter: newParquetMemberWriter,
+	},
+}
+
+// Linha de remuneração com os valores tipados, usada no formato jsonl.
+type typedSearchResult struct {
+	Orgao                    string  `json:"orgao"`
+	Mes                      int32   `json:"mes"`
+	Ano                      int32   `json:"ano"`
+	Matricula                *string `json:"matricula"`
+	Nome                     string  `json:"nome"`
+	Cargo                    *string `json:"cargo"`
+	Lotacao                  *string `json:"lotacao"`
+	CategoriaContracheque    string  `json:"categoria_contracheque"`
+	DetalhamentoContracheque string  `json:"detalhamento_contracheque"`
+	Valor                    float64 `json:"valor"`
+	DesambiguacaoMicro       string  `json:"desambiguacao_micro"`
+	DesambiguacaoMacro       string  `json:"desambiguacao_macro"`
+}
+
+func newTypedSearchResult(rem searchResult) (*typedSearchResult, error) {
+	valor, err := parseValor(rem.Valor)
+	if err != nil {
+		return nil, err
+	}
+	return &typedSearchResult{
+		Orgao:                    rem.Orgao,
+		Mes:                      int32(rem.Mes),
+		Ano:                      int32(rem.Ano),
+		Matricula:                rem.Matricula,
+		Nome:                     rem.Nome,
+		Cargo:                    rem.Cargo,
+		Lotacao:                  rem.Lotacao,
+		CategoriaContracheque:    rem.CategoriaContracheque,
+		DetalhamentoContracheque: rem.DetalhamentoContracheque,
+		Valor:                    valor,
+		DesambiguacaoMicro:       rem.DesambiguacaoMicro,
+		DesambiguacaoMacro:       rem.DesambiguacaoMacro,
+	}, nil
+}
+
+type csvRemunerationWriter struct {
+	w     *gocsv.SafeCSVWriter
+	batch []searchResult
+}
+
+func newCSVRemunerationWriter(w io.Writer) (remunerationWriter, error) {
+	csvWriter := gocsv.NewSafeCSVWriter(csv.NewWriter(w))
+	// Escrevemos o cabeçalho do csv antes de começar a baixar os arquivos.
+	if err := gocsv.MarshalCSV([]searchResult{}, csvWriter); err != nil {
+		return nil, fmt.Errorf("error writing csv header: %w", err)
+	}
+	return &csvRemunerationWriter{w: csvWriter, batch: make([]searchResult, 0, downloadBatchSize)}, nil
+}
+
+func (c *csvRemunerationWriter) Write(rem searchResult) error {
+	c.batch = append(c.batch, rem)
+	return nil
+}
+
+func (c *csvRemunerationWriter) Flush() error {
+	if err := gocsv.MarshalCSVWithoutHeaders(c.batch, c.w); err != nil {
+		return fmt.Errorf("error writing csv rows: %w", err)
+	}
+	c.batch = c.batch[:0]
+	return nil
+}
+
+func (c *csvRemunerationWriter) Close() error {
+	return c.Flush()
+}
+
+type jsonlRemunerationWriter struct {
+	enc *json.Encoder
+}
+
+func newJSONLRemunerationWriter(w io.Writer) (remunerationWriter, error) {
+	return &jsonlRemunerationWriter{enc: json.NewEncoder(w)}, nil
+}
+
+func (j *jsonlRemunerationWriter) Write(rem searchResult) error {
+	typed, err := newTypedSearchResult(rem)
+	if err != nil {
+		return err
+	}
+	return j.enc.Encode(typed)
+}
+
+func (j *jsonlRemunerationWriter) Flush() error {
+	return nil
+}
+
+func (j *jsonlRemunerationWriter) Close() error {
+	return nil
+}
+
+type parquetRemunerationWriter struct {
+	pw *parquetWriter
+}
+
+func newParquetRemunerationWriter(w io.Writer) (remunerationWriter, error) {
+	pw, err := newParquetWriter(w, parquetColumns)
+	if err != nil {
+		return nil, fmt.Errorf("error creating parquet writer: %w", err)
+	}
+	return &parquetRemunerationWriter{pw: pw}, nil
+}
+
+func (p *parquetRemunerationWriter) Write(rem searchResult) error {
+	valor, err := parseValor(rem.Valor)
+	if err != nil {
+		return err
+	}
+	return p.pw.Write(
+		rem.Orgao,
+		int32(rem.Mes),
+		int32(rem.Ano),
+		rem.Matricula,
+		rem.Nome,
+		rem.Cargo,
+		rem.Lotacao,
+		rem.CategoriaContracheque,
+		rem.DetalhamentoContracheque,
+		valor,
+		rem.DesambiguacaoMicro,
+		rem.DesambiguacaoMacro,
+	)
+}
+
+// Os dados do parquet só podem ser escritos ao fim de cada grupo de linhas,
+// o que é feito pelo próprio parquetWriter.
+func (p *parquetRemunerationWriter) Flush() error {
+	return nil
+}
+
+func (p *parquetRemunerationWriter) Close() error {
+	if err := p.pw.Close(); err != nil {
+		return fmt.Errorf("error finishing parquet file: %w", err)
+	}
+	return nil
+}
+
+type csvMemberWriter struct {
+	w     *gocsv.SafeCSVWriter
+	batch []memberResult
+}
+
+func newCSVMemberWriter(w io.Writer) (memberWriter, error) {
+	csvWriter := gocsv.NewSafeCSVWriter(csv.NewWriter(w))
+	if err := gocsv.MarshalCSV([]memberResult{}, csvWriter); err != nil {
+		return nil, fmt.Errorf("error writing csv header: %w", err)
+	}
+	return &csvMemberWriter{w: csvWriter, batch: make([]memberResult, 0, downloadBatchSize)}, nil
+}
+
+func (c *csvMemberWriter) Write(m memberResult) error {
+	c.batch = append(c.batch, m)
+	return nil
+}
+
+func (c *csvMemberWriter) Flush() error {
+	if err := gocsv.MarshalCSVWithoutHeaders(c.batch, c.w); err != nil {
+		return fmt.Errorf("error writing csv rows: %w", err)
+	}
+	c.batch = c.batch[:0]
+	return nil
+}
+
+func (c *csvMemberWriter) Close() error {
+	return c.Flush()
+}
+
+type jsonlMemberWriter struct {
+	enc *json.Encoder
+}
+
+func newJSONLMemberWriter(w io.Writer) (memberWriter, error) {
+	return &jsonlMemberWriter{enc: json.NewEncoder(w)}, nil
+}
+
+func (j *jsonlMemberWriter) Write(m memberResult) error {
+	return j.enc.Encode(m)
+}
+
+func (j *jsonlMemberWriter) Flush() error {
+	return nil
+}
+
+func (j *jsonlMemberWriter) Close() error {
+	return nil
+}
+
+type parquetMemberWriter struct {
+	pw *parquetWriter
+}
+
+func newParquetMemberWriter(w io.Writer) (memberWriter, error) {
+	pw, err := newParquetWriter(w, memberParquetColumns)
+	if err != nil {
+		return nil, fmt.Errorf("error creating parquet writer: %w", err)
+	}
+	return &parquetMemberWriter{pw: pw}, nil
+}
+
+func (p *parquetMemberWriter) Write(m memberResult) error {
+	return p.pw.Write(
+		m.Orgao,
+		int32(m.Mes),
+		int32(m.Ano),
+		m.Matricula,
+		m.Nome,
+		m.Cargo,
+		m.Lotacao,
+		m.Base,
+		m.Outras,
+		m.Descontos,
+		m.Liquido,
+	)
+}
+
+func (p *parquetMemberWriter) Flush() error {
+	return nil
+}
+
+func (p *parquetMemberWriter) Close() error {
+	if err := p.pw.Close(); err != nil {
+		return fmt.Errorf("error finishing parquet file: %w", err)
+	}
+	return nil
+}
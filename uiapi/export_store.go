@@ -0,0 +1,241 @@
+package uiapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/dadosjusbr/api/blobstore"
+)
+
+// ErrExportNotFound é retornado pelo ExportStore quando a chave não existe.
+var ErrExportNotFound = blobstore.ErrNotFound
+
+// ErrExportExists é retornado pelo Create do ExportStore quando a chave já existe.
+var ErrExportExists = blobstore.ErrExists
+
+// ExportStore guarda os arquivos gerados pelas exportações assíncronas e o
+// estado de cada exportação, para que elas sobrevivam a reinícios da API. Os
+// snapshots da API pública são guardados no mesmo armazenamento.
+type ExportStore = blobstore.Store
+
+type s3ExportStore struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+// NewS3ExportStore cria um ExportStore que guarda as exportações em um bucket
+// do AWS S3 ou, se endpoint não for vazio, de um serviço compatível com o S3.
+// As chaves são guardadas abaixo de prefix, que pode ser vazio.
+func NewS3ExportStore(region, bucket, prefix, endpoint string) (ExportStore, error) {
+	config := &aws.Config{
+		Region: aws.String(region),
+	}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating aws session: %w", err)
+	}
+	return &s3ExportStore{
+		client:   s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+		bucket:   bucket,
+		prefix:   prefix,
+	}, nil
+}
+
+func (s *s3ExportStore) Put(ctx context.Context, key string, r io.Reader) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + key),
+		Body:   r,
+	})
+	if err != nil {
+		return fmt.Errorf("error uploading file (%s) to S3: %w", key, err)
+	}
+	return nil
+}
+
+func (s *s3ExportStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + key),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrExportNotFound
+		}
+		return nil, fmt.Errorf("error downloading file (%s) from S3: %w", key, err)
+	}
+	return out.Body, nil
+}
+
+func (s *s3ExportStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix + prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			keys = append(keys, strings.TrimPrefix(aws.StringValue(o.Key), s.prefix))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing files (%s) from S3: %w", prefix, err)
+	}
+	return keys, nil
+}
+
+func (s *s3ExportStore) Create(ctx context.Context, key string, content []byte) error {
+	// Com If-None-Match, o S3 só cria o objeto se ele ainda não existir.
+	_, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + key),
+		Body:   bytes.NewReader(content),
+	}, request.WithSetRequestHeaders(map[string]string{"If-None-Match": "*"}))
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && (aerr.Code() == "PreconditionFailed" || aerr.Code() == "ConditionalRequestConflict") {
+			return ErrExportExists
+		}
+		return fmt.Errorf("error creating file (%s) in S3: %w", key, err)
+	}
+	return nil
+}
+
+func (s *s3ExportStore) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + key),
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting file (%s) from S3: %w", key, err)
+	}
+	return nil
+}
+
+type dirExportStore struct {
+	dir string
+}
+
+// NewDirExportStore cria um ExportStore que guarda as exportações em um
+// diretório local, usando as chaves como caminhos relativos.
+func NewDirExportStore(dir string) ExportStore {
+	return &dirExportStore{dir: dir}
+}
+
+// Caminho do arquivo da chave, que nunca sai do diretório.
+func (d *dirExportStore) path(key string) string {
+	return filepath.Join(d.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+func (d *dirExportStore) Put(ctx context.Context, key string, r io.Reader) error {
+	name := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("error creating directory (%s): %w", filepath.Dir(name), err)
+	}
+	// Escrevemos em um arquivo temporário e depois renomeamos, para que um
+	// arquivo incompleto nunca seja lido.
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating file (%s): %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing file (%s): %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing file (%s): %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("error writing file (%s): %w", name, err)
+	}
+	return nil
+}
+
+func (d *dirExportStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrExportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading file (%s): %w", key, err)
+	}
+	return f, nil
+}
+
+func (d *dirExportStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(d.dir, func(name string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(d.dir, name)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing files (%s): %w", d.dir, err)
+	}
+	return keys, nil
+}
+
+func (d *dirExportStore) Create(ctx context.Context, key string, content []byte) error {
+	name := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("error creating directory (%s): %w", filepath.Dir(name), err)
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return ErrExportExists
+	}
+	if err != nil {
+		return fmt.Errorf("error creating file (%s): %w", name, err)
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing file (%s): %w", name, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing file (%s): %w", name, err)
+	}
+	return nil
+}
+
+func (d *dirExportStore) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(d.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error deleting file (%s): %w", key, err)
+	}
+	return nil
+}
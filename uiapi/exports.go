@@ -0,0 +1,471 @@
+package uiapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Situações de uma exportação.
+const (
+	exportPending = "pendente"
+	exportRunning = "executando"
+	exportDone    = "concluida"
+	exportFailed  = "erro"
+)
+
+// Prefixo das chaves das exportações no ExportStore.
+const exportsPrefix = "exportacoes/"
+
+// Quantidade máxima de exportações esperando na fila.
+const exportQueueSize = 1000
+
+// Tempo padrão que as exportações ficam guardadas depois de criadas.
+const defaultExportTTL = 24 * time.Hour
+
+// Quantidade padrão de exportações de um mesmo cliente na fila ou executando.
+const defaultExportsPerClient = 3
+
+// Intervalo entre as limpezas das exportações expiradas, que também colocam de
+// volta na fila as exportações de instâncias que pararam.
+const exportCleanupInterval = 5 * time.Minute
+
+// Intervalo em que a instância que gera uma exportação renova sua reserva.
+const exportLeaseRenewal = time.Minute
+
+// Tempo sem renovação depois do qual a reserva de uma exportação é
+// considerada abandonada, pois a instância que a gerava parou.
+const exportLeaseTimeout = 5 * time.Minute
+
+var exportIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// exportQueue guarda as exportações que ainda precisam ser geradas. O estado
+// de cada exportação fica no ExportStore, que é a fonte da verdade.
+type exportQueue struct {
+	store        ExportStore
+	pending      chan string
+	ttl          time.Duration
+	maxPerClient int
+	// Identifica esta instância da API nas reservas das exportações.
+	instance string
+
+	// Serializa a contagem das exportações de um cliente e a criação de uma
+	// nova, para que requisições simultâneas não passem do limite.
+	mu sync.Mutex
+}
+
+// storedExport é como a exportação é guardada no ExportStore. Além dos campos
+// retornados pela API, guarda o cliente e a tentativa atual.
+type storedExport struct {
+	exportJob
+	Client  string `json:"cliente"`
+	Attempt int    `json:"tentativa"`
+}
+
+// exportClaim é o conteúdo da reserva de uma exportação, renovado enquanto a
+// instância a gera.
+type exportClaim struct {
+	Instance  string    `json:"instancia"`
+	RenewedAt time.Time `json:"renovada_em"`
+}
+
+func newExportQueue(store ExportStore) *exportQueue {
+	instance, _ := os.Hostname()
+	if id, err := newExportID(); err == nil {
+		instance += "-" + id[:8]
+	}
+	return &exportQueue{
+		store:        store,
+		pending:      make(chan string, exportQueueSize),
+		ttl:          defaultExportTTL,
+		maxPerClient: defaultExportsPerClient,
+		instance:     instance,
+	}
+}
+
+func exportJobKey(id string) string {
+	return exportsPrefix + id + ".json"
+}
+
+func exportFileKey(job *exportJob) string {
+	return exportsPrefix + job.ID + "." + downloadFormats[job.Format].Extension
+}
+
+// exportClaimKey é a chave da reserva de uma tentativa da exportação. Cada
+// tentativa tem sua própria reserva, então uma exportação abandonada volta
+// para a fila sem que a reserva antiga precise ser apagada.
+func exportClaimKey(id string, attempt int) string {
+	return fmt.Sprintf("%s%s.%d.claim", exportsPrefix, id, attempt)
+}
+
+// exportClient identifica o cliente sem guardar seu IP.
+func exportClient(ip string) string {
+	sum := sha256.Sum256([]byte(ip))
+	return hex.EncodeToString(sum[:])
+}
+
+func newExportID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating export id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// finish registra o fim da exportação e quando ela será apagada.
+func (j *exportJob) finish(now time.Time, ttl time.Duration) {
+	expiresAt := now.Add(ttl)
+	j.FinishedAt = &now
+	j.ExpiresAt = &expiresAt
+}
+
+func (q *exportQueue) save(ctx context.Context, job *exportJob) error {
+	b, err := json.Marshal(storedExport{exportJob: *job, Client: job.client, Attempt: job.attempt})
+	if err != nil {
+		return fmt.Errorf("error encoding export %s: %w", job.ID, err)
+	}
+	return q.store.Put(ctx, exportJobKey(job.ID), bytes.NewReader(b))
+}
+
+// load retorna ErrExportNotFound se a exportação não existir.
+func (q *exportQueue) load(ctx context.Context, id string) (*exportJob, error) {
+	r, err := q.store.Get(ctx, exportJobKey(id))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var stored storedExport
+	if err := json.NewDecoder(r).Decode(&stored); err != nil {
+		return nil, fmt.Errorf("error decoding export %s: %w", id, err)
+	}
+	job := stored.exportJob
+	job.client = stored.Client
+	job.attempt = stored.Attempt
+	return &job, nil
+}
+
+// jobs retorna todas as exportações guardadas no ExportStore.
+func (q *exportQueue) jobs(ctx context.Context) ([]*exportJob, error) {
+	keys, err := q.store.List(ctx, exportsPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing exports: %w", err)
+	}
+	var jobs []*exportJob
+	for _, k := range keys {
+		if !strings.HasSuffix(k, ".json") {
+			continue
+		}
+		job, err := q.load(ctx, strings.TrimSuffix(strings.TrimPrefix(k, exportsPrefix), ".json"))
+		if err != nil {
+			log.Printf("[exports] error loading %s: %q", k, err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// create guarda a nova exportação do cliente. Retorna false, sem guardá-la, se
+// o cliente já tem maxPerClient exportações não finalizadas. A contagem usa as
+// exportações guardadas, então vale para todas as instâncias e sobrevive a
+// reinícios da API.
+func (q *exportQueue) create(ctx context.Context, job *exportJob, ip string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs, err := q.jobs(ctx)
+	if err != nil {
+		return false, err
+	}
+	job.client = exportClient(ip)
+	n := 0
+	for _, j := range jobs {
+		if j.client == job.client && j.FinishedAt == nil {
+			n++
+		}
+	}
+	if n >= q.maxPerClient {
+		return false, nil
+	}
+	return true, q.save(ctx, job)
+}
+
+// claim reserva a tentativa atual da exportação para esta instância com uma
+// escrita condicional. Retorna false se ela já foi reservada, por esta ou por
+// outra instância, para que uma tentativa nunca seja gerada duas vezes.
+func (q *exportQueue) claim(ctx context.Context, job *exportJob, now time.Time) (bool, error) {
+	b, err := json.Marshal(exportClaim{Instance: q.instance, RenewedAt: now})
+	if err != nil {
+		return false, fmt.Errorf("error encoding claim of export %s: %w", job.ID, err)
+	}
+	err = q.store.Create(ctx, exportClaimKey(job.ID, job.attempt), b)
+	if errors.Is(err, ErrExportExists) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error claiming export %s: %w", job.ID, err)
+	}
+	return true, nil
+}
+
+// renew renova a reserva da exportação a cada exportLeaseRenewal, até que ctx
+// seja cancelado.
+func (q *exportQueue) renew(ctx context.Context, job *exportJob) {
+	ticker := time.NewTicker(exportLeaseRenewal)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			b, _ := json.Marshal(exportClaim{Instance: q.instance, RenewedAt: now})
+			if err := q.store.Put(ctx, exportClaimKey(job.ID, job.attempt), bytes.NewReader(b)); err != nil {
+				log.Printf("[exports] error renewing claim of export %s: %q", job.ID, err)
+			}
+		}
+	}
+}
+
+// abandoned diz se a tentativa atual da exportação foi reservada por uma
+// instância que parou de renovar a reserva. Uma exportação pendente sem
+// reserva não está abandonada, apenas esperando na fila.
+func (q *exportQueue) abandoned(ctx context.Context, job *exportJob, now time.Time) (bool, error) {
+	r, err := q.store.Get(ctx, exportClaimKey(job.ID, job.attempt))
+	if errors.Is(err, ErrExportNotFound) {
+		return job.Status == exportRunning, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+	var claim exportClaim
+	if err := json.NewDecoder(r).Decode(&claim); err != nil {
+		return false, fmt.Errorf("error decoding claim of export %s: %w", job.ID, err)
+	}
+	return now.Sub(claim.RenewedAt) > exportLeaseTimeout, nil
+}
+
+// cleanup apaga as exportações finalizadas há mais de ttl, marca como falha as
+// que foram criadas há mais de ttl e não terminaram e coloca de volta como
+// pendentes as abandonadas por uma instância que parou. Retorna todas as
+// exportações pendentes e, separadamente, as que voltaram a ser pendentes.
+func (q *exportQueue) cleanup(ctx context.Context, now time.Time) (pending, requeued []string, err error) {
+	jobs, err := q.jobs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, job := range jobs {
+		switch {
+		case job.FinishedAt != nil && now.Sub(*job.FinishedAt) > q.ttl:
+			keys := []string{exportFileKey(job), exportJobKey(job.ID)}
+			for i := 0; i <= job.attempt; i++ {
+				keys = append(keys, exportClaimKey(job.ID, i))
+			}
+			for _, key := range keys {
+				if err := q.store.Delete(ctx, key); err != nil {
+					log.Printf("[exports] error deleting %s: %q", key, err)
+				}
+			}
+		case job.FinishedAt == nil && now.Sub(job.CreatedAt) > q.ttl:
+			job.Status = exportFailed
+			job.Error = "exportação expirada antes de ser concluída"
+			job.finish(now, q.ttl)
+			if err := q.save(ctx, job); err != nil {
+				log.Printf("[exports] error saving export %s: %q", job.ID, err)
+			}
+		case job.FinishedAt == nil:
+			abandoned, err := q.abandoned(ctx, job, now)
+			if err != nil {
+				log.Printf("[exports] error checking claim of export %s: %q", job.ID, err)
+				continue
+			}
+			if abandoned {
+				job.Status = exportPending
+				job.attempt++
+				if err := q.save(ctx, job); err != nil {
+					log.Printf("[exports] error saving export %s: %q", job.ID, err)
+					continue
+				}
+				requeued = append(requeued, job.ID)
+			}
+			if job.Status == exportPending {
+				pending = append(pending, job.ID)
+			}
+		}
+	}
+	return pending, requeued, nil
+}
+
+// enqueue coloca a exportação na fila sem bloquear. Retorna false se a fila
+// estiver cheia.
+func (q *exportQueue) enqueue(id string) bool {
+	select {
+	case q.pending <- id:
+		return true
+	default:
+		return false
+	}
+}
+
+// StartExports inicia os workers que geram as exportações e a limpeza
+// periódica das exportações, e coloca de volta na fila as exportações
+// pendentes. As exportações que estavam executando em uma instância que parou
+// de renovar sua reserva voltam para a fila, nesta limpeza inicial ou nas
+// seguintes. Valores não positivos de maxPerClient e ttl mantêm os padrões.
+func (h handler) StartExports(ctx context.Context, workers, maxPerClient int, ttl time.Duration) error {
+	if h.exports == nil {
+		return nil
+	}
+	if maxPerClient > 0 {
+		h.exports.maxPerClient = maxPerClient
+	}
+	if ttl > 0 {
+		h.exports.ttl = ttl
+	}
+	pending, _, err := h.exports.cleanup(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for i := 0; i < workers; i++ {
+		go h.exportWorker(ctx)
+	}
+	go func() {
+		ticker := time.NewTicker(exportCleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, requeued, err := h.exports.cleanup(ctx, time.Now())
+				if err != nil {
+					log.Printf("[exports] error cleaning up exports: %q", err)
+				}
+				// As demais pendentes já estão na fila de alguma instância.
+				for _, id := range requeued {
+					if !h.exports.enqueue(id) {
+						log.Printf("[exports] queue is full, export %s will be retried later", id)
+					}
+				}
+			}
+		}
+	}()
+	// A fila pode ter menos espaço que o número de exportações pendentes.
+	go func() {
+		for _, id := range pending {
+			select {
+			case h.exports.pending <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (h handler) exportWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-h.exports.pending:
+			if err := h.runExport(ctx, id); err != nil {
+				log.Printf("[exports] error running export %s: %q", id, err)
+			}
+		}
+	}
+}
+
+// runExport reserva a exportação, gera seu arquivo e atualiza seu estado.
+// Enquanto o arquivo é gerado, a reserva é renovada para que outras instâncias
+// saibam que esta não parou.
+func (h handler) runExport(ctx context.Context, id string) error {
+	job, err := h.exports.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	if job.Status != exportPending {
+		return nil
+	}
+	claimed, err := h.exports.claim(ctx, job, time.Now())
+	if err != nil || !claimed {
+		return err
+	}
+	leaseCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go h.exports.renew(leaseCtx, job)
+	job.Status = exportRunning
+	if err := h.exports.save(ctx, job); err != nil {
+		return err
+	}
+	rows, err := h.writeExport(ctx, job)
+	stop()
+	// Se a reserva expirou, outra tentativa pode ter começado e é ela quem
+	// registra o resultado.
+	if current, loadErr := h.exports.load(ctx, id); loadErr == nil && current.attempt != job.attempt {
+		return nil
+	}
+	job.finish(time.Now().In(h.loc), h.exports.ttl)
+	if err != nil {
+		log.Printf("[exports] error generating export %s: %q", id, err)
+		job.Status = exportFailed
+		job.Error = "erro ao gerar o arquivo da exportação"
+	} else {
+		job.Status = exportDone
+		job.Rows = rows
+		job.File = fmt.Sprintf("/uiapi/v2/exportacoes/%s/arquivo", job.ID)
+	}
+	return h.exports.save(ctx, job)
+}
+
+// writeExport gera o arquivo em um arquivo temporário e depois o envia ao
+// ExportStore. Retorna a quantidade de linhas escritas.
+func (h handler) writeExport(ctx context.Context, job *exportJob) (int, error) {
+	qp, err := url.ParseQuery(job.Parameters)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing parameters: %w", err)
+	}
+	params, err := newSearchParams(qp)
+	if err != nil {
+		return 0, err
+	}
+	if err := h.resolveAgencyGroups(params); err != nil {
+		return 0, err
+	}
+	results, err := h.db.filter(h.db.remunerationQuery(params), h.db.arguments(params))
+	if err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp("", "dadosjusbr-exportacao-*")
+	if err != nil {
+		return 0, fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	// As exportações existem para os arquivos grandes demais para a rota de
+	// download, então não têm o limite de linhas dela.
+	rows, err := h.writeSearchResults(ctx, tmp, downloadFormats[job.Format], 0, params, results, func() {})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("error reading temporary file: %w", err)
+	}
+	if err := h.exports.store.Put(ctx, exportFileKey(job), tmp); err != nil {
+		return 0, err
+	}
+	return rows, nil
+}
@@ -1,8 +1,13 @@
 package uiapi
 
 import (
+	"bytes"
+	"context"
+	"crypto/subtle"
 	_ "embed"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"sort"
@@ -10,9 +15,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dadosjusbr/api/daterange"
+	"github.com/dadosjusbr/api/ipca"
+	"github.com/dadosjusbr/api/jurisdiction"
 	"github.com/dadosjusbr/storage"
 	strModels "github.com/dadosjusbr/storage/models"
-	"github.com/gocarina/gocsv"
 	"github.com/labstack/echo/v4"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"gorm.io/gorm"
@@ -21,32 +28,37 @@ import (
 type handler struct {
 	client           *storage.Client
 	db               *postgresDB
-	sess             *awsSession
-	s3Bucket         string
+	remunerations    *remunerationReader
+	exports          *exportQueue
 	loc              *time.Location
 	envOmittedFields []string
 	searchLimit      int
 	downloadLimit    int
+	downloadAPIKeys  []string
 }
 
-func NewHandler(client *storage.Client, conn *gorm.DB, newrelic *newrelic.Application, awsRegion string, s3Bucket string, loc *time.Location, envOmittedFields []string, searchLimit, downloadLimit int) (*handler, error) {
+func NewHandler(client *storage.Client, conn *gorm.DB, newrelic *newrelic.Application, zips ZipStore, exports ExportStore, loc *time.Location, envOmittedFields []string, searchLimit, downloadLimit int, downloadAPIKeys []string) (*handler, error) {
 	db := &postgresDB{
 		conn:     conn,
 		newrelic: newrelic,
 	}
-	sess, err := newAwsSession(awsRegion)
-	if err != nil {
-		return nil, err
+	var queue *exportQueue
+	if exports != nil {
+		queue = newExportQueue(exports)
 	}
 	return &handler{
-		db:               db,
-		sess:             sess,
-		s3Bucket:         s3Bucket,
+		db: db,
+		remunerations: &remunerationReader{
+			zips:     zips,
+			newrelic: newrelic,
+		},
+		exports:          queue,
 		client:           client,
 		loc:              loc,
 		envOmittedFields: envOmittedFields,
 		searchLimit:      searchLimit,
 		downloadLimit:    downloadLimit,
+		downloadAPIKeys:  downloadAPIKeys,
 	}, nil
 }
 
@@ -337,9 +349,12 @@ func (h handler) GetTotalsOfAgencyYear(c echo.Context) error {
 // @Description	- Detalhamento de rubricas (auxílios, férias, gratificações)
 // @Description	- Informações gerais sobre o órgão pesquisado
 // @Description	- Informações sobre o pacote de dados (URL para download, hash, tamanho)
+// @Description	- Anomalias calculadas pela API, comparando cada mês com a mediana dos 6 meses anteriores com dados (ver /uiapi/v2/anomalias)
 // @Produce		json
 // @Param			orgao	path		string				true	"Identificador do órgão público"	example:"tjal"
 // @Param			ano		path		int					true	"Ano de referência para a consulta"	example:"2022"
+// @Param			corrigir	query		string				false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao."
+// @Param			base		query		string				false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
 // @Success		200		{object}	v2AgencyTotalsYear	"Dados financeiros completos do órgão no ano especificado"
 // @Failure		400		{string}	string				"Erro de validação: parâmetros de órgão ou ano inválidos"
 // @Failure		500		{string}	string				"Erro interno durante processamento da consulta"
@@ -349,12 +364,25 @@ func (h handler) V2GetTotalsOfAgencyYear(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, fmt.Sprintf("Parâmetro ano=%s inválido", c.Param("ano")))
 	}
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
 	aID := c.Param("orgao")
 	agenciesMonthlyInfo, err := h.client.Db.GetMonthlyInfo([]strModels.Agency{{ID: aID}}, year)
 	if err != nil {
 		log.Printf("[totals of agency year] error getting data for first screen(ano:%d, estado:%s):%q", year, aID, err)
 		return c.JSON(http.StatusBadRequest, fmt.Sprintf("Parâmetro ano=%d ou orgao=%s inválidos", year, aID))
 	}
+	// Os meses do ano anterior servem de histórico para as anomalias do início do ano.
+	history := agenciesMonthlyInfo[aID]
+	previousMonthlyInfo, err := h.client.Db.GetMonthlyInfo([]strModels.Agency{{ID: aID}}, year-1)
+	if err != nil {
+		log.Printf("[totals of agency year] error getting previous year (ano:%d, estado:%s):%q", year-1, aID, err)
+	} else {
+		history = append(previousMonthlyInfo[aID], history...)
+	}
+	anomalies := detectAnomalies(history)
 	var monthTotalsOfYear []v2MonthTotals
 	strAgency, err := h.client.Db.GetAgency(aID)
 	if err != nil {
@@ -369,34 +397,9 @@ func (h handler) V2GetTotalsOfAgencyYear(c echo.Context) error {
 	host := c.Request().Host
 	strAgency.URL = fmt.Sprintf("%s/v2/orgao/%s", host, strAgency.ID)
 	for _, agencyMonthlyInfo := range agenciesMonthlyInfo[aID] {
-		if agencyMonthlyInfo.Summary != nil && agencyMonthlyInfo.Summary.BaseRemuneration.Total+agencyMonthlyInfo.Summary.OtherRemunerations.Total > 0 {
-			monthTotals := v2MonthTotals{Month: agencyMonthlyInfo.Month,
-				BaseRemuneration:            agencyMonthlyInfo.Summary.BaseRemuneration.Total,
-				OtherRemunerations:          agencyMonthlyInfo.Summary.OtherRemunerations.Total,
-				Remunerations:               agencyMonthlyInfo.Summary.Remunerations.Total,
-				Discounts:                   agencyMonthlyInfo.Summary.Discounts.Total,
-				BaseRemunerationPerCapita:   agencyMonthlyInfo.Summary.BaseRemuneration.Average,
-				OtherRemunerationsPerCapita: agencyMonthlyInfo.Summary.OtherRemunerations.Average,
-				RemunerationsPerCapita:      agencyMonthlyInfo.Summary.Remunerations.Average,
-				DiscountsPerCapita:          agencyMonthlyInfo.Summary.Discounts.Average,
-				CrawlingTimestamp: timestamp{
-					Seconds: agencyMonthlyInfo.CrawlingTimestamp.GetSeconds(),
-					Nanos:   agencyMonthlyInfo.CrawlingTimestamp.GetNanos(),
-				},
-				MemberCount: agencyMonthlyInfo.Summary.Count,
-				ItemSummary: itemSummary(agencyMonthlyInfo.Summary.ItemSummary),
-			}
-			monthTotalsOfYear = append(monthTotalsOfYear, monthTotals)
-
-			// The status 4 is a report from crawlers that data is unavailable or malformed. By removing them from the API results, we make sure they are displayed as if there is no data.
-		} else if agencyMonthlyInfo.ProcInfo.String() != "" && agencyMonthlyInfo.ProcInfo.Status != 4 {
-			monthTotals := v2MonthTotals{Month: agencyMonthlyInfo.Month,
-				CrawlingTimestamp: timestamp{
-					Seconds: agencyMonthlyInfo.CrawlingTimestamp.GetSeconds(),
-					Nanos:   agencyMonthlyInfo.CrawlingTimestamp.GetNanos(),
-				},
-				Error: &procError{Stdout: agencyMonthlyInfo.ProcInfo.Stdout, Stderr: agencyMonthlyInfo.ProcInfo.Stderr},
-			}
+		f := corr.Month(year, agencyMonthlyInfo.Month)
+		if monthTotals, ok := newV2MonthTotals(agencyMonthlyInfo, f, anomalies[yearMonth{Year: year, Month: agencyMonthlyInfo.Month}]); ok {
+			monthTotals.Uncorrected = corr.Uncorrected(year, agencyMonthlyInfo.Month)
 			monthTotalsOfYear = append(monthTotalsOfYear, monthTotals)
 		}
 	}
@@ -414,6 +417,61 @@ func (h handler) V2GetTotalsOfAgencyYear(c echo.Context) error {
 		}
 	}
 
+	agencyTotalsYear := v2AgencyTotalsYear{
+		Year:           year,
+		Agency:         newAgency(strAgency),
+		MonthTotals:    monthTotalsOfYear,
+		SummaryPackage: pkg,
+		AveragePerCapita: &perCapitaData{
+			BaseRemuneration:   strAveragePerCapita.BaseRemuneration * corr.Year(year),
+			OtherRemunerations: strAveragePerCapita.OtherRemunerations * corr.Year(year),
+			Discounts:          strAveragePerCapita.Discounts * corr.Year(year),
+			Remunerations:      strAveragePerCapita.Remunerations * corr.Year(year),
+			Uncorrected:        corr.UncorrectedYear(year),
+		},
+		Correction: corr,
+	}
+	return c.JSON(http.StatusOK, agencyTotalsYear)
+}
+
+// newV2MonthTotals calcula os totais de um mês do órgão, com os valores
+// multiplicados por f. Retorna false para os meses sem dados, inclusive os que
+// a coleta informou estarem indisponíveis.
+func newV2MonthTotals(mi strModels.AgencyMonthlyInfo, f float64, anomalies []anomaly) (v2MonthTotals, bool) {
+	if mi.Summary != nil && mi.Summary.BaseRemuneration.Total+mi.Summary.OtherRemunerations.Total > 0 {
+		return v2MonthTotals{Month: mi.Month,
+			BaseRemuneration:            mi.Summary.BaseRemuneration.Total * f,
+			OtherRemunerations:          mi.Summary.OtherRemunerations.Total * f,
+			Remunerations:               mi.Summary.Remunerations.Total * f,
+			Discounts:                   mi.Summary.Discounts.Total * f,
+			BaseRemunerationPerCapita:   mi.Summary.BaseRemuneration.Average * f,
+			OtherRemunerationsPerCapita: mi.Summary.OtherRemunerations.Average * f,
+			RemunerationsPerCapita:      mi.Summary.Remunerations.Average * f,
+			DiscountsPerCapita:          mi.Summary.Discounts.Average * f,
+			CrawlingTimestamp: timestamp{
+				Seconds: mi.CrawlingTimestamp.GetSeconds(),
+				Nanos:   mi.CrawlingTimestamp.GetNanos(),
+			},
+			MemberCount: mi.Summary.Count,
+			ItemSummary: itemSummary(ipca.Items(mi.Summary.ItemSummary, f)),
+			Anomalies:   anomalies,
+		}, true
+
+		// The status 4 is a report from crawlers that data is unavailable or malformed. By removing them from the API results, we make sure they are displayed as if there is no data.
+	} else if mi.ProcInfo != nil && mi.ProcInfo.String() != "" && mi.ProcInfo.Status != 4 {
+		return v2MonthTotals{Month: mi.Month,
+			CrawlingTimestamp: timestamp{
+				Seconds: mi.CrawlingTimestamp.GetSeconds(),
+				Nanos:   mi.CrawlingTimestamp.GetNanos(),
+			},
+			Error: &procError{Stdout: mi.ProcInfo.Stdout, Stderr: mi.ProcInfo.Stderr},
+		}, true
+	}
+	return v2MonthTotals{}, false
+}
+
+// newAgency converte o órgão do banco de dados no formato retornado pela API.
+func newAgency(strAgency *strModels.Agency) *agency {
 	var collect []collecting
 	var hasData bool
 	for _, c := range strAgency.Collecting {
@@ -423,30 +481,103 @@ func (h handler) V2GetTotalsOfAgencyYear(c echo.Context) error {
 		})
 		hasData = c.Collecting
 	}
-	agencyTotalsYear := v2AgencyTotalsYear{
-		Year: year,
-		Agency: &agency{
-			ID:            strAgency.ID,
-			Name:          strAgency.Name,
-			Type:          strAgency.Type,
-			Entity:        strAgency.Entity,
-			UF:            strAgency.UF,
-			URL:           strAgency.URL,
-			Collecting:    collect,
-			TwitterHandle: strAgency.TwitterHandle,
-			OmbudsmanURL:  strAgency.OmbudsmanURL,
-			HasData:       hasData,
-		},
-		MonthTotals:    monthTotalsOfYear,
-		SummaryPackage: pkg,
-		AveragePerCapita: &perCapitaData{
-			BaseRemuneration:   strAveragePerCapita.BaseRemuneration,
-			OtherRemunerations: strAveragePerCapita.OtherRemunerations,
-			Discounts:          strAveragePerCapita.Discounts,
-			Remunerations:      strAveragePerCapita.Remunerations,
-		},
+	return &agency{
+		ID:            strAgency.ID,
+		Name:          strAgency.Name,
+		Type:          strAgency.Type,
+		Entity:        strAgency.Entity,
+		UF:            strAgency.UF,
+		URL:           strAgency.URL,
+		Collecting:    collect,
+		TwitterHandle: strAgency.TwitterHandle,
+		OmbudsmanURL:  strAgency.OmbudsmanURL,
+		HasData:       hasData,
 	}
-	return c.JSON(http.StatusOK, agencyTotalsYear)
+}
+
+// @ID				GetTotalsOfAgencyRange
+// @Tags			ui_api
+// @Description	Retorna os totais mensais de um órgão em um intervalo de meses, que pode abranger vários anos, no mesmo formato de /uiapi/v2/orgao/totais/{orgao}/{ano}. Cada mês informa o seu ano e a média por membro é calculada com os meses do intervalo: os totais divididos pela soma da quantidade de membros de cada mês.
+// @Produce		json
+// @Param			orgao		path		string				true	"Identificador do órgão público"	example:"tjal"
+// @Param			de			query		string				false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Padrão: 2018-01."
+// @Param			ate			query		string				false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Padrão: mês atual."
+// @Param			corrigir	query		string				false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao."
+// @Param			base		query		string				false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
+// @Success		200			{object}	agencyTotalsRange	"Totais mensais do órgão no intervalo"
+// @Failure		400			{string}	string				"Parâmetros inválidos"
+// @Failure		500			{string}	string				"Erro interno do servidor"
+// @Router			/uiapi/v2/orgao/totais/{orgao} [get]
+func (h handler) GetTotalsOfAgencyRange(c echo.Context) error {
+	dateRange, err := daterange.Parse(c.QueryParam(daterange.FromParam), c.QueryParam(daterange.ToParam))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if dateRange == nil {
+		return c.JSON(http.StatusBadRequest, "parâmetro de ou ate é obrigatório!")
+	}
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	aID := strings.ToLower(c.Param("orgao"))
+	strAgency, err := h.client.Db.GetAgency(aID)
+	if err != nil {
+		log.Printf("[totals of agency range] error getting agency (orgao:%s): %q", aID, err)
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("Parâmetro orgao=%s inválido", aID))
+	}
+	strAgency.URL = fmt.Sprintf("%s/v2/orgao/%s", c.Request().Host, strAgency.ID)
+
+	// O ano anterior ao intervalo serve de histórico para as anomalias dos primeiros meses.
+	var history []strModels.AgencyMonthlyInfo
+	years := dateRange.Years()
+	for _, y := range append([]int{years[0] - 1}, years...) {
+		infos, err := h.client.Db.GetMonthlyInfo([]strModels.Agency{{ID: aID}}, y)
+		if err != nil {
+			log.Printf("[totals of agency range] error getting monthly info (ano:%d, orgao:%s): %q", y, aID, err)
+			return c.JSON(http.StatusInternalServerError, "erro ao buscar os dados mensais do órgão")
+		}
+		history = append(history, infos[aID]...)
+	}
+	anomalies := detectAnomalies(history)
+
+	totals := agencyTotalsRange{Range: dateRange, Agency: newAgency(strAgency), MonthTotals: []v2MonthTotals{}, Correction: corr}
+	var perCapita perCapitaData
+	var memberMonths int
+	for _, mi := range history {
+		if !dateRange.Contains(mi.Year, mi.Month) {
+			continue
+		}
+		ym := yearMonth{Year: mi.Year, Month: mi.Month}
+		monthTotals, ok := newV2MonthTotals(mi, corr.Month(mi.Year, mi.Month), anomalies[ym])
+		if !ok {
+			continue
+		}
+		monthTotals.Year = mi.Year
+		monthTotals.Uncorrected = corr.Uncorrected(mi.Year, mi.Month)
+		totals.MonthTotals = append(totals.MonthTotals, monthTotals)
+		perCapita.Uncorrected = perCapita.Uncorrected || monthTotals.Uncorrected
+		perCapita.BaseRemuneration += monthTotals.BaseRemuneration
+		perCapita.OtherRemunerations += monthTotals.OtherRemunerations
+		perCapita.Discounts += monthTotals.Discounts
+		perCapita.Remunerations += monthTotals.Remunerations
+		memberMonths += monthTotals.MemberCount
+	}
+	sort.Slice(totals.MonthTotals, func(i, j int) bool {
+		a, b := totals.MonthTotals[i], totals.MonthTotals[j]
+		return yearMonth{Year: a.Year, Month: a.Month}.number() < yearMonth{Year: b.Year, Month: b.Month}.number()
+	})
+	if memberMonths > 0 {
+		n := float64(memberMonths)
+		totals.AveragePerCapita = &perCapitaData{
+			BaseRemuneration:   perCapita.BaseRemuneration / n,
+			OtherRemunerations: perCapita.OtherRemunerations / n,
+			Discounts:          perCapita.Discounts / n,
+			Remunerations:      perCapita.Remunerations / n,
+			Uncorrected:        perCapita.Uncorrected,
+		}
+	}
+	return c.JSON(http.StatusOK, totals)
 }
 
 // TODO: Remover quando o site tiver migrado para o novo endpoint
@@ -457,16 +588,7 @@ func (h handler) GetBasicInfoOfType(c echo.Context) error {
 	var err error
 	var estadual bool
 	var exists bool
-	jurisdicao := map[string]string{
-		"justica-eleitoral":    "Eleitoral",
-		"ministerios-publicos": "Ministério",
-		"justica-estadual":     "Estadual",
-		"justica-do-trabalho":  "Trabalho",
-		"justica-federal":      "Federal",
-		"justica-militar":      "Militar",
-		"justica-superior":     "Superior",
-		"conselhos-de-justica": "Conselho",
-	}
+	jurisdicao := jurisdiction.Groups
 
 	// Adaptando as URLs do site com o banco de dados
 	// Primeiro consultamos entre as chaves do mapa.
@@ -537,16 +659,7 @@ func (h handler) V2GetBasicInfoOfType(c echo.Context) error {
 	var err error
 	var estadual bool
 	var exists bool
-	jurisdicao := map[string]string{
-		"justica-eleitoral":    "Eleitoral",
-		"ministerios-publicos": "Ministério",
-		"justica-estadual":     "Estadual",
-		"justica-do-trabalho":  "Trabalho",
-		"justica-federal":      "Federal",
-		"justica-militar":      "Militar",
-		"justica-superior":     "Superior",
-		"conselhos-de-justica": "Conselho",
-	}
+	jurisdicao := jurisdiction.Groups
 
 	// Adaptando as URLs do site com o banco de dados
 	// Primeiro consultamos entre as chaves do mapa.
@@ -563,8 +676,7 @@ func (h handler) V2GetBasicInfoOfType(c echo.Context) error {
 		}
 		// Se a jurisdição não existir no mapa, verificamos se trata-se de um estado
 		if !exists {
-			values := map[string]struct{}{"AC": {}, "AL": {}, "AP": {}, "AM": {}, "BA": {}, "CE": {}, "DF": {}, "ES": {}, "GO": {}, "MA": {}, "MT": {}, "MS": {}, "MG": {}, "PA": {}, "PB": {}, "PR": {}, "PE": {}, "PI": {}, "RJ": {}, "RN": {}, "RS": {}, "RO": {}, "RR": {}, "SC": {}, "SP": {}, "SE": {}, "TO": {}}
-			if _, estadual = values[strings.ToUpper(groupName)]; estadual {
+			if _, estadual = federativeUnits[strings.ToUpper(groupName)]; estadual {
 				exists = true
 			}
 		}
@@ -620,6 +732,8 @@ func (h handler) GetGeneralRemunerationFromYear(c echo.Context) error {
 // @Description	Busca os dados, das remunerações (remuneração base/salário, outras remunerações/benefícios, descontos) e benefícios identificados (rubricas/penduricalhos) de um ano inteiro, agrupados por mês.
 // @Produce		json
 // @Param			ano									path		string					true	"Ano da remuneração. Ex.: 2018, 2019, 2020..."
+// @Param			corrigir							query		string					false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao. O mês base usado é informado no cabeçalho X-Correcao-Monetaria."
+// @Param			base								query		string					false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
 // @Success		200									{object}	[]mensalRemuneration	"Requisição bem sucedida."
 // @Failure		400									{string}	string					"Parâmetro ano inválido."
 // @Failure		500									{string}	string					"Erro interno."
@@ -629,6 +743,10 @@ func (h handler) V2GetGeneralRemunerationFromYear(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, fmt.Sprintf("Parâmetro ano=%s inválido", c.Param("ano")))
 	}
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
 	data, err := h.client.Db.GetGeneralMonthlyInfosFromYear(year)
 	if err != nil {
 		fmt.Println("Error searching for monthly info from year: %w", err)
@@ -636,14 +754,16 @@ func (h handler) V2GetGeneralRemunerationFromYear(c echo.Context) error {
 	}
 	annualRemu := []mensalRemuneration{}
 	for _, d := range data {
+		f := corr.Month(year, d.Month)
 		annualRemu = append(annualRemu, mensalRemuneration{
 			Month:              d.Month,
 			Members:            d.Count,
-			BaseRemuneration:   d.BaseRemuneration,
-			OtherRemunerations: d.OtherRemunerations,
-			Discounts:          d.Discounts,
-			Remunerations:      d.Remunerations,
-			ItemSummary:        itemSummary(d.ItemSummary),
+			BaseRemuneration:   d.BaseRemuneration * f,
+			OtherRemunerations: d.OtherRemunerations * f,
+			Discounts:          d.Discounts * f,
+			Remunerations:      d.Remunerations * f,
+			ItemSummary:        itemSummary(ipca.Items(d.ItemSummary, f)),
+			Uncorrected:        corr.Uncorrected(year, d.Month),
 		})
 	}
 	return c.JSON(http.StatusOK, annualRemu)
@@ -741,13 +861,17 @@ func (h handler) GetGeneralSummary(c echo.Context) error {
 // @Description
 // @Description	- Filtragem por anos específicos
 // @Description	- Seleção de meses específicos
-// @Description	- Pesquisa por órgãos públicos de diferentes esferas
+// @Description	- Pesquisa por órgãos públicos de diferentes esferas, individualmente ou por grupo, UF e entidade. Os filtros de órgãos são combinados entre si (ex: grupos=justica-estadual&ufs=AL,PB retorna os tribunais estaduais de Alagoas e da Paraíba)
 // @Description	- Categorias de remuneração
+// @Description	- Nome, cargo e lotação do membro e detalhamento do contracheque (ex: auxílio-moradia), sem diferenciar maiúsculas e acentos
+// @Description	- Faixa de valores das linhas dos contracheques, com ordenação dos resultados (ex: maiores valores primeiro)
 // @Description
 // @Description	Características principais:
 // @Description	- Suporta múltiplas seleções em cada filtro
 // @Description	- Permite combinações complexas de busca
 // @Description	- Retorna dados consolidados de remuneração dos contracheques por membros
+// @Description	- Resultados paginados: o campo 'proximo' da resposta deve ser passado no parâmetro de mesmo nome para obter a página seguinte
+// @Description	- Com agrupar=membro, retorna um contracheque por membro e mês, com os totais de base, outras, descontos e o valor líquido
 // @Description
 // @Description	Casos de uso:
 // @Description	- Análise comparativa de remunerações entre diferentes órgãos
@@ -755,27 +879,39 @@ func (h handler) GetGeneralSummary(c echo.Context) error {
 // @Produce		json
 // @Param			anos		query		string			false	"Lista de anos a serem pesquisados, separados por virgula. Exemplo: 2018,2019,2020"
 // @Param			meses		query		string			false	"Lista de meses a serem pesquisados, separados por virgula. Exemplo: 1,2,3"
+// @Param			de			query		string			false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01."
+// @Param			ate			query		string			false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual."
 // @Param			orgaos		query		string			false	"Lista de órgãos a serem pesquisados, separados por virgula. Exemplo: tjal,mpal,mppb"
-// @Param			categorias	query		string			false	"Categorias a serem pesquisadas. Remuneração base (salário), outras remunerações (benefícios) e descontos"	Enums(base,outras,descontos)
+// @Param			grupos		query		string			false	"Grupos de órgãos a serem pesquisados, separados por vírgula: justica-eleitoral, ministerios-publicos, justica-estadual, justica-do-trabalho, justica-federal, justica-militar, justica-superior e conselhos-de-justica. Exemplo: justica-estadual"
+// @Param			ufs			query		string			false	"UFs dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: AL,PB,PE"
+// @Param			entidades	query		string			false	"Entidades dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: Tribunal"
+// @Param			categorias	query		string			false	"Categorias a serem pesquisadas, separadas por vírgula: base (salário), outras (benefícios) e descontos. Substitui o antigo parâmetro tipos, que não é mais aceito. Exemplo: base,outras"
+// @Param			nome		query		string			false	"Trecho do nome do membro. Exemplo: maria jose"
+// @Param			cargo		query		string			false	"Trecho do cargo do membro. Exemplo: juiz"
+// @Param			lotacao		query		string			false	"Trecho da lotação do membro. Exemplo: maceio"
+// @Param			detalhamento_contracheque	query	string	false	"Trecho do detalhamento do contracheque. Exemplo: auxilio-moradia"
+// @Param			valor_min	query		number			false	"Valor mínimo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 1000.50 ou 1.000,50"
+// @Param			valor_max	query		number			false	"Valor máximo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 50000"
+// @Param			ordenar		query		string			false	"Ordenação dos resultados no formato 'campo direção'. Campos: orgao, mes, ano, nome, cargo, lotacao, detalhamento_contracheque e valor. Direções: asc (padrão) e desc. Na ordenação por valor, as linhas com valor inválido ficam de fora. Exemplo: valor desc"
+// @Param			agrupar		query		string			false	"Agrupa as linhas dos contracheques por membro, mês e ano, somando base, outras e descontos e calculando o valor líquido. Nesse caso, o campo 'result' contém objetos memberResult e, na ordenação, 'valor' corresponde ao valor líquido"	Enums(membro)
+// @Param			proximo		query		string			false	"Cursor da próxima página, retornado no campo 'proximo' da página anterior. Os demais parâmetros devem ser os mesmos da página anterior"
 // @Success		200			{object}	searchResponse	"Requisição bem-sucedida com dados de remuneração"
 // @Failure		400			{string}	string			"Erro de validação dos parâmetros de busca"
 // @Failure		500			{string}	string			"Erro interno do servidor durante processamento da pesquisa"
 // @Router			/uiapi/v2/pesquisar [get]
 func (h handler) SearchByUrl(c echo.Context) error {
-	//Pegando os query params
-	years := c.QueryParam("anos")
-	months := c.QueryParam("meses")
-	agencies := c.QueryParam("orgaos")
-	categories := c.QueryParam("categorias")
-	types := c.QueryParam("tipos")
 	//Criando os filtros a partir dos query params e validando eles
-	searchParams, err := newSearchParams(years, months, agencies, categories, types)
+	searchParams, err := newSearchParams(c.QueryParams())
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
-	var category string
-	if searchParams != nil {
-		category = searchParams.Category
+	if err := h.resolveAgencyGroups(searchParams); err != nil {
+		log.Printf("Error resolving agency groups: %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	cursor, err := decodeSearchCursor(c.QueryParam("proximo"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
 	}
 	// Pegando os resultados da pesquisa a partir dos filtros;
 	results, err := h.db.filter(h.db.remunerationQuery(searchParams), h.db.arguments(searchParams))
@@ -783,7 +919,25 @@ func (h handler) SearchByUrl(c echo.Context) error {
 		log.Printf("Error querying BD (searchParams or counter):%q", err)
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
-	remunerations, numRows, err := h.getSearchResults(h.searchLimit, category, results)
+	if searchParams.groupByMember() {
+		members, numRows, next, err := h.remunerations.getMemberResults(h.searchLimit, searchParams, results, cursor)
+		if err != nil {
+			log.Printf("Error getting member results: %q", err)
+			return c.JSON(http.StatusInternalServerError, err.Error())
+		}
+		response := memberSearchResponse{
+			DownloadAvailable:  numRows > 0 && numRows <= h.downloadLimit,
+			NumRowsIfAvailable: numRows,
+			DownloadLimit:      h.downloadLimit,
+			SearchLimit:        h.searchLimit,
+			Results:            members,
+		}
+		if next != nil {
+			response.Next = next.encode()
+		}
+		return c.JSON(http.StatusOK, response)
+	}
+	remunerations, numRows, next, err := h.getSearchResults(h.searchLimit, searchParams, results, cursor)
 	if err != nil {
 		log.Printf("Error getting search results: %q", err)
 		return c.JSON(http.StatusInternalServerError, err.Error())
@@ -794,14 +948,17 @@ func (h handler) SearchByUrl(c echo.Context) error {
 		NumRowsIfAvailable: numRows,
 		DownloadLimit:      h.downloadLimit,
 		SearchLimit:        h.searchLimit,
-		Results:            remunerations, // retornando os SearchLimit primeiros elementos.
+		Results:            remunerations, // retornando os SearchLimit elementos a partir do cursor.
+	}
+	if next != nil {
+		response.Next = next.encode()
 	}
 	return c.JSON(http.StatusOK, response)
 }
 
 // @ID				DownloadByUrl
 // @Tags			ui_api
-// @Description	Baixa um arquivo csv referentes a remunerações a partir de filtros. O arquivo tem um limite de 10 mil linhas. Para cada parâmetro, é possível passar múltiplos valores separados por vírgula. As colunas do arquivo csv são:
+// @Description	Baixa um arquivo referente a remunerações a partir de filtros, nos formatos csv (padrão), jsonl ou parquet. Sem chave de acesso, a pesquisa pode ter no máximo 10 mil linhas: pesquisas maiores são recusadas, em vez de truncadas, e devem usar a rota de exportações. Com chave de acesso, não há limite e o arquivo é enviado à medida que os dados são lidos; se houver um erro no meio do envio, a conexão é interrompida. Para cada parâmetro, é possível passar múltiplos valores separados por vírgula. Nos formatos jsonl e parquet, mês e ano são inteiros e o valor é numérico. As colunas do arquivo são:
 // @Description
 // @Description	- Nome do órgão
 // @Description	- Mês de referência do contracheque
@@ -814,44 +971,881 @@ func (h handler) SearchByUrl(c echo.Context) error {
 // @Description	- Detalhamento do contracheque (ex: subsídio, desconto, benefício, etc)
 // @Description	- Valor do contracheque em reais, não corrigido pela inflação
 // @Produce		json
-// @Param			anos		query		string	false	"Anos a serem pesquisados, separados por virgula. Exemplo: 2018,2019,2020"
-// @Param			meses		query		string	false	"Meses a serem pesquisados, separados por virgula. Exemplo: 1,2,3"
-// @Param			orgaos		query		string	false	"Orgãos a serem pesquisados, separados por virgula. Exemplo: tjal,mpal,mppb"
-// @Param			categorias	query		string	false	"Categorias a serem pesquisadas. Se nada for informado, todas as categorias serão baixadas"	Enums(base,outras,descontos)
-// @Success		200			{file}		file	"Arquivo CSV com os dados."
-// @Failure		400			{string}	string	"Erro de validação dos parâmetros."
-// @Failure		500			{string}	string	"Erro interno do servidor."
+// @Param			anos			query		string	false	"Anos a serem pesquisados, separados por virgula. Exemplo: 2018,2019,2020"
+// @Param			meses			query		string	false	"Meses a serem pesquisados, separados por virgula. Exemplo: 1,2,3"
+// @Param			de				query		string	false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01."
+// @Param			ate				query		string	false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual."
+// @Param			orgaos			query		string	false	"Orgãos a serem pesquisados, separados por virgula. Exemplo: tjal,mpal,mppb"
+// @Param			grupos			query		string	false	"Grupos de órgãos a serem pesquisados, separados por vírgula. Exemplo: justica-estadual,ministerios-publicos"
+// @Param			ufs				query		string	false	"UFs dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: AL,PB,PE"
+// @Param			entidades		query		string	false	"Entidades dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: Tribunal"
+// @Param			categorias		query		string	false	"Categorias a serem pesquisadas, separadas por vírgula: base, outras e descontos. Se nada for informado, todas as categorias serão baixadas. Substitui o antigo parâmetro tipos, que não é mais aceito. Exemplo: base,outras"
+// @Param			nome			query		string	false	"Trecho do nome do membro, sem diferenciar maiúsculas e acentos"
+// @Param			cargo			query		string	false	"Trecho do cargo do membro, sem diferenciar maiúsculas e acentos"
+// @Param			lotacao			query		string	false	"Trecho da lotação do membro, sem diferenciar maiúsculas e acentos"
+// @Param			detalhamento_contracheque	query	string	false	"Trecho do detalhamento do contracheque, sem diferenciar maiúsculas e acentos. Exemplo: auxilio-moradia"
+// @Param			valor_min		query		number	false	"Valor mínimo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 1000.50 ou 1.000,50"
+// @Param			valor_max		query		number	false	"Valor máximo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 50000"
+// @Param			ordenar			query		string	false	"Ordenação das linhas no formato 'campo direção'. Campos: orgao, mes, ano, nome, cargo, lotacao, detalhamento_contracheque e valor. Direções: asc (padrão) e desc. Na ordenação por valor, as linhas com valor inválido ficam de fora. Com ordenação, o arquivo só começa a ser enviado depois que todos os dados forem lidos"
+// @Param			agrupar			query		string	false	"Agrupa as linhas por membro, mês e ano. As colunas passam a ser orgao, mes, ano, matricula, nome, cargo, lotacao, base, outras, descontos e liquido"	Enums(membro)
+// @Param			formato			query		string	false	"Formato do arquivo. Se nada for informado, o arquivo será gerado em csv"	Enums(csv,jsonl,parquet)
+// @Param			Authorization	header		string	false	"Chave de acesso no formato 'Bearer <chave>'. Remove o limite de linhas do arquivo"
+// @Success		200				{file}		file	"Arquivo com os dados no formato pedido. Sem chave de acesso, o cabeçalho X-Total-Rows informa a quantidade de linhas."
+// @Failure		400				{string}	string	"Erro de validação dos parâmetros ou, sem chave de acesso, pesquisa com mais linhas que o limite do download."
+// @Failure		500				{string}	string	"Erro interno do servidor."
 // @Router			/uiapi/v2/download [get]
 func (h handler) DownloadByUrl(c echo.Context) error {
-	//Pegando os query params
-	years := c.QueryParam("anos")
-	months := c.QueryParam("meses")
-	agencies := c.QueryParam("orgaos")
-	categories := c.QueryParam("categorias")
-	types := c.QueryParam("tipos")
-
 	//Criando os filtros a partir dos query params e validando eles
-	searchParams, err := newSearchParams(years, months, agencies, categories, types)
+	searchParams, err := newSearchParams(c.QueryParams())
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
+	if err := h.resolveAgencyGroups(searchParams); err != nil {
+		log.Printf("Error resolving agency groups: %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	formatName := c.QueryParam("formato")
+	if formatName == "" {
+		formatName = "csv"
+	}
+	format, ok := downloadFormats[formatName]
+	if !ok {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("formato inválido: '%s'. Os formatos aceitos são csv, jsonl e parquet", formatName))
+	}
 
 	results, err := h.db.filter(h.db.remunerationQuery(searchParams), h.db.arguments(searchParams))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
-	searchResults, _, err := h.getSearchResults(h.downloadLimit, searchParams.Category, results)
+
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=dadosjusbr-remuneracoes.%s", format.Extension))
+	// Requisições autenticadas não possuem limite de linhas.
+	if !h.isAuthenticated(c) {
+		buf, rows, err := h.bufferSearchResults(c.Request().Context(), format, h.downloadLimit, searchParams, results)
+		if errors.Is(err, errDownloadTooLarge) {
+			c.Response().Header().Del("Content-Disposition")
+			return c.JSON(http.StatusBadRequest, fmt.Sprintf("a pesquisa tem mais de %d linhas, o limite do download sem chave de acesso. Use uma chave de acesso ou a rota /uiapi/v2/exportacoes.", h.downloadLimit))
+		}
+		if err != nil {
+			c.Response().Header().Del("Content-Disposition")
+			log.Printf("[download] error writing %s: %q", format.Extension, err)
+			return c.JSON(http.StatusInternalServerError, "erro ao gerar o arquivo")
+		}
+		c.Response().Header().Set(totalRowsHeader, strconv.Itoa(rows))
+		return c.Stream(http.StatusOK, format.ContentType, buf)
+	}
+
+	c.Response().Header().Set("Content-Type", format.ContentType)
+	// As linhas são escritas em lotes, para que o cliente receba os dados à medida que eles são lidos.
+	_, err = h.writeSearchResults(c.Request().Context(), c.Response(), format, 0, searchParams, results, c.Response().Flush)
+	if err != nil {
+		// Neste ponto o cabeçalho da resposta já foi enviado. A conexão é
+		// interrompida para que o cliente não receba um arquivo truncado
+		// como se estivesse completo.
+		log.Printf("[download] error streaming %s: %q", format.Extension, err)
+		panic(http.ErrAbortHandler)
+	}
+	return nil
+}
+
+// Cabeçalho com a quantidade de linhas do arquivo baixado.
+const totalRowsHeader = "X-Total-Rows"
+
+// errDownloadTooLarge indica que a pesquisa tem mais linhas que o limite do
+// download.
+var errDownloadTooLarge = errors.New("search has more rows than the download limit")
+
+// bufferSearchResults gera o arquivo da pesquisa em memória, com no máximo
+// limit linhas. Uma linha a mais é lida para que uma pesquisa maior que o
+// limite retorne errDownloadTooLarge em vez de um arquivo truncado. Retorna a
+// quantidade de linhas do arquivo.
+func (h handler) bufferSearchResults(ctx context.Context, format downloadFormat, limit int, params *searchParams, results []searchDetails) (*bytes.Buffer, int, error) {
+	buf := new(bytes.Buffer)
+	rows, err := h.writeSearchResults(ctx, buf, format, limit+1, params, results, func() {})
+	if err != nil {
+		return nil, 0, err
+	}
+	if rows > limit {
+		return nil, 0, errDownloadTooLarge
+	}
+	return buf, rows, nil
+}
+
+// @ID				GetMemberByRegistration
+// @Tags			ui_api
+// @Description	Retorna, mês a mês, os totais de remuneração base, outras remunerações e descontos de um membro, identificado pelo órgão e pela matrícula, em todos os meses disponíveis. Os valores não são corrigidos pela inflação.
+// @Produce		json
+// @Param			orgao		path		string				true	"ID do órgão. Exemplo: tjal"
+// @Param			matricula	path		string				true	"Matrícula do membro no órgão"
+// @Success		200			{object}	memberTimeSeries	"Totais mensais do membro"
+// @Failure		404			{string}	string				"Membro não encontrado"
+// @Failure		500			{string}	string				"Erro interno do servidor"
+// @Router			/uiapi/v2/membro/{orgao}/{matricula} [get]
+func (h handler) GetMemberByRegistration(c echo.Context) error {
+	agency := strings.ToLower(c.Param("orgao"))
+	registration := strings.TrimSpace(c.Param("matricula"))
+	rows, err := h.getMemberRemunerations(c.Request().Context(), agency, func(rem searchResult) bool {
+		return rem.Matricula != nil && strings.TrimSpace(*rem.Matricula) == registration
+	})
+	if err != nil {
+		log.Printf("Error getting member remunerations: %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	series, err := newMemberTimeSeries(rows)
 	if err != nil {
+		log.Printf("Error building member time series: %q", err)
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
+	if len(series) == 0 {
+		return c.JSON(http.StatusNotFound, fmt.Sprintf("membro com matrícula '%s' não encontrado no órgão '%s'", registration, agency))
+	}
+	return c.JSON(http.StatusOK, series[0])
+}
 
-	c.Response().Header().Set("Content-Disposition", "attachment; filename=dadosjusbr-remuneracoes.csv")
-	c.Response().Header().Set("Content-Type", c.Response().Header().Get("Content-Type"))
-	err = gocsv.Marshal(searchResults, c.Response().Writer)
+// @ID				GetMembersByName
+// @Tags			ui_api
+// @Description	Retorna, mês a mês, os totais de remuneração base, outras remunerações e descontos dos membros de um órgão com o nome informado, em todos os meses disponíveis. O nome é comparado sem diferenciar maiúsculas e acentos. Como pode haver homônimos, é retornada uma série por matrícula. Os valores não são corrigidos pela inflação.
+// @Produce		json
+// @Param			orgao	path		string				true	"ID do órgão. Exemplo: tjal"
+// @Param			nome	query		string				true	"Nome completo do membro. Exemplo: maria jose da silva"
+// @Success		200		{array}		memberTimeSeries	"Totais mensais dos membros"
+// @Failure		400		{string}	string				"Parâmetro nome não informado"
+// @Failure		404		{string}	string				"Membro não encontrado"
+// @Failure		500		{string}	string				"Erro interno do servidor"
+// @Router			/uiapi/v2/membro/{orgao} [get]
+func (h handler) GetMembersByName(c echo.Context) error {
+	agency := strings.ToLower(c.Param("orgao"))
+	name := normalizeName(c.QueryParam("nome"))
+	if name == "" {
+		return c.JSON(http.StatusBadRequest, "parâmetro nome é obrigatório!")
+	}
+	rows, err := h.getMemberRemunerations(c.Request().Context(), agency, func(rem searchResult) bool {
+		return normalizeName(rem.Nome) == name
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, fmt.Errorf("erro tentando fazer download do csv: %q", err))
+		log.Printf("Error getting member remunerations: %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
-	return nil
+	series, err := newMemberTimeSeries(rows)
+	if err != nil {
+		log.Printf("Error building member time series: %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	if len(series) == 0 {
+		return c.JSON(http.StatusNotFound, fmt.Sprintf("membro '%s' não encontrado no órgão '%s'", c.QueryParam("nome"), agency))
+	}
+	return c.JSON(http.StatusOK, series)
+}
+
+// @ID				GetItems
+// @Tags			ui_api
+// @Description	Lista as rubricas encontradas no resumo dos contracheques (campo resumo_rubricas das rotas de totais e resumos) de todos os órgãos e anos com dados. As rubricas conhecidas têm descrição.
+// @Produce		json
+// @Success		200	{array}		itemDescription	"Rubricas encontradas nos dados"
+// @Failure		500	{string}	string			"Erro interno do servidor"
+// @Router			/uiapi/v2/rubricas [get]
+func (h handler) GetItems(c echo.Context) error {
+	monthlyInfos, err := h.itemMonthlyInfos(nil)
+	if err != nil {
+		log.Printf("[items] %q", err)
+		return c.JSON(http.StatusInternalServerError, "erro ao buscar os dados mensais dos órgãos")
+	}
+	return c.JSON(http.StatusOK, newItemCatalog(monthlyInfos))
+}
+
+// @ID				GetItemTimeSeries
+// @Tags			ui_api
+// @Description	Retorna os totais mensais de uma rubrica em cada órgão. Sem filtros, considera todos os órgãos em todos os anos com dados. Os valores não são corrigidos pela inflação. Os totais vêm do resumo dos contracheques, por isso os filtros das linhas da pesquisa (categorias, nome, cargo, lotacao, detalhamento_contracheque, valor_min, valor_max, ordenar e agrupar) não são aceitos.
+// @Produce		json
+// @Param			rubrica	path		string			true	"Rubrica, como listada em /uiapi/v2/rubricas. Exemplo: auxilio_saude"
+// @Param			anos	query		string			false	"Anos a serem considerados, separados por vírgula. Exemplo: 2022,2023"
+// @Param			meses	query		string			false	"Meses a serem considerados, separados por vírgula. Exemplo: 1,2,3"
+// @Param			de		query		string			false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01."
+// @Param			ate		query		string			false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual."
+// @Param			grupos	query		string			false	"Grupos de órgãos a serem considerados, separados por vírgula. Exemplo: justica-estadual,ministerios-publicos"
+// @Param			ufs		query		string			false	"UFs dos órgãos a serem considerados, separadas por vírgula. Exemplo: AL,PB"
+// @Param			entidades	query	string			false	"Entidades dos órgãos a serem consideradas, separadas por vírgula. Exemplo: Tribunal"
+// @Param			orgaos	query		string			false	"Órgãos a serem considerados, separados por vírgula. Exemplo: tjal,mpal"
+// @Success		200		{object}	itemTimeSeries	"Totais mensais da rubrica por órgão"
+// @Failure		400		{string}	string			"Parâmetros inválidos ou não aceitos pela rota"
+// @Failure		404		{string}	string			"Rubrica não encontrada"
+// @Failure		500		{string}	string			"Erro interno do servidor"
+// @Router			/uiapi/v2/rubricas/{rubrica} [get]
+func (h handler) GetItemTimeSeries(c echo.Context) error {
+	// Os totais vêm do resumo dos contracheques, então só os filtros de meses e
+	// de órgãos podem ser aplicados.
+	if err := rejectRowParams(c.QueryParams()); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	params, err := newSearchParams(c.QueryParams())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := h.resolveAgencyGroups(params); err != nil {
+		log.Printf("Error resolving agency groups: %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	monthlyInfos, err := h.itemMonthlyInfos(params)
+	if err != nil {
+		log.Printf("[item time series] %q", err)
+		return c.JSON(http.StatusInternalServerError, "erro ao buscar os dados mensais dos órgãos")
+	}
+	item, ok := findItem(c.Param("rubrica"), monthlyInfos)
+	if !ok {
+		return c.JSON(http.StatusNotFound, fmt.Sprintf("rubrica '%s' não encontrada", c.Param("rubrica")))
+	}
+	return c.JSON(http.StatusOK, itemTimeSeries{
+		Item:        item.Item,
+		Description: item.Description,
+		Agencies:    newItemAgencyTotals(item.Item, monthlyInfos),
+	})
+}
+
+// itemMonthlyInfos retorna os dados mensais dos órgãos e meses que atendem aos
+// filtros. Sem filtros, considera todos os órgãos em todos os anos com dados.
+func (h handler) itemMonthlyInfos(params *searchParams) (map[string][]strModels.AgencyMonthlyInfo, error) {
+	var agencies []strModels.Agency
+	if params == nil || params.Agencies == nil {
+		all, err := h.client.Db.GetAllAgencies()
+		if err != nil {
+			return nil, fmt.Errorf("error getting agencies: %w", err)
+		}
+		agencies = all
+	} else {
+		for _, a := range params.Agencies {
+			agencies = append(agencies, strModels.Agency{ID: a})
+		}
+	}
+	years := params.years()
+	if len(years) == 0 {
+		_, first, err := h.client.Db.GetFirstDateWithMonthlyInfo()
+		if err != nil {
+			return nil, fmt.Errorf("error getting first date with monthly info: %w", err)
+		}
+		_, last, err := h.client.Db.GetLastDateWithMonthlyInfo()
+		if err != nil {
+			return nil, fmt.Errorf("error getting last date with monthly info: %w", err)
+		}
+		for y := first; y <= last; y++ {
+			years = append(years, y)
+		}
+	}
+	monthlyInfos := map[string][]strModels.AgencyMonthlyInfo{}
+	if len(agencies) == 0 {
+		return monthlyInfos, nil
+	}
+	for _, y := range years {
+		infos, err := h.client.Db.GetMonthlyInfo(agencies, y)
+		if err != nil {
+			return nil, fmt.Errorf("error getting monthly info (ano:%d): %w", y, err)
+		}
+		for aID, mis := range infos {
+			for _, mi := range mis {
+				if params.matchMonth(mi.Year, mi.Month) {
+					monthlyInfos[aID] = append(monthlyInfos[aID], mi)
+				}
+			}
+		}
+	}
+	return monthlyInfos, nil
+}
+
+// @ID				CompareAgencies
+// @Tags			ui_api
+// @Description	Compara os órgãos lado a lado. Retorna as séries mensais de totais e valores por membro de cada órgão, alinhadas pelos mesmos meses (null nos meses em que o órgão não tem dados), e a posição de cada órgão em cada mês e no período, pela remuneração líquida (salário + benefícios - descontos) por membro, da maior para a menor.
+// @Produce		json
+// @Param			orgaos		query		string				false	"Órgãos a serem comparados, separados por vírgula. Exemplo: tjal,tjpe,tjba"
+// @Param			grupos		query		string				false	"Grupos de órgãos a serem comparados, separados por vírgula. Exemplo: justica-estadual"
+// @Param			ufs			query		string				false	"UFs dos órgãos a serem comparados, separadas por vírgula. Exemplo: AL,PE,BA"
+// @Param			entidades	query		string				false	"Entidades dos órgãos a serem comparados, separadas por vírgula. Exemplo: Tribunal"
+// @Param			anos		query		string				false	"Anos a serem considerados, separados por vírgula. Exemplo: 2022,2023. Obrigatório se de e ate não forem informados."
+// @Param			meses		query		string				false	"Meses a serem considerados, separados por vírgula. Exemplo: 1,2,3"
+// @Param			de			query		string				false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01."
+// @Param			ate			query		string				false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual."
+// @Param			corrigir	query		string				false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao."
+// @Param			base		query		string				false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
+// @Success		200			{object}	agencyComparison	"Séries mensais e posições dos órgãos"
+// @Failure		400			{string}	string				"Parâmetros inválidos"
+// @Failure		500			{string}	string				"Erro interno do servidor"
+// @Router			/uiapi/v2/comparar [get]
+func (h handler) CompareAgencies(c echo.Context) error {
+	params, err := newSearchParams(c.QueryParams())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if len(params.years()) == 0 {
+		return c.JSON(http.StatusBadRequest, "parâmetro anos ou intervalo de/ate é obrigatório!")
+	}
+	if params.Agencies == nil && len(params.Groups) == 0 && len(params.UFs) == 0 && len(params.Entities) == 0 {
+		return c.JSON(http.StatusBadRequest, "informe ao menos um dos parâmetros orgaos, grupos, ufs ou entidades!")
+	}
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err := h.resolveAgencyGroups(params); err != nil {
+		log.Printf("Error resolving agency groups: %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	// Sem grupos, ufs ou entidades, os órgãos mantêm a ordem em que foram informados.
+	var agencies []string
+	for _, a := range params.Agencies {
+		if !contains(agencies, a) {
+			agencies = append(agencies, a)
+		}
+	}
+
+	monthlyInfos := map[string][]strModels.AgencyMonthlyInfo{}
+	if len(agencies) > 0 {
+		var strAgencies []strModels.Agency
+		for _, a := range agencies {
+			strAgencies = append(strAgencies, strModels.Agency{ID: a})
+		}
+		for _, year := range params.years() {
+			infos, err := h.client.Db.GetMonthlyInfo(strAgencies, year)
+			if err != nil {
+				log.Printf("[compare agencies] error getting monthly info (ano:%d): %q", year, err)
+				return c.JSON(http.StatusInternalServerError, "erro ao buscar os dados mensais dos órgãos")
+			}
+			for aID, mis := range infos {
+				monthlyInfos[aID] = append(monthlyInfos[aID], mis...)
+			}
+		}
+	}
+	return c.JSON(http.StatusOK, newAgencyComparison(agencies, monthlyInfos, params, corr))
+}
+
+// @ID				GetRanking
+// @Tags			ui_api
+// @Description	Classifica os órgãos por uma métrica no período informado. O valor de cada órgão é a média mensal da métrica nos meses com dados. Também retorna a posição e o valor de cada órgão no período anterior, de mesma duração, e a variação entre os dois. Por exemplo, o período anterior a 2023 é 2022 e o anterior a abril a junho de 2023 é janeiro a março de 2023.
+// @Description
+// @Description	Métricas:
+// @Description	- remuneracao: remuneração líquida (salário + benefícios - descontos) por membro
+// @Description	- remuneracao_base: remuneração base (salário) por membro
+// @Description	- outras: outras remunerações (benefícios) por membro
+// @Description	- descontos: descontos por membro
+// @Description	- rubrica: valor da rubrica informada por membro
+// @Description	- indice: índice de transparência
+// @Produce		json
+// @Param			metrica		query		string			false	"Métrica usada na classificação. Padrão: remuneracao"	Enums(remuneracao, remuneracao_base, outras, descontos, rubrica, indice)
+// @Param			rubrica		query		string			false	"Rubrica usada com metrica=rubrica. Exemplo: auxilio_saude"
+// @Param			anos		query		string			false	"Anos do período, separados por vírgula. Exemplo: 2023. Obrigatório se de e ate não forem informados."
+// @Param			meses		query		string			false	"Meses do período, separados por vírgula. Exemplo: 4,5,6"
+// @Param			de			query		string			false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01."
+// @Param			ate			query		string			false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual."
+// @Param			orgaos		query		string			false	"Órgãos a serem classificados, separados por vírgula. Sem filtros, todos os órgãos são classificados."
+// @Param			grupos		query		string			false	"Grupos de órgãos a serem classificados, separados por vírgula. Exemplo: justica-estadual"
+// @Param			ufs			query		string			false	"UFs dos órgãos a serem classificados, separadas por vírgula. Exemplo: AL,PE"
+// @Param			entidades	query		string			false	"Entidades dos órgãos a serem classificados, separadas por vírgula. Exemplo: Tribunal"
+// @Param			ordem		query		string			false	"Direção da classificação. Padrão: desc"	Enums(asc, desc)
+// @Param			limite		query		int				false	"Quantidade de órgãos retornados. Sem ele, todos os órgãos são retornados."
+// @Param			corrigir	query		string			false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao."
+// @Param			base		query		string			false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
+// @Success		200			{object}	agencyRanking	"Ranking dos órgãos"
+// @Failure		400			{string}	string			"Parâmetros inválidos"
+// @Failure		500			{string}	string			"Erro interno do servidor"
+// @Router			/uiapi/v2/ranking [get]
+func (h handler) GetRanking(c echo.Context) error {
+	metric := c.QueryParam("metrica")
+	if metric == "" {
+		metric = "remuneracao"
+	}
+	if !rankingMetrics[metric] {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("parâmetro metrica '%s' é inválido!", metric))
+	}
+	item := c.QueryParam("rubrica")
+	if metric == "rubrica" {
+		if item == "" {
+			return c.JSON(http.StatusBadRequest, "parâmetro rubrica é obrigatório com metrica=rubrica!")
+		}
+	} else if item != "" {
+		return c.JSON(http.StatusBadRequest, "parâmetro rubrica só pode ser usado com metrica=rubrica!")
+	}
+	order := c.QueryParam("ordem")
+	if order == "" {
+		order = "desc"
+	}
+	if order != "asc" && order != "desc" {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("parâmetro ordem '%s' é inválido!", order))
+	}
+	limit := 0
+	if l := c.QueryParam("limite"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil || limit <= 0 {
+			return c.JSON(http.StatusBadRequest, fmt.Sprintf("parâmetro limite '%s' é inválido!", l))
+		}
+	}
+	params, err := newSearchParams(c.QueryParams())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if len(params.years()) == 0 {
+		return c.JSON(http.StatusBadRequest, "parâmetro anos ou intervalo de/ate é obrigatório!")
+	}
+	if metric == "indice" && c.QueryParam("corrigir") != "" {
+		return c.JSON(http.StatusBadRequest, "parâmetro corrigir não pode ser usado com metrica=indice!")
+	}
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	period := rankingPeriod(params)
+	if len(period) == 0 {
+		return c.JSON(http.StatusBadRequest, "o período informado não tem nenhum mês!")
+	}
+	previous := previousPeriod(period)
+	if err := h.resolveAgencyGroups(params); err != nil {
+		log.Printf("Error resolving agency groups: %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	var agencies []strModels.Agency
+	if params.Agencies == nil {
+		agencies, err = h.client.Db.GetAllAgencies()
+		if err != nil {
+			log.Printf("[ranking] error getting agencies: %q", err)
+			return c.JSON(http.StatusInternalServerError, "erro ao buscar os órgãos")
+		}
+	} else {
+		for _, a := range params.Agencies {
+			agencies = append(agencies, strModels.Agency{ID: a})
+		}
+	}
+
+	monthlyInfos := map[string][]strModels.AgencyMonthlyInfo{}
+	if len(agencies) > 0 {
+		years := map[int]bool{}
+		for i := range period {
+			years[period[i].Year] = true
+			years[previous[i].Year] = true
+		}
+		for y := previous[0].Year; y <= period[len(period)-1].Year; y++ {
+			if !years[y] {
+				continue
+			}
+			infos, err := h.client.Db.GetMonthlyInfo(agencies, y)
+			if err != nil {
+				log.Printf("[ranking] error getting monthly info (ano:%d): %q", y, err)
+				return c.JSON(http.StatusInternalServerError, "erro ao buscar os dados mensais dos órgãos")
+			}
+			for aID, mis := range infos {
+				monthlyInfos[aID] = append(monthlyInfos[aID], mis...)
+			}
+		}
+	}
+	// As rubricas válidas são as conhecidas e as encontradas nos dados.
+	if _, ok := findItem(item, monthlyInfos); metric == "rubrica" && !ok {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("parâmetro rubrica '%s' é inválido!", item))
+	}
+	return c.JSON(http.StatusOK, agencyRanking{
+		Metric:         metric,
+		Item:           item,
+		Order:          order,
+		Period:         periodRange{Start: period[0].String(), End: period[len(period)-1].String()},
+		PreviousPeriod: periodRange{Start: previous[0].String(), End: previous[len(previous)-1].String()},
+		Positions:      newRankingPositions(metric, item, monthlyInfos, period, previous, order == "desc", limit, corr),
+		Correction:     corr,
+	})
+}
+
+// @ID				GetRemunerationDistribution
+// @Tags			ui_api
+// @Description	Calcula a distribuição dos valores dos contracheques dos membros de um órgão em um mês, a partir das linhas dos arquivos de remunerações: percentis (p10, p25, p50, p75, p90 e p99), média, mediana, mínimo, máximo, coeficiente de Gini e um histograma com faixas configuráveis. Os valores de cada membro são a soma das linhas do seu contracheque.
+// @Description
+// @Description	As faixas do histograma incluem o limite inferior e excluem o superior. A primeira faixa tem os valores abaixo do primeiro limite e a última, os valores a partir do último limite. Por padrão, os limites vão de R$ 0,00 a R$ 100.000,00, de R$ 10.000,00 em R$ 10.000,00.
+// @Produce		json
+// @Param			orgao		path		string						true	"Sigla do órgão. Ex.: tjal, mppb, tjmmg"
+// @Param			ano			path		int							true	"Ano de referência"
+// @Param			mes			path		int							true	"Mês de referência (1-12)"
+// @Param			valor		query		string						false	"Valor do contracheque usado na distribuição. Padrão: liquido (salário + benefícios - descontos)"	Enums(liquido, base, outras, descontos)
+// @Param			faixas		query		string						false	"Limites das faixas do histograma, em ordem crescente e separados por vírgula. Exemplo: 0,25000,50000,75000,100000"
+// @Param			corrigir	query		string						false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao. As faixas se referem aos valores já corrigidos."
+// @Param			base		query		string						false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
+// @Success		200			{object}	remunerationDistribution	"Distribuição dos valores dos contracheques"
+// @Failure		400			{string}	string						"Parâmetros inválidos"
+// @Failure		404			{string}	string						"Não existem dados para os parâmetros informados"
+// @Failure		500			{string}	string						"Erro interno do servidor"
+// @Router			/uiapi/v2/distribuicao/{orgao}/{ano}/{mes} [get]
+func (h handler) GetRemunerationDistribution(c echo.Context) error {
+	year, err := strconv.Atoi(c.Param("ano"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("Parâmetro ano=%s inválido", c.Param("ano")))
+	}
+	month, err := strconv.Atoi(c.Param("mes"))
+	if err != nil || month < 1 || month > 12 {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("Parâmetro mês=%s inválido", c.Param("mes")))
+	}
+	agency := strings.ToLower(c.Param("orgao"))
+	valueName := c.QueryParam("valor")
+	if valueName == "" {
+		valueName = "liquido"
+	}
+	value, ok := distributionValues[valueName]
+	if !ok {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("parâmetro valor '%s' é inválido!", valueName))
+	}
+	edges, err := parseBinEdges(c.QueryParam("faixas"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	params := &searchParams{Years: []string{strconv.Itoa(year)}, Months: []string{strconv.Itoa(month)}, Agencies: []string{agency}}
+	results, err := h.db.filter(h.db.remunerationQuery(params), h.db.arguments(params))
+	if err != nil {
+		log.Printf("Error querying BD (distribution): %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	if len(results) == 0 {
+		return c.JSON(http.StatusNotFound, "Não existem dados para os parâmetros informados")
+	}
+	values, err := h.memberValues(c.Request().Context(), results, value, corr.Month(year, month))
+	if err != nil {
+		log.Printf("Error reading remunerations (distribution): %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	distribution := newDistribution(values, edges)
+	distribution.AgencyID = agency
+	distribution.Year = year
+	distribution.Month = month
+	distribution.Value = valueName
+	distribution.Correction = corr
+	distribution.Uncorrected = corr.Uncorrected(year, month)
+	return c.JSON(http.StatusOK, distribution)
+}
+
+// @ID				GetAnomalies
+// @Tags			ui_api
+// @Description	Lista as anomalias dos totais mensais dos órgãos no período, calculadas pela API. Cada mês é comparado com a mediana dos 6 meses anteriores com dados do mesmo órgão (são necessários ao menos 3). Os sinais são:
+// @Description	- remuneracoes: o total de remunerações se afasta mais de 50% da mediana
+// @Description	- num_membros: a quantidade de membros se afasta mais de 30% da mediana
+// @Description	- rubrica: uma rubrica atinge três vezes a sua mediana ou, se não aparecia nos meses anteriores, passa a representar 10% das remunerações (salário + benefícios) do mês
+// @Description
+// @Description	As anomalias são ordenadas por ano, mês e órgão. Os valores são nominais.
+// @Produce		json
+// @Param			anos		query		string		false	"Anos a serem considerados, separados por vírgula. Exemplo: 2023,2024. Obrigatório se de e ate não forem informados."
+// @Param			meses		query		string		false	"Meses a serem considerados, separados por vírgula. Exemplo: 1,2,3"
+// @Param			de			query		string		false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01."
+// @Param			ate			query		string		false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual."
+// @Param			orgaos		query		string		false	"Órgãos a serem considerados, separados por vírgula. Sem filtros, todos os órgãos são considerados."
+// @Param			grupos		query		string		false	"Grupos de órgãos a serem considerados, separados por vírgula. Exemplo: justica-estadual"
+// @Param			ufs			query		string		false	"UFs dos órgãos a serem considerados, separadas por vírgula. Exemplo: AL,PB"
+// @Param			entidades	query		string		false	"Entidades dos órgãos a serem consideradas, separadas por vírgula. Exemplo: Tribunal"
+// @Success		200			{array}		anomaly		"Anomalias encontradas"
+// @Failure		400			{string}	string		"Parâmetros inválidos"
+// @Failure		500			{string}	string		"Erro interno do servidor"
+// @Router			/uiapi/v2/anomalias [get]
+func (h handler) GetAnomalies(c echo.Context) error {
+	params, err := newSearchParams(c.QueryParams())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if len(params.years()) == 0 {
+		return c.JSON(http.StatusBadRequest, "parâmetro anos ou intervalo de/ate é obrigatório!")
+	}
+	if err := h.resolveAgencyGroups(params); err != nil {
+		log.Printf("Error resolving agency groups: %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	var agencies []strModels.Agency
+	if params.Agencies == nil {
+		agencies, err = h.client.Db.GetAllAgencies()
+		if err != nil {
+			log.Printf("[anomalies] error getting agencies: %q", err)
+			return c.JSON(http.StatusInternalServerError, "erro ao buscar os órgãos")
+		}
+	} else {
+		for _, a := range params.Agencies {
+			agencies = append(agencies, strModels.Agency{ID: a})
+		}
+	}
+	period := map[yearMonth]bool{}
+	for _, ym := range rankingPeriod(params) {
+		period[ym] = true
+	}
+
+	found := []anomaly{}
+	if len(agencies) > 0 {
+		// O ano anterior a cada ano serve de histórico para as anomalias do
+		// início do ano, mesmo quando os anos pedidos não são consecutivos.
+		years := map[int]bool{}
+		for _, year := range params.years() {
+			years[year] = true
+			years[year-1] = true
+		}
+		monthlyInfos := map[string][]strModels.AgencyMonthlyInfo{}
+		for y := range years {
+			infos, err := h.client.Db.GetMonthlyInfo(agencies, y)
+			if err != nil {
+				log.Printf("[anomalies] error getting monthly info (ano:%d): %q", y, err)
+				return c.JSON(http.StatusInternalServerError, "erro ao buscar os dados mensais dos órgãos")
+			}
+			for aID, mis := range infos {
+				monthlyInfos[aID] = append(monthlyInfos[aID], mis...)
+			}
+		}
+		for _, mis := range monthlyInfos {
+			for ym, anomalies := range detectAnomalies(mis) {
+				if period[ym] {
+					found = append(found, anomalies...)
+				}
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Year != found[j].Year {
+			return found[i].Year < found[j].Year
+		}
+		if found[i].Month != found[j].Month {
+			return found[i].Month < found[j].Month
+		}
+		return found[i].AgencyID < found[j].AgencyID
+	})
+	return c.JSON(http.StatusOK, found)
+}
+
+// @ID				GetTetoTable
+// @Tags			ui_api
+// @Description	Retorna a tabela de valores do teto constitucional (subsídio dos ministros do STF) usada no cálculo dos membros acima do teto, com o início da vigência de cada valor.
+// @Produce		json
+// @Success		200	{object}	tetoTableResponse	"Tabela do teto constitucional"
+// @Router			/uiapi/v2/teto [get]
+func (h handler) GetTetoTable(c echo.Context) error {
+	return c.JSON(http.StatusOK, tetoTableResponse{Version: tetoTableVersion, Values: tetoTable})
+}
+
+// @ID				GetTetoBreaches
+// @Tags			ui_api
+// @Description	Retorna, para cada órgão e mês, a quantidade e o percentual de membros com remuneração líquida (salário + benefícios - descontos) acima do teto constitucional vigente no mês, e a soma do que excedeu o teto. Também retorna os totais do período. É obrigatório informar os anos e ao menos um filtro de órgãos.
+// @Produce		json
+// @Param			anos		query		string					false	"Anos a serem considerados, separados por vírgula. Exemplo: 2023,2024. Obrigatório se de e ate não forem informados."
+// @Param			meses		query		string					false	"Meses a serem considerados, separados por vírgula. Exemplo: 1,2,3"
+// @Param			de			query		string					false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01."
+// @Param			ate			query		string					false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual."
+// @Param			orgaos		query		string					false	"Órgãos a serem considerados, separados por vírgula. Exemplo: tjal,mpal"
+// @Param			grupos		query		string					false	"Grupos de órgãos a serem considerados, separados por vírgula. Exemplo: justica-estadual"
+// @Param			ufs			query		string					false	"UFs dos órgãos a serem considerados, separadas por vírgula. Exemplo: AL,PB"
+// @Param			entidades	query		string					false	"Entidades dos órgãos a serem consideradas, separadas por vírgula. Exemplo: Tribunal"
+// @Success		200			{object}	tetoBreachesResponse	"Membros acima do teto por órgão e mês"
+// @Failure		400			{string}	string					"Parâmetros inválidos"
+// @Failure		500			{string}	string					"Erro interno do servidor"
+// @Router			/uiapi/v2/teto/excedentes [get]
+func (h handler) GetTetoBreaches(c echo.Context) error {
+	params, err := newSearchParams(c.QueryParams())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if len(params.years()) == 0 {
+		return c.JSON(http.StatusBadRequest, "parâmetro anos ou intervalo de/ate é obrigatório!")
+	}
+	if params.Agencies == nil && len(params.Groups) == 0 && len(params.UFs) == 0 && len(params.Entities) == 0 {
+		return c.JSON(http.StatusBadRequest, "informe ao menos um dos parâmetros orgaos, grupos, ufs ou entidades!")
+	}
+	if err := h.resolveAgencyGroups(params); err != nil {
+		log.Printf("Error resolving agency groups: %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	// Os demais filtros da pesquisa não se aplicam ao teto.
+	params = &searchParams{Years: params.Years, Months: params.Months, Range: params.Range, Agencies: params.Agencies}
+	results, err := h.db.filter(h.db.remunerationQuery(params), h.db.arguments(params))
+	if err != nil {
+		log.Printf("Error querying BD (teto breaches): %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	// Os resumos mensais permitem pular os meses em que ninguém passou do teto.
+	agenciesByYear := map[int][]strModels.Agency{}
+	seen := map[string]bool{}
+	for _, r := range results {
+		if key := fmt.Sprintf("%s/%d", r.Orgao, r.Ano); !seen[key] {
+			seen[key] = true
+			agenciesByYear[r.Ano] = append(agenciesByYear[r.Ano], strModels.Agency{ID: r.Orgao})
+		}
+	}
+	summaries := map[string]*strModels.Summary{}
+	for year, agencies := range agenciesByYear {
+		monthlyInfos, err := h.client.Db.GetMonthlyInfo(agencies, year)
+		if err != nil {
+			log.Printf("[teto breaches] error getting monthly info (ano:%d): %q", year, err)
+			return c.JSON(http.StatusInternalServerError, "erro ao buscar os dados mensais dos órgãos")
+		}
+		for aID, mis := range monthlyInfos {
+			for _, mi := range mis {
+				summaries[monthlySummaryKey(aID, mi.Year, mi.Month)] = mi.Summary
+			}
+		}
+	}
+
+	months, err := h.tetoBreaches(c.Request().Context(), results, summaries)
+	if err != nil {
+		log.Printf("Error calculating teto breaches: %q", err)
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, tetoBreachesResponse{
+		TableVersion: tetoTableVersion,
+		Totals:       newTetoBreachTotals(months),
+		Months:       months,
+	})
+}
+
+// @ID				CreateExport
+// @Tags			ui_api
+// @Description	Cria uma exportação assíncrona de remunerações, para arquivos grandes demais para a rota de download. Aceita os mesmos parâmetros da rota /uiapi/v2/download, mas o arquivo não tem limite de linhas, mesmo sem chave de acesso. O arquivo é gerado em segundo plano e a situação da exportação deve ser consultada na rota /uiapi/v2/exportacoes/{id} até que ela seja concluída. Cada cliente pode ter poucas exportações em andamento ao mesmo tempo, e as exportações e seus arquivos são apagados depois de um tempo, informado no campo expira_em.
+// @Produce		json
+// @Param			anos			query		string		false	"Anos a serem pesquisados, separados por virgula. Exemplo: 2018,2019,2020"
+// @Param			meses			query		string		false	"Meses a serem pesquisados, separados por virgula. Exemplo: 1,2,3"
+// @Param			de				query		string		false	"Primeiro mês do intervalo, no formato AAAA-MM, a partir de 2018-01. Não pode ser usado com anos e meses. Padrão: 2018-01."
+// @Param			ate				query		string		false	"Último mês do intervalo, no formato AAAA-MM, até o mês atual. Não pode ser usado com anos e meses. Padrão: mês atual."
+// @Param			orgaos			query		string		false	"Orgãos a serem pesquisados, separados por virgula. Exemplo: tjal,mpal,mppb"
+// @Param			grupos			query		string		false	"Grupos de órgãos a serem pesquisados, separados por vírgula. Exemplo: justica-estadual,ministerios-publicos"
+// @Param			ufs				query		string		false	"UFs dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: AL,PB,PE"
+// @Param			entidades		query		string		false	"Entidades dos órgãos a serem pesquisados, separadas por vírgula. Exemplo: Tribunal"
+// @Param			categorias		query		string		false	"Categorias a serem pesquisadas, separadas por vírgula: base, outras e descontos. Exemplo: base,outras"
+// @Param			nome			query		string		false	"Trecho do nome do membro, sem diferenciar maiúsculas e acentos"
+// @Param			cargo			query		string		false	"Trecho do cargo do membro, sem diferenciar maiúsculas e acentos"
+// @Param			lotacao			query		string		false	"Trecho da lotação do membro, sem diferenciar maiúsculas e acentos"
+// @Param			detalhamento_contracheque	query	string	false	"Trecho do detalhamento do contracheque, sem diferenciar maiúsculas e acentos. Exemplo: auxilio-moradia"
+// @Param			valor_min		query		number		false	"Valor mínimo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 1000.50 ou 1.000,50"
+// @Param			valor_max		query		number		false	"Valor máximo da linha do contracheque, em reais, com ponto ou vírgula como separador decimal. Exemplo: 50000"
+// @Param			ordenar			query		string		false	"Ordenação das linhas no formato 'campo direção'. Na ordenação por valor, as linhas com valor inválido ficam de fora. Exemplo: valor desc"
+// @Param			agrupar			query		string		false	"Agrupa as linhas por membro, mês e ano. As colunas passam a ser orgao, mes, ano, matricula, nome, cargo, lotacao, base, outras, descontos e liquido"	Enums(membro)
+// @Param			formato			query		string		false	"Formato do arquivo. Se nada for informado, o arquivo será gerado em csv"	Enums(csv,jsonl,parquet)
+// @Success		202				{object}	exportJob	"Exportação criada"
+// @Failure		400				{string}	string		"Erro de validação dos parâmetros."
+// @Failure		500				{string}	string		"Erro interno do servidor."
+// @Failure		429				{string}	string		"Limite de exportações em andamento do cliente atingido."
+// @Failure		503				{string}	string		"Exportações indisponíveis ou fila de exportações cheia."
+// @Router			/uiapi/v2/exportacoes [post]
+func (h handler) CreateExport(c echo.Context) error {
+	if h.exports == nil {
+		return c.JSON(http.StatusServiceUnavailable, "exportações não estão disponíveis")
+	}
+	qp := c.QueryParams()
+	if _, err := newSearchParams(qp); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	formatName := qp.Get("formato")
+	if formatName == "" {
+		formatName = "csv"
+	}
+	if _, ok := downloadFormats[formatName]; !ok {
+		return c.JSON(http.StatusBadRequest, fmt.Sprintf("formato inválido: '%s'. Os formatos aceitos são csv, jsonl e parquet", formatName))
+	}
+	id, err := newExportID()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	// O formato fica no próprio job, então não precisa ser guardado nos parâmetros.
+	qp.Del("formato")
+	job := &exportJob{
+		ID:         id,
+		Status:     exportPending,
+		Parameters: qp.Encode(),
+		Format:     formatName,
+		CreatedAt:  time.Now().In(h.loc),
+	}
+	created, err := h.exports.create(c.Request().Context(), job, c.RealIP())
+	if err != nil {
+		log.Printf("[exports] error saving export %s: %q", id, err)
+		return c.JSON(http.StatusInternalServerError, "erro ao criar a exportação")
+	}
+	if !created {
+		return c.JSON(http.StatusTooManyRequests, fmt.Sprintf("limite de %d exportações em andamento por cliente atingido, tente novamente quando elas terminarem", h.exports.maxPerClient))
+	}
+	if !h.exports.enqueue(id) {
+		// Marcamos a exportação como falha para que ela não seja executada ao reiniciar a API.
+		job.Status = exportFailed
+		job.Error = "fila de exportações cheia"
+		job.finish(time.Now().In(h.loc), h.exports.ttl)
+		if err := h.exports.save(c.Request().Context(), job); err != nil {
+			log.Printf("[exports] error saving export %s: %q", id, err)
+		}
+		return c.JSON(http.StatusServiceUnavailable, "fila de exportações cheia, tente novamente mais tarde")
+	}
+	c.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/uiapi/v2/exportacoes/%s", id))
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// @ID				GetExport
+// @Tags			ui_api
+// @Description	Retorna a situação de uma exportação assíncrona (pendente, executando, concluida ou erro). Quando a exportação é concluída, o campo 'arquivo' contém a rota para baixar o arquivo.
+// @Produce		json
+// @Param			id	path		string		true	"Identificador da exportação"
+// @Success		200	{object}	exportJob	"Situação da exportação"
+// @Failure		404	{string}	string		"Exportação não encontrada"
+// @Failure		500	{string}	string		"Erro interno do servidor"
+// @Router			/uiapi/v2/exportacoes/{id} [get]
+func (h handler) GetExport(c echo.Context) error {
+	job, status, err := h.loadExport(c)
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// @ID				DownloadExport
+// @Tags			ui_api
+// @Description	Baixa o arquivo de uma exportação assíncrona concluída.
+// @Produce		json
+// @Param			id	path		string	true	"Identificador da exportação"
+// @Success		200	{file}		file	"Arquivo com os dados no formato pedido"
+// @Failure		404	{string}	string	"Exportação não encontrada"
+// @Failure		409	{string}	string	"Exportação ainda não concluída"
+// @Failure		500	{string}	string	"Erro interno do servidor"
+// @Router			/uiapi/v2/exportacoes/{id}/arquivo [get]
+func (h handler) DownloadExport(c echo.Context) error {
+	job, status, err := h.loadExport(c)
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+	if job.Status != exportDone {
+		return c.JSON(http.StatusConflict, fmt.Sprintf("exportação %s ainda não foi concluída (%s)", job.ID, job.Status))
+	}
+	r, err := h.exports.store.Get(c.Request().Context(), exportFileKey(job))
+	if err != nil {
+		log.Printf("[exports] error getting file of export %s: %q", job.ID, err)
+		return c.JSON(http.StatusInternalServerError, "erro ao buscar o arquivo da exportação")
+	}
+	defer r.Close()
+	format := downloadFormats[job.Format]
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=dadosjusbr-remuneracoes-%s.%s", job.ID, format.Extension))
+	return c.Stream(http.StatusOK, format.ContentType, r)
+}
+
+// Busca a exportação do parâmetro "id". Em caso de erro, também retorna o
+// status da resposta.
+func (h handler) loadExport(c echo.Context) (*exportJob, int, error) {
+	if h.exports == nil {
+		return nil, http.StatusServiceUnavailable, fmt.Errorf("exportações não estão disponíveis")
+	}
+	id := c.Param("id")
+	if !exportIDPattern.MatchString(id) {
+		return nil, http.StatusNotFound, fmt.Errorf("exportação %s não encontrada", id)
+	}
+	job, err := h.exports.load(c.Request().Context(), id)
+	if errors.Is(err, ErrExportNotFound) {
+		return nil, http.StatusNotFound, fmt.Errorf("exportação %s não encontrada", id)
+	}
+	if err != nil {
+		log.Printf("[exports] error loading export %s: %q", id, err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("erro ao buscar a exportação")
+	}
+	return job, http.StatusOK, nil
+}
+
+// Verifica se a requisição possui uma das chaves de acesso configuradas.
+func (h handler) isAuthenticated(c echo.Context) bool {
+	token := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if token == "" {
+		return false
+	}
+	for _, k := range h.downloadAPIKeys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(k)) == 1 {
+			return true
+		}
+	}
+	return false
 }
 
 //go:embed readme_content.txt
@@ -943,12 +1937,18 @@ func (h handler) DownloadReadme(c echo.Context) error {
 // @Description	- Resumo dos benefícios identificados (rubricas/penduricalhos) e seus respectivos valores no ano
 // @Description	- Informações do pacote de dados, URL do pacote de dados para download, seu hash e tamanho do pacote de dados (em bytes)
 // @Produce		json
-// @Param			orgao	path		string			true	"Nome do orgão"
+// @Param			orgao		path		string			true	"Nome do orgão"
+// @Param			corrigir	query		string			false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao. Os valores anuais são corrigidos pela média dos fatores dos meses do ano."
+// @Param			base		query		string			false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
 // @Success		200		{object}	[]annualSummary	"Requisição bem sucedida."
 // @Failure		400		{string}	string			"Parâmetro orgao inválido"
 // @Failure		500		{string}	string			"Algo deu errado ao tentar coletar os dados anuais do orgao"
 // @Router			/uiapi/v1/orgao/resumo/{orgao} [get]
 func (h handler) GetAnnualSummary(c echo.Context) error {
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
 	agencyName := c.Param("orgao")
 	strAgency, err := h.client.Db.GetAgency(agencyName)
 	if err != nil {
@@ -964,28 +1964,29 @@ func (h handler) GetAnnualSummary(c echo.Context) error {
 	}
 	var annualData []annualSummaryData
 	for _, s := range summaries {
-		baseRemPerMonth := s.BaseRemuneration / float64(s.NumMonthsWithData)
-		baseRemPerCapita := s.BaseRemunerationPerCapita
-		otherRemPerMonth := s.OtherRemunerations / float64(s.NumMonthsWithData)
-		otherRemPerCapita := s.OtherRemunerationsPerCapita
-		remPerMonth := s.Remunerations / float64(s.NumMonthsWithData)
-		remPerCapita := s.RemunerationsPerCapita
-		discountsRemPerMonth := s.Discounts / float64(s.NumMonthsWithData)
-		discountsRemPerCapita := s.DiscountsPerCapita
+		f := corr.Year(s.Year)
+		baseRemPerMonth := s.BaseRemuneration * f / float64(s.NumMonthsWithData)
+		baseRemPerCapita := s.BaseRemunerationPerCapita * f
+		otherRemPerMonth := s.OtherRemunerations * f / float64(s.NumMonthsWithData)
+		otherRemPerCapita := s.OtherRemunerationsPerCapita * f
+		remPerMonth := s.Remunerations * f / float64(s.NumMonthsWithData)
+		remPerCapita := s.RemunerationsPerCapita * f
+		discountsRemPerMonth := s.Discounts * f / float64(s.NumMonthsWithData)
+		discountsRemPerCapita := s.DiscountsPerCapita * f
 
 		annualData = append(annualData, annualSummaryData{
 			Year:                        s.Year,
 			AverageMemberCount:          s.AverageCount,
-			BaseRemuneration:            s.BaseRemuneration,
+			BaseRemuneration:            s.BaseRemuneration * f,
 			BaseRemunerationPerMonth:    baseRemPerMonth,
 			BaseRemunerationPerCapita:   baseRemPerCapita,
-			OtherRemunerations:          s.OtherRemunerations,
+			OtherRemunerations:          s.OtherRemunerations * f,
 			OtherRemunerationsPerMonth:  otherRemPerMonth,
 			OtherRemunerationsPerCapita: otherRemPerCapita,
-			Discounts:                   s.Discounts,
+			Discounts:                   s.Discounts * f,
 			DiscountsPerMonth:           discountsRemPerMonth,
 			DiscountsPerCapita:          discountsRemPerCapita,
-			Remunerations:               s.Remunerations,
+			Remunerations:               s.Remunerations * f,
 			RemunerationsPerMonth:       remPerMonth,
 			RemunerationsPerCapita:      remPerCapita,
 			NumMonthsWithData:           s.NumMonthsWithData,
@@ -994,8 +1995,9 @@ func (h handler) GetAnnualSummary(c echo.Context) error {
 				Hash: s.Package.Hash,
 				Size: s.Package.Size,
 			},
-			ItemSummary:  itemSummary(s.ItemSummary),
+			ItemSummary:  itemSummary(ipca.Items(s.ItemSummary, f)),
 			Inconsistent: s.Inconsistent,
+			Uncorrected:  corr.UncorrectedYear(s.Year),
 		})
 	}
 	var collect []collecting
@@ -1020,36 +2022,201 @@ func (h handler) GetAnnualSummary(c echo.Context) error {
 			OmbudsmanURL:  strAgency.OmbudsmanURL,
 			HasData:       hasData,
 		},
-		Data: annualData,
+		Data:       annualData,
+		Correction: corr,
 	}
 	return c.JSON(http.StatusOK, annualSum)
 }
 
-func (h handler) getSearchResults(limit int, category string, results []searchDetails) ([]searchResult, int, error) {
+func (h handler) getSearchResults(limit int, params *searchParams, results []searchDetails, cursor searchCursor) ([]searchResult, int, *searchCursor, error) {
 	searchResults := []searchResult{}
 	numRows := 0
 	if len(results) == 0 {
-		return searchResults, numRows, nil
+		return searchResults, numRows, nil, nil
 	} else {
-		// A razão para essa ordenação é que quando o usuário escolhe diversos órgãos
-		// provavelmente ele prefere ver dados de todos eles. Dessa forma, aumentamos
-		// as chances do preview limitado retornar dados de diversos órgãos.
-		sort.SliceStable(results, func(i, j int) bool {
-			return results[i].Ano < results[j].Ano || results[i].Mes < results[j].Mes
+		searchResults, numRows, next, err := h.remunerations.getRemunerations(limit, params, results, cursor)
+		if err != nil {
+			return nil, numRows, nil, fmt.Errorf("failed to get remunerations %q", err)
+		}
+		return searchResults, numRows, next, nil
+	}
+}
+
+// resolveAgencyGroups converte os filtros de grupos, UFs e entidades nos órgãos
+// correspondentes. Os filtros são combinados entre si e com os órgãos informados
+// pelo usuário, ou seja, um órgão precisa atender a todos eles.
+func (h handler) resolveAgencyGroups(params *searchParams) error {
+	if params == nil || (len(params.Groups) == 0 && len(params.UFs) == 0 && len(params.Entities) == 0) {
+		return nil
+	}
+	var sets []map[string]bool
+	if len(params.Agencies) > 0 {
+		set := map[string]bool{}
+		for _, a := range params.Agencies {
+			set[a] = true
+		}
+		sets = append(sets, set)
+	}
+	agencies, err := h.client.Db.GetAllAgencies()
+	if err != nil {
+		return fmt.Errorf("error getting agencies: %w", err)
+	}
+	// Os grupos são associados aos órgãos da mesma forma que na API pública.
+	if len(params.Groups) > 0 {
+		set := map[string]bool{}
+		for _, a := range agencies {
+			for _, j := range params.Groups {
+				if jurisdiction.Match(j, a) {
+					set[a.ID] = true
+				}
+			}
+		}
+		sets = append(sets, set)
+	}
+	if len(params.UFs) > 0 {
+		set := map[string]bool{}
+		for _, a := range agencies {
+			if contains(params.UFs, strings.ToUpper(a.UF)) {
+				set[a.ID] = true
+			}
+		}
+		sets = append(sets, set)
+	}
+	if len(params.Entities) > 0 {
+		set := map[string]bool{}
+		for _, a := range agencies {
+			if contains(params.Entities, normalizeText(a.Entity)) {
+				set[a.ID] = true
+			}
+		}
+		sets = append(sets, set)
+	}
+	// Mantemos apenas os órgãos presentes em todos os conjuntos, em ordem para
+	// que a query seja sempre a mesma.
+	ids := []string{}
+	for id := range sets[0] {
+		inAll := true
+		for _, set := range sets[1:] {
+			if !set[id] {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	params.Agencies = ids
+	return nil
+}
+
+// writeSearchResults escreve em out as linhas da pesquisa no formato pedido,
+// agrupadas por membro quando params pedir. flush é chamado a cada
+// downloadBatchSize linhas escritas. Retorna a quantidade de linhas escritas.
+func (h handler) writeSearchResults(ctx context.Context, out io.Writer, format downloadFormat, limit int, params *searchParams, results []searchDetails, flush func()) (int, error) {
+	if params.groupByMember() {
+		w, err := format.NewMemberWriter(out)
+		if err != nil {
+			return 0, err
+		}
+		return writeRows[memberResult](w, flush, func(fn func(memberResult) error) error {
+			return h.streamMemberSearchResults(ctx, limit, params, results, fn)
+		})
+	}
+	w, err := format.NewWriter(out)
+	if err != nil {
+		return 0, err
+	}
+	return writeRows[searchResult](w, flush, func(fn func(searchResult) error) error {
+		return h.streamSearchResults(ctx, limit, params, results, fn)
+	})
+}
+
+// streamMemberSearchResults funciona como streamSearchResults, mas chama fn
+// para cada contracheque agrupado por membro.
+func (h handler) streamMemberSearchResults(ctx context.Context, limit int, params *searchParams, results []searchDetails, fn func(memberResult) error) error {
+	if order := params.sort(); order != nil {
+		sorted := newSortedResults(*order, limit, nil)
+		err := h.remunerations.streamMemberResults(ctx, results, params, searchCursor{}, func(m memberResult, pos searchCursor) error {
+			sorted.AddMember(m, pos)
+			return nil
 		})
-		searchResults, numRows, err := h.sess.getRemunerationsFromS3(limit, h.downloadLimit, category, h.s3Bucket, results)
 		if err != nil {
-			return nil, numRows, fmt.Errorf("failed to get remunerations from s3 %q", err)
+			return fmt.Errorf("failed to get remunerations %q", err)
+		}
+		for _, m := range sorted.MemberResults() {
+			if err := fn(m); err != nil {
+				return err
+			}
 		}
-		return searchResults, numRows, nil
+		return nil
 	}
+	numRows := 0
+	err := h.remunerations.streamMemberResults(ctx, results, params, searchCursor{}, func(m memberResult, _ searchCursor) error {
+		if limit > 0 && numRows >= limit {
+			return errStopStreaming
+		}
+		numRows++
+		return fn(m)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get remunerations %q", err)
+	}
+	return nil
+}
+
+// streamSearchResults chama fn para cada linha que atende aos filtros, sem
+// guardar os resultados na memória. Um limite igual a zero significa que todas as linhas
+// serão lidas.
+func (h handler) streamSearchResults(ctx context.Context, limit int, params *searchParams, results []searchDetails, fn func(searchResult) error) error {
+	if len(results) == 0 {
+		return nil
+	}
+	// Com ordenação, as linhas só podem ser enviadas depois que todos os
+	// arquivos forem lidos.
+	if order := params.sort(); order != nil {
+		sorted := newSortedResults(*order, limit, nil)
+		err := h.remunerations.streamRemunerations(ctx, results, searchCursor{}, func(rem searchResult, pos searchCursor) error {
+			if params.match(rem) {
+				sorted.Add(rem, pos)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get remunerations %q", err)
+		}
+		for _, rem := range sorted.Results() {
+			if err := fn(rem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	numRows := 0
+	err := h.remunerations.streamRemunerations(ctx, zipsForLimit(results, params, searchCursor{}, limit), searchCursor{}, func(rem searchResult, _ searchCursor) error {
+		if !params.match(rem) {
+			return nil
+		}
+		if limit > 0 && numRows >= limit {
+			return errStopStreaming
+		}
+		numRows++
+		return fn(rem)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get remunerations %q", err)
+	}
+	return nil
 }
 
 // @ID				GetAveragePerAgency
 // @Tags			ui_api
 // @Description	Busca médias (remuneração base, outras remunerações, descontos e remuneração total) de cada órgão em um ano especificado.
 // @Produce		json
-// @Param			ano	path		int					true	"Ano para filtrar os dados"
+// @Param			ano			path		int					true	"Ano para filtrar os dados"
+// @Param			corrigir	query		string				false	"Índice usado para corrigir os valores pela inflação. Apenas 'ipca' é aceito. Sem ele, os valores são nominais. Os valores dos meses sem IPCA na série ficam nominais e são marcados com sem_correcao. As médias são corrigidas pela média dos fatores dos meses do ano e o mês base usado é informado no cabeçalho X-Correcao-Monetaria."
+// @Param			base		query		string				false	"Mês base da correção, no formato AAAA-MM. Padrão: último mês do IPCA disponível."
 // @Success		200	{array}		averagePerAgency	"Lista de dados de médias dos órgãos"
 // @Failure		400	{string}	string				"Parâmetro ANO inválido"
 // @Failure		500	{string}	string				"Erro ao buscar dados"
@@ -1060,6 +2227,11 @@ func (h handler) GetAveragePerAgency(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, fmt.Sprintf("Parâmetro ANO inválido: %s.", year))
 	}
+	corr, err := ipca.FromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	f := corr.Year(yearInt)
 
 	// Busca os as médias por membro do banco de dados
 	data, err := h.client.Db.GetAveragePerAgency(yearInt)
@@ -1072,10 +2244,11 @@ func (h handler) GetAveragePerAgency(c echo.Context) error {
 		avgPerAgency = append(avgPerAgency, averagePerAgency{
 			ID: d.AgencyID,
 			AveragePerMember: &perCapitaData{
-				BaseRemuneration:   d.BaseRemuneration,
-				OtherRemunerations: d.OtherRemunerations,
-				Discounts:          d.Discounts,
-				Remunerations:      d.Remunerations},
+				BaseRemuneration:   d.BaseRemuneration * f,
+				OtherRemunerations: d.OtherRemunerations * f,
+				Discounts:          d.Discounts * f,
+				Remunerations:      d.Remunerations * f,
+				Uncorrected:        corr.UncorrectedYear(yearInt)},
 		})
 	}
 
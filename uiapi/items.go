@@ -0,0 +1,98 @@
+package uiapi
+
+import (
+	"sort"
+
+	strModels "github.com/dadosjusbr/storage/models"
+)
+
+// Descrições das rubricas conhecidas do resumo dos contracheques (campo
+// resumo_rubricas). As demais rubricas encontradas nos dados são listadas sem
+// descrição.
+var itemLabels = map[string]string{
+	"auxilio_alimentacao":   "Auxílio-alimentação",
+	"auxilio_saude":         "Auxílio-saúde",
+	"ferias":                "Férias, incluindo o adicional de um terço",
+	"gratificacao_natalina": "Gratificação natalina (13º salário)",
+	"indenizacao_de_ferias": "Indenização de férias não usufruídas",
+	"licenca_compensatoria": "Licença compensatória",
+	"licenca_premio":        "Licença-prêmio",
+	"outras":                "Soma das demais rubricas, não identificadas individualmente",
+}
+
+// hasRemunerations indica se o mês tem dados de remuneração.
+func hasRemunerations(mi strModels.AgencyMonthlyInfo) bool {
+	return mi.Summary != nil && mi.Summary.BaseRemuneration.Total+mi.Summary.OtherRemunerations.Total > 0
+}
+
+// newItemCatalog lista, em ordem alfabética, as rubricas encontradas no resumo
+// dos meses com dados de remuneração.
+func newItemCatalog(monthlyInfos map[string][]strModels.AgencyMonthlyInfo) []itemDescription {
+	found := map[string]bool{}
+	for _, infos := range monthlyInfos {
+		for _, mi := range infos {
+			if !hasRemunerations(mi) {
+				continue
+			}
+			for item := range mi.Summary.ItemSummary {
+				found[item] = true
+			}
+		}
+	}
+	catalog := []itemDescription{}
+	for item := range found {
+		catalog = append(catalog, itemDescription{Item: item, Description: itemLabels[item]})
+	}
+	sort.Slice(catalog, func(i, j int) bool {
+		return catalog[i].Item < catalog[j].Item
+	})
+	return catalog
+}
+
+// Retorna a descrição da rubrica e se ela existe. A rubrica existe se for
+// conhecida ou se aparecer nos dados mensais.
+func findItem(item string, monthlyInfos map[string][]strModels.AgencyMonthlyInfo) (itemDescription, bool) {
+	if label, ok := itemLabels[item]; ok {
+		return itemDescription{Item: item, Description: label}, true
+	}
+	for _, d := range newItemCatalog(monthlyInfos) {
+		if d.Item == item {
+			return d, true
+		}
+	}
+	return itemDescription{}, false
+}
+
+// newItemAgencyTotals monta a série mensal da rubrica em cada órgão a partir
+// dos dados mensais dos órgãos. Os meses sem dados de remuneração são
+// ignorados. Os órgãos são ordenados pelo ID e os meses cronologicamente.
+func newItemAgencyTotals(item string, monthlyInfos map[string][]strModels.AgencyMonthlyInfo) []itemAgencyTotals {
+	totals := []itemAgencyTotals{}
+	for aID, infos := range monthlyInfos {
+		agencyTotals := itemAgencyTotals{AgencyID: aID, Months: []itemMonthTotal{}}
+		for _, mi := range infos {
+			if !hasRemunerations(mi) {
+				continue
+			}
+			agencyTotals.Months = append(agencyTotals.Months, itemMonthTotal{
+				Year:  mi.Year,
+				Month: mi.Month,
+				Total: mi.Summary.ItemSummary[item],
+			})
+		}
+		if len(agencyTotals.Months) == 0 {
+			continue
+		}
+		sort.Slice(agencyTotals.Months, func(i, j int) bool {
+			if agencyTotals.Months[i].Year != agencyTotals.Months[j].Year {
+				return agencyTotals.Months[i].Year < agencyTotals.Months[j].Year
+			}
+			return agencyTotals.Months[i].Month < agencyTotals.Months[j].Month
+		})
+		totals = append(totals, agencyTotals)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		return totals[i].AgencyID < totals[j].AgencyID
+	})
+	return totals
+}
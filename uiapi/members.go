@@ -0,0 +1,146 @@
+package uiapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Converte o nome do membro para a forma usada na comparação: sem acentos,
+// em minúsculas e com apenas um espaço entre as palavras.
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(normalizeText(name)), " ")
+}
+
+// getMemberRemunerations lê todos os arquivos de remunerações do órgão e
+// retorna as linhas dos contracheques que atendem a match.
+func (h handler) getMemberRemunerations(ctx context.Context, agency string, match func(searchResult) bool) ([]searchResult, error) {
+	params := &searchParams{Agencies: []string{agency}}
+	results, err := h.db.filter(h.db.remunerationQuery(params), h.db.arguments(params))
+	if err != nil {
+		return nil, err
+	}
+	var rows []searchResult
+	err = h.remunerations.streamRemunerations(ctx, results, searchCursor{}, func(rem searchResult, _ searchCursor) error {
+		if match(rem) {
+			rows = append(rows, rem)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remunerations %q", err)
+	}
+	return rows, nil
+}
+
+// Identifica o membro pela matrícula ou, quando o órgão não a informa, pelo nome.
+func memberKey(registration *string, name string) string {
+	if registration != nil && strings.TrimSpace(*registration) != "" {
+		return "matricula:" + strings.TrimSpace(*registration)
+	}
+	return "nome:" + normalizeName(name)
+}
+
+// memberAggregator soma as linhas dos contracheques por órgão, mês, ano e
+// membro, mantendo a ordem em que os membros aparecem.
+type memberAggregator struct {
+	index   map[string]int
+	members []memberResult
+	// Totais de base, outras e descontos de cada membro, em centavos, para
+	// que a soma das linhas não acumule erros de arredondamento.
+	totals [][3]int64
+}
+
+func newMemberAggregator() *memberAggregator {
+	return &memberAggregator{index: map[string]int{}}
+}
+
+func (a *memberAggregator) Add(rem searchResult) error {
+	cents, err := parseCents(rem.Valor)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s/%d/%d/%s", rem.Orgao, rem.Ano, rem.Mes, memberKey(rem.Matricula, rem.Nome))
+	i, ok := a.index[key]
+	if !ok {
+		i = len(a.members)
+		a.index[key] = i
+		a.members = append(a.members, memberResult{
+			Orgao:     rem.Orgao,
+			Mes:       rem.Mes,
+			Ano:       rem.Ano,
+			Matricula: rem.Matricula,
+			Nome:      rem.Nome,
+			Cargo:     rem.Cargo,
+			Lotacao:   rem.Lotacao,
+		})
+		a.totals = append(a.totals, [3]int64{})
+	}
+	t := &a.totals[i]
+	switch rem.CategoriaContracheque {
+	case "base":
+		t[0] += cents
+	case "outras":
+		t[1] += cents
+	case "descontos":
+		t[2] += cents
+	}
+	m := &a.members[i]
+	m.Base = float64(t[0]) / 100
+	m.Outras = float64(t[1]) / 100
+	m.Descontos = float64(t[2]) / 100
+	m.Liquido = float64(t[0]+t[1]-t[2]) / 100
+	return nil
+}
+
+// Results retorna os contracheques agrupados, na ordem em que os membros apareceram.
+func (a *memberAggregator) Results() []memberResult {
+	return a.members
+}
+
+// newMemberTimeSeries soma as linhas dos contracheques por membro e mês. As
+// séries são retornadas na ordem em que os membros aparecem nos arquivos e os
+// meses em ordem cronológica.
+func newMemberTimeSeries(rows []searchResult) ([]memberTimeSeries, error) {
+	agg := newMemberAggregator()
+	for _, rem := range rows {
+		if err := agg.Add(rem); err != nil {
+			return nil, err
+		}
+	}
+	var series []memberTimeSeries
+	index := map[string]int{}
+	for _, m := range agg.Results() {
+		key := memberKey(m.Matricula, m.Nome)
+		i, ok := index[key]
+		if !ok {
+			i = len(series)
+			index[key] = i
+			series = append(series, memberTimeSeries{
+				Agency:       m.Orgao,
+				Registration: m.Matricula,
+				Name:         m.Nome,
+			})
+		}
+		series[i].Months = append(series[i].Months, memberMonth{
+			Year:      m.Ano,
+			Month:     m.Mes,
+			Role:      m.Cargo,
+			Workplace: m.Lotacao,
+			Base:      m.Base,
+			Other:     m.Outras,
+			Discounts: m.Descontos,
+		})
+	}
+	for _, s := range series {
+		months := s.Months
+		sort.SliceStable(months, func(a, b int) bool {
+			if months[a].Year != months[b].Year {
+				return months[a].Year < months[b].Year
+			}
+			return months[a].Month < months[b].Month
+		})
+	}
+	return series, nil
+}
@@ -3,6 +3,8 @@ package uiapi
 import (
 	"time"
 
+	"github.com/dadosjusbr/api/daterange"
+	"github.com/dadosjusbr/api/ipca"
 	"github.com/dadosjusbr/proto/coleta"
 	"github.com/dadosjusbr/storage/models"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -125,11 +127,12 @@ type agencyTotalsYear struct {
 }
 
 type v2AgencyTotalsYear struct {
-	Year             int             `json:"ano,omitempty"`
-	Agency           *agency         `json:"orgao,omitempty"`
-	AveragePerCapita *perCapitaData  `json:"media_por_membro,omitempty"`
-	MonthTotals      []v2MonthTotals `json:"meses,omitempty"`
-	SummaryPackage   *backup         `json:"package,omitempty"`
+	Year             int              `json:"ano,omitempty"`
+	Agency           *agency          `json:"orgao,omitempty"`
+	AveragePerCapita *perCapitaData   `json:"media_por_membro,omitempty"`
+	MonthTotals      []v2MonthTotals  `json:"meses,omitempty"`
+	SummaryPackage   *backup          `json:"package,omitempty"`
+	Correction       *ipca.Correction `json:"correcao_monetaria,omitempty"`
 }
 
 type backup struct {
@@ -157,6 +160,7 @@ type monthTotals struct {
 
 type v2MonthTotals struct {
 	Error                       *procError  `json:"error,omitempty"`
+	Year                        int         `json:"ano,omitempty"` // Apenas nos totais de um intervalo
 	Month                       int         `json:"mes"`
 	MemberCount                 int         `json:"total_membros"`
 	BaseRemuneration            float64     `json:"remuneracao_base"`
@@ -170,6 +174,8 @@ type v2MonthTotals struct {
 	CrawlingTimestamp           timestamp   `json:"timestamp"`
 	ItemSummary                 itemSummary `json:"resumo_rubricas"`
 	Inconsistent                bool        `json:"inconsistente"`
+	Anomalies                   []anomaly   `json:"anomalias,omitempty"`
+	Uncorrected                 bool        `json:"sem_correcao,omitempty"` // Valores nominais apesar de corrigir, pois o IPCA do mês não está na série
 }
 
 type timestamp struct {
@@ -207,8 +213,13 @@ type searchDetails struct {
 	Mes       int    `db:"mes" json:"mes"`
 	Ano       int    `db:"ano" json:"ano"`
 	ZipUrl    string `db:"zip_url" json:"zip_url"`
+	// Linhas da categoria pesquisada neste arquivo e soma acumulada das linhas
+	// até este arquivo, inclusive.
+	Linhas           int `db:"linhas" json:"linhas"`
+	LinhasAcumuladas int `db:"linhas_acumuladas" json:"linhas_acumuladas"`
 }
 
+// Os campos que serão trazidos pela query de atualizações
 type searchResult struct {
 	Orgao                    string  `db:"orgao" json:"orgao" csv:"orgao" tableheader:"orgao"`
 	Mes                      int     `db:"mes" json:"mes" csv:"mes" tableheader:"mes"`
@@ -224,6 +235,22 @@ type searchResult struct {
 	DesambiguacaoMacro       string  `db:"desambiguacao_macro" json:"desambiguacao_macro" csv:"desambiguacao_macro" tableheader:"desambiguacao_macro"`
 }
 
+// Contracheque de um membro em um mês, com as linhas somadas por categoria.
+// Usado quando a pesquisa ou o download são feitos com agrupar=membro.
+type memberResult struct {
+	Orgao     string  `json:"orgao" csv:"orgao"`
+	Mes       int     `json:"mes" csv:"mes"`
+	Ano       int     `json:"ano" csv:"ano"`
+	Matricula *string `json:"matricula" csv:"matricula"`
+	Nome      string  `json:"nome" csv:"nome"`
+	Cargo     *string `json:"cargo" csv:"cargo"`
+	Lotacao   *string `json:"lotacao" csv:"lotacao"`
+	Base      float64 `json:"base" csv:"base"`
+	Outras    float64 `json:"outras" csv:"outras"`
+	Descontos float64 `json:"descontos" csv:"descontos"`
+	Liquido   float64 `json:"liquido" csv:"liquido"` // base + outras - descontos
+}
+
 // A resposta que será enviada pela rota de pesquisa
 type searchResponse struct {
 	DownloadAvailable  bool           `json:"download_available"`
@@ -231,6 +258,53 @@ type searchResponse struct {
 	SearchLimit        int            `json:"search_limit"`
 	DownloadLimit      int            `json:"download_limit"`
 	Results            []searchResult `json:"result"`
+	Next               string         `json:"proximo,omitempty"` // Cursor da próxima página, vazio na última página
+}
+
+// A resposta da rota de pesquisa quando os contracheques são agrupados por membro
+type memberSearchResponse struct {
+	DownloadAvailable  bool           `json:"download_available"`
+	NumRowsIfAvailable int            `json:"num_rows_if_available"` // Linhas dos contracheques, antes do agrupamento
+	SearchLimit        int            `json:"search_limit"`
+	DownloadLimit      int            `json:"download_limit"`
+	Results            []memberResult `json:"result"`
+	Next               string         `json:"proximo,omitempty"`
+}
+
+// Totais mensais dos contracheques de um membro de um órgão
+type memberTimeSeries struct {
+	Agency       string        `json:"orgao"`
+	Registration *string       `json:"matricula"`
+	Name         string        `json:"nome"` // Nome como aparece no primeiro contracheque encontrado
+	Months       []memberMonth `json:"meses"`
+}
+
+type memberMonth struct {
+	Year      int     `json:"ano"`
+	Month     int     `json:"mes"`
+	Role      *string `json:"cargo"`
+	Workplace *string `json:"lotacao"`
+	Base      float64 `json:"base"`
+	Other     float64 `json:"outras"`
+	Discounts float64 `json:"descontos"`
+}
+
+// Exportação assíncrona de remunerações. É guardada em json no ExportStore.
+type exportJob struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`            // pendente, executando, concluida ou erro
+	Parameters string     `json:"parametros"`        // Query params da pesquisa, no mesmo formato da rota de download
+	Format     string     `json:"formato"`           // csv, jsonl ou parquet
+	Rows       int        `json:"linhas"`            // Linhas escritas no arquivo, preenchido ao concluir
+	Error      string     `json:"erro,omitempty"`    // Preenchido quando a exportação falha
+	File       string     `json:"arquivo,omitempty"` // Rota para baixar o arquivo, preenchido ao concluir
+	CreatedAt  time.Time  `json:"criada_em"`
+	FinishedAt *time.Time `json:"finalizada_em,omitempty"`
+	ExpiresAt  *time.Time `json:"expira_em,omitempty"` // Quando a exportação e seu arquivo serão apagados
+
+	// Guardados no ExportStore, mas não retornados pela API (ver storedExport).
+	client  string
+	attempt int
 }
 
 type agency struct {
@@ -253,8 +327,9 @@ type collecting struct {
 }
 
 type annualSummary struct {
-	Agency *agency             `json:"orgao,omitempty"`
-	Data   []annualSummaryData `json:"dados_anuais,omitempty"`
+	Agency     *agency             `json:"orgao,omitempty"`
+	Data       []annualSummaryData `json:"dados_anuais,omitempty"`
+	Correction *ipca.Correction    `json:"correcao_monetaria,omitempty"`
 }
 
 type annualSummaryData struct {
@@ -276,6 +351,7 @@ type annualSummaryData struct {
 	Package                     *backup     `json:"package,omitempty"`
 	ItemSummary                 itemSummary `json:"resumo_rubricas"`
 	Inconsistent                bool        `json:"inconsistente"`
+	Uncorrected                 bool        `json:"sem_correcao,omitempty"` // A correção anual inclui meses com valores nominais, pois seu IPCA não está na série
 }
 
 // DEPRECATED: The ItemSummary struct is deprecated
@@ -294,6 +370,185 @@ type annualSummaryData struct {
 
 type itemSummary map[string]float64
 
+// Rubrica do resumo dos contracheques
+type itemDescription struct {
+	Item        string `json:"rubrica"`
+	Description string `json:"descricao,omitempty"`
+}
+
+// Totais mensais de uma rubrica em cada órgão
+type itemTimeSeries struct {
+	Item        string             `json:"rubrica"`
+	Description string             `json:"descricao,omitempty"`
+	Agencies    []itemAgencyTotals `json:"orgaos"`
+}
+
+type itemAgencyTotals struct {
+	AgencyID string           `json:"id_orgao"`
+	Months   []itemMonthTotal `json:"meses"`
+}
+
+type itemMonthTotal struct {
+	Year  int     `json:"ano"`
+	Month int     `json:"mes"`
+	Total float64 `json:"total"`
+}
+
+// Valor do teto constitucional a partir de um mês
+type tetoValue struct {
+	Start string  `json:"inicio"` // Mês de início da vigência, no formato AAAA-MM
+	Value float64 `json:"valor"`
+	Law   string  `json:"norma"`
+}
+
+type tetoTableResponse struct {
+	Version string      `json:"versao"`
+	Values  []tetoValue `json:"valores"`
+}
+
+// Membros acima do teto em um órgão e mês
+type tetoBreachMonth struct {
+	AgencyID string  `json:"id_orgao"`
+	Year     int     `json:"ano"`
+	Month    int     `json:"mes"`
+	Teto     float64 `json:"teto"`
+	Members  int     `json:"num_membros"`
+	Breaches int     `json:"num_acima_teto"`
+	Share    float64 `json:"percentual_acima_teto"` // Entre 0 e 1
+	Excess   float64 `json:"excedente"`             // Soma do que os membros receberam acima do teto
+}
+
+type tetoBreachTotals struct {
+	Paychecks int     `json:"num_contracheques"` // Soma dos membros de todos os meses
+	Breaches  int     `json:"num_acima_teto"`
+	Share     float64 `json:"percentual_acima_teto"`
+	Excess    float64 `json:"excedente"`
+}
+
+type tetoBreachesResponse struct {
+	TableVersion string            `json:"versao_tabela_teto"`
+	Totals       tetoBreachTotals  `json:"totais"`
+	Months       []tetoBreachMonth `json:"meses"`
+}
+
+// Comparação das séries mensais de vários órgãos
+type agencyComparison struct {
+	Months     []comparisonMonth        `json:"meses"`
+	Agencies   []agencyComparisonSeries `json:"orgaos"`
+	Correction *ipca.Correction         `json:"correcao_monetaria,omitempty"`
+}
+
+type comparisonMonth struct {
+	Year  int `json:"ano"`
+	Month int `json:"mes"`
+}
+
+type agencyComparisonSeries struct {
+	AgencyID               string              `json:"id_orgao"`
+	Position               int                 `json:"posicao,omitempty"`       // Posição no período, ausente se o órgão não tem dados
+	RemunerationsPerCapita float64             `json:"remuneracoes_por_membro"` // Média mensal da remuneração líquida por membro
+	MonthsWithData         int                 `json:"meses_com_dados"`
+	Months                 []*comparisonTotals `json:"totais"`                 // Alinhado com os meses da comparação, null quando não há dados
+	Uncorrected            bool                `json:"sem_correcao,omitempty"` // A média inclui meses com valores nominais, pois seu IPCA não está na série
+}
+
+type comparisonTotals struct {
+	Position                    int     `json:"posicao"` // Posição do órgão no mês
+	MemberCount                 int     `json:"total_membros"`
+	BaseRemuneration            float64 `json:"remuneracao_base"`
+	BaseRemunerationPerCapita   float64 `json:"remuneracao_base_por_membro"`
+	OtherRemunerations          float64 `json:"outras_remuneracoes"`
+	OtherRemunerationsPerCapita float64 `json:"outras_remuneracoes_por_membro"`
+	Discounts                   float64 `json:"descontos"`
+	DiscountsPerCapita          float64 `json:"descontos_por_membro"`
+	Remunerations               float64 `json:"remuneracoes"`
+	RemunerationsPerCapita      float64 `json:"remuneracoes_por_membro"`
+	Uncorrected                 bool    `json:"sem_correcao,omitempty"` // Valores nominais apesar de corrigir, pois o IPCA do mês não está na série
+}
+
+// Ranking dos órgãos por uma métrica
+type agencyRanking struct {
+	Metric         string            `json:"metrica"`
+	Item           string            `json:"rubrica,omitempty"`
+	Order          string            `json:"ordem"`
+	Period         periodRange       `json:"periodo"`
+	PreviousPeriod periodRange       `json:"periodo_anterior"`
+	Positions      []rankingPosition `json:"orgaos"`
+	Correction     *ipca.Correction  `json:"correcao_monetaria,omitempty"`
+}
+
+// Primeiro e último mês de um período, no formato AAAA-MM
+type periodRange struct {
+	Start string `json:"inicio"`
+	End   string `json:"fim"`
+}
+
+type rankingPosition struct {
+	Rank           int      `json:"posicao"`
+	AgencyID       string   `json:"id_orgao"`
+	Value          float64  `json:"valor"` // Média mensal da métrica nos meses com dados
+	MonthsWithData int      `json:"meses_com_dados"`
+	PreviousRank   *int     `json:"posicao_anterior"` // null se o órgão não tem dados no período anterior
+	PreviousValue  *float64 `json:"valor_anterior"`
+	Change         *float64 `json:"variacao"`               // Valor menos o valor anterior
+	ChangePercent  *float64 `json:"variacao_percentual"`    // null se o valor anterior é zero
+	Uncorrected    bool     `json:"sem_correcao,omitempty"` // O valor inclui meses com valores nominais, pois seu IPCA não está na série
+}
+
+// Distribuição dos valores dos contracheques dos membros de um órgão em um mês
+type remunerationDistribution struct {
+	AgencyID    string           `json:"id_orgao"`
+	Year        int              `json:"ano"`
+	Month       int              `json:"mes"`
+	Value       string           `json:"valor"` // Valor do contracheque usado: liquido, base, outras ou descontos
+	Members     int              `json:"num_membros"`
+	Mean        float64          `json:"media"`
+	Median      float64          `json:"mediana"`
+	Min         float64          `json:"min"`
+	Max         float64          `json:"max"`
+	Percentiles percentiles      `json:"percentis"`
+	Gini        *float64         `json:"gini"` // null se a soma dos valores não é positiva
+	Histogram   []histogramBin   `json:"histograma"`
+	Correction  *ipca.Correction `json:"correcao_monetaria,omitempty"`
+	Uncorrected bool             `json:"sem_correcao,omitempty"` // Valores nominais apesar de corrigir, pois o IPCA do mês não está na série
+}
+
+type percentiles struct {
+	P10 float64 `json:"p10"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
+
+// Faixa do histograma, que inclui o início e exclui o fim
+type histogramBin struct {
+	Start *float64 `json:"inicio"` // null na primeira faixa
+	End   *float64 `json:"fim"`    // null na última faixa
+	Count int      `json:"quantidade"`
+}
+
+// Sinal de anomalia em um mês de um órgão, calculado pela API. Os valores são nominais.
+type anomaly struct {
+	AgencyID  string   `json:"id_orgao"`
+	Year      int      `json:"ano"`
+	Month     int      `json:"mes"`
+	Type      string   `json:"tipo"` // remuneracoes, num_membros ou rubrica
+	Item      string   `json:"rubrica,omitempty"`
+	Value     float64  `json:"valor"`
+	Median    float64  `json:"mediana_movel"` // Mediana dos meses anteriores com dados
+	Deviation *float64 `json:"desvio"`        // (valor - mediana) / mediana, null se a mediana é zero
+}
+
+type agencyTotalsRange struct {
+	Range            *daterange.Range `json:"intervalo"`
+	Agency           *agency          `json:"orgao,omitempty"`
+	AveragePerCapita *perCapitaData   `json:"media_por_membro,omitempty"`
+	MonthTotals      []v2MonthTotals  `json:"meses"`
+	Correction       *ipca.Correction `json:"correcao_monetaria,omitempty"`
+}
+
 type mensalRemuneration struct {
 	Month              int         `json:"mes,omitempty"`
 	Members            int         `json:"num_membros,omitempty"`
@@ -302,6 +557,7 @@ type mensalRemuneration struct {
 	Discounts          float64     `json:"descontos"`
 	Remunerations      float64     `json:"remuneracoes"`
 	ItemSummary        itemSummary `json:"resumo_rubricas"`
+	Uncorrected        bool        `json:"sem_correcao,omitempty"` // Valores nominais apesar de corrigir, pois o IPCA do mês não está na série
 }
 
 type perCapitaData struct {
@@ -309,6 +565,7 @@ type perCapitaData struct {
 	OtherRemunerations float64 `json:"outras_remuneracoes"`
 	Discounts          float64 `json:"descontos"`
 	Remunerations      float64 `json:"remuneracoes"`
+	Uncorrected        bool    `json:"sem_correcao,omitempty"` // Inclui meses com valores nominais, pois seu IPCA não está na série
 }
 
 type averagePerAgency struct {
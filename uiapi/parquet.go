@@ -0,0 +1,370 @@
+package uiapi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Escritor mínimo de arquivos parquet (https://parquet.apache.org/docs/file-format/),
+// suficiente para os arquivos de download: colunas sem aninhamento, codificação
+// PLAIN, sem compressão e uma única página por coluna em cada grupo de linhas.
+// Os metadados do arquivo são serializados com o protocolo compacto do thrift.
+
+const parquetMagic = "PAR1"
+
+// Quantidade de linhas mantidas em memória antes de escrever um grupo de linhas.
+const parquetRowGroupRows = 50000
+
+// Tipos físicos e convertidos do parquet usados no arquivo de download.
+const (
+	parquetInt32     int32 = 1
+	parquetDouble    int32 = 5
+	parquetByteArray int32 = 6
+
+	parquetConvertedUTF8 int32 = 0
+
+	parquetRequired int32 = 0
+	parquetOptional int32 = 1
+
+	parquetEncodingPlain int32 = 0
+	parquetEncodingRLE   int32 = 3
+
+	parquetCodecUncompressed int32 = 0
+	parquetPageData          int32 = 0
+)
+
+type parquetColumn struct {
+	Name     string
+	Type     int32
+	Optional bool
+}
+
+// Colunas do arquivo parquet das linhas dos contracheques, na mesma ordem do csv.
+var parquetColumns = []parquetColumn{
+	{Name: "orgao", Type: parquetByteArray},
+	{Name: "mes", Type: parquetInt32},
+	{Name: "ano", Type: parquetInt32},
+	{Name: "matricula", Type: parquetByteArray, Optional: true},
+	{Name: "nome", Type: parquetByteArray},
+	{Name: "cargo", Type: parquetByteArray, Optional: true},
+	{Name: "lotacao", Type: parquetByteArray, Optional: true},
+	{Name: "categoria_contracheque", Type: parquetByteArray},
+	{Name: "detalhamento_contracheque", Type: parquetByteArray},
+	{Name: "valor", Type: parquetDouble},
+	{Name: "desambiguacao_micro", Type: parquetByteArray},
+	{Name: "desambiguacao_macro", Type: parquetByteArray},
+}
+
+// Colunas do arquivo parquet dos contracheques agrupados por membro.
+var memberParquetColumns = []parquetColumn{
+	{Name: "orgao", Type: parquetByteArray},
+	{Name: "mes", Type: parquetInt32},
+	{Name: "ano", Type: parquetInt32},
+	{Name: "matricula", Type: parquetByteArray, Optional: true},
+	{Name: "nome", Type: parquetByteArray},
+	{Name: "cargo", Type: parquetByteArray, Optional: true},
+	{Name: "lotacao", Type: parquetByteArray, Optional: true},
+	{Name: "base", Type: parquetDouble},
+	{Name: "outras", Type: parquetDouble},
+	{Name: "descontos", Type: parquetDouble},
+	{Name: "liquido", Type: parquetDouble},
+}
+
+// Valores de uma coluna no grupo de linhas atual.
+type parquetColumnBuffer struct {
+	values bytes.Buffer
+	levels []bool // definido ou nulo, apenas para colunas opcionais
+}
+
+type parquetColumnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+type parquetRowGroup struct {
+	numRows int64
+	size    int64
+	columns []parquetColumnChunk
+}
+
+type parquetWriter struct {
+	w         io.Writer
+	columns   []parquetColumn
+	offset    int64
+	rows      int64
+	buffers   []parquetColumnBuffer
+	rowGroups []parquetRowGroup
+}
+
+func newParquetWriter(w io.Writer, columns []parquetColumn) (*parquetWriter, error) {
+	p := &parquetWriter{w: w, columns: columns, buffers: make([]parquetColumnBuffer, len(columns))}
+	if err := p.write([]byte(parquetMagic)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	return err
+}
+
+// Write adiciona uma linha ao grupo de linhas atual. Os valores devem estar na
+// ordem das colunas do arquivo: string ou *string para BYTE_ARRAY, int32 e float64.
+func (p *parquetWriter) Write(values ...interface{}) error {
+	if len(values) != len(p.columns) {
+		return fmt.Errorf("expected %d values, got %d", len(p.columns), len(values))
+	}
+	for i, v := range values {
+		buf := &p.buffers[i]
+		if s, ok := v.(*string); ok {
+			if buf.levels = append(buf.levels, s != nil); s == nil {
+				continue
+			}
+			v = *s
+		}
+		switch v := v.(type) {
+		case string:
+			binary.Write(&buf.values, binary.LittleEndian, uint32(len(v)))
+			buf.values.WriteString(v)
+		case int32:
+			binary.Write(&buf.values, binary.LittleEndian, v)
+		case float64:
+			binary.Write(&buf.values, binary.LittleEndian, math.Float64bits(v))
+		default:
+			return fmt.Errorf("unsupported parquet value for column %s: %T", p.columns[i].Name, v)
+		}
+	}
+	p.rows++
+	if p.rows == parquetRowGroupRows {
+		return p.Flush()
+	}
+	return nil
+}
+
+// Flush escreve o grupo de linhas atual.
+func (p *parquetWriter) Flush() error {
+	if p.rows == 0 {
+		return nil
+	}
+	rg := parquetRowGroup{numRows: p.rows, columns: make([]parquetColumnChunk, len(p.columns))}
+	for i, col := range p.columns {
+		buf := &p.buffers[i]
+		var page bytes.Buffer
+		if col.Optional {
+			levels := encodeDefinitionLevels(buf.levels)
+			binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+			page.Write(levels)
+		}
+		page.Write(buf.values.Bytes())
+
+		var header thriftWriter
+		header.i32Field(1, parquetPageData)
+		header.i32Field(2, int32(page.Len()))
+		header.i32Field(3, int32(page.Len()))
+		header.structField(5)
+		header.i32Field(1, int32(p.rows))
+		header.i32Field(2, parquetEncodingPlain)
+		header.i32Field(3, parquetEncodingRLE)
+		header.i32Field(4, parquetEncodingRLE)
+		header.structEnd()
+		header.structEnd()
+
+		chunk := parquetColumnChunk{offset: p.offset, numValues: p.rows}
+		if err := p.write(header.Bytes()); err != nil {
+			return err
+		}
+		if err := p.write(page.Bytes()); err != nil {
+			return err
+		}
+		chunk.size = p.offset - chunk.offset
+		rg.size += chunk.size
+		rg.columns[i] = chunk
+
+		buf.values.Reset()
+		buf.levels = buf.levels[:0]
+	}
+	p.rowGroups = append(p.rowGroups, rg)
+	p.rows = 0
+	return nil
+}
+
+// Close escreve o último grupo de linhas e os metadados do arquivo.
+func (p *parquetWriter) Close() error {
+	if err := p.Flush(); err != nil {
+		return err
+	}
+	var numRows int64
+	for _, rg := range p.rowGroups {
+		numRows += rg.numRows
+	}
+
+	var meta thriftWriter
+	meta.i32Field(1, 1) // versão
+	meta.listField(2, thriftStruct, len(p.columns)+1)
+	meta.structBegin()
+	meta.binaryField(4, "schema")
+	meta.i32Field(5, int32(len(p.columns)))
+	meta.structEnd()
+	for _, col := range p.columns {
+		meta.structBegin()
+		meta.i32Field(1, col.Type)
+		repetition := parquetRequired
+		if col.Optional {
+			repetition = parquetOptional
+		}
+		meta.i32Field(3, repetition)
+		meta.binaryField(4, col.Name)
+		if col.Type == parquetByteArray {
+			meta.i32Field(6, parquetConvertedUTF8)
+		}
+		meta.structEnd()
+	}
+	meta.i64Field(3, numRows)
+	meta.listField(4, thriftStruct, len(p.rowGroups))
+	for _, rg := range p.rowGroups {
+		meta.structBegin()
+		meta.listField(1, thriftStruct, len(rg.columns))
+		for i, chunk := range rg.columns {
+			col := p.columns[i]
+			meta.structBegin()
+			meta.i64Field(2, chunk.offset)
+			meta.structField(3)
+			meta.i32Field(1, col.Type)
+			meta.listField(2, thriftI32, 2)
+			meta.varint(int64(parquetEncodingPlain))
+			meta.varint(int64(parquetEncodingRLE))
+			meta.listField(3, thriftBinary, 1)
+			meta.binary(col.Name)
+			meta.i32Field(4, parquetCodecUncompressed)
+			meta.i64Field(5, chunk.numValues)
+			meta.i64Field(6, chunk.size)
+			meta.i64Field(7, chunk.size)
+			meta.i64Field(9, chunk.offset)
+			meta.structEnd()
+			meta.structEnd()
+		}
+		meta.i64Field(2, rg.size)
+		meta.i64Field(3, rg.numRows)
+		meta.structEnd()
+	}
+	meta.binaryField(6, "dadosjusbr api")
+	meta.structEnd()
+
+	if err := p.write(meta.Bytes()); err != nil {
+		return err
+	}
+	var footer [4]byte
+	binary.LittleEndian.PutUint32(footer[:], uint32(meta.Len()))
+	if err := p.write(footer[:]); err != nil {
+		return err
+	}
+	return p.write([]byte(parquetMagic))
+}
+
+// Codifica os níveis de definição (0 para nulo, 1 para definido) usando a
+// codificação híbrida RLE/bit-packing com largura de 1 bit, apenas com
+// sequências RLE.
+func encodeDefinitionLevels(levels []bool) []byte {
+	var b []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		b = binary.AppendUvarint(b, uint64(j-i)<<1)
+		if levels[i] {
+			b = append(b, 1)
+		} else {
+			b = append(b, 0)
+		}
+		i = j
+	}
+	return b
+}
+
+// Tipos do protocolo compacto do thrift.
+const (
+	thriftI32    byte = 5
+	thriftI64    byte = 6
+	thriftBinary byte = 8
+	thriftList   byte = 9
+	thriftStruct byte = 12
+)
+
+// thriftWriter serializa structs usando o protocolo compacto do thrift.
+type thriftWriter struct {
+	bytes.Buffer
+	lastField []int16
+	current   int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.current; delta > 0 && delta <= 15 {
+		t.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	t.current = id
+}
+
+func (t *thriftWriter) varint(v int64) {
+	t.Write(binary.AppendVarint(nil, v))
+}
+
+func (t *thriftWriter) binary(s string) {
+	t.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	t.WriteString(s)
+}
+
+func (t *thriftWriter) i32Field(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64Field(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binaryField(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary(s)
+}
+
+// Inicia um campo do tipo struct. Deve ser finalizado com structEnd.
+func (t *thriftWriter) structField(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.structBegin()
+}
+
+// Inicia uma struct que é elemento de uma lista. Deve ser finalizada com structEnd.
+func (t *thriftWriter) structBegin() {
+	t.lastField = append(t.lastField, t.current)
+	t.current = 0
+}
+
+// Inicia um campo do tipo lista. Os elementos devem ser escritos em seguida.
+func (t *thriftWriter) listField(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.WriteByte(0xf0 | elemType)
+		t.Write(binary.AppendUvarint(nil, uint64(size)))
+	}
+}
+
+func (t *thriftWriter) structEnd() {
+	t.WriteByte(0)
+	if n := len(t.lastField); n > 0 {
+		t.current = t.lastField[n-1]
+		t.lastField = t.lastField[:n-1]
+	}
+}
@@ -132,29 +132,50 @@ func (p postgresDB) filter(query string, arguments []interface{}) ([]searchDetai
 	return results, nil
 }
 
-// Função que recebe os filtros e a partir deles estrutura a query SQL da pesquisa
+// Função que recebe os filtros e a partir deles estrutura a query SQL da pesquisa.
+// Além dos arquivos, a query retorna a quantidade de linhas das categorias pedidas
+// em cada arquivo e a soma acumulada dessas linhas, na ordem em que os arquivos
+// serão lidos. Assim, sabemos quais arquivos são necessários para um limite de
+// linhas antes de baixá-los.
 func (p postgresDB) remunerationQuery(searchParams *searchParams) string {
+	rows := "linhas_descontos + linhas_base + linhas_outras"
+	if categories := searchParams.categories(); len(categories) > 0 {
+		var columns []string
+		for _, c := range categories {
+			columns = append(columns, categoryRowsColumns[c])
+		}
+		rows = strings.Join(columns, " + ")
+	}
+	// A razão para ordenar por data e depois por órgão é que quando o usuário
+	// escolhe diversos órgãos provavelmente ele prefere ver dados de todos eles.
+	// Dessa forma, aumentamos as chances do preview limitado retornar dados de
+	// diversos órgãos. A ordem também precisa ser estável para que os cursores
+	// de paginação continuem válidos.
+	order := "ano, mes, id_orgao"
+
 	//A query padrão sem os filtros
-	query := `SELECT
+	query := fmt.Sprintf(`SELECT
 		id_orgao as orgao,
 		mes as mes,
 		ano as ano,
 		linhas_descontos as descontos,
 		linhas_base as base,
 		linhas_outras as outras,
-		zip_url as zip_url
+		zip_url as zip_url,
+		%[1]s as linhas,
+		SUM(%[1]s) OVER (ORDER BY %[2]s ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) as linhas_acumuladas
 	FROM remuneracoes_zips 
-	`
+	`, rows, order)
 	if searchParams != nil {
 		p.addFiltersInQuery(&query, searchParams)
 	}
 
-	return query
+	return fmt.Sprintf("%s ORDER BY %s", query, order)
 }
 
 // Função que insere os filtros na query
 func (p postgresDB) addFiltersInQuery(query *string, searchParams *searchParams) {
-	*query = *query + " WHERE"
+	var filters []string
 
 	//Insere os filtros de ano caso existam
 	if len(searchParams.Years) > 0 {
@@ -163,35 +184,43 @@ func (p postgresDB) addFiltersInQuery(query *string, searchParams *searchParams)
 		for i := 0; i < len(searchParams.Years); i++ {
 			years[i] = fmt.Sprintf("$%d", i+1)
 		}
-		*query = fmt.Sprintf("%s ano IN (%s)", *query, strings.Join(years, ","))
+		filters = append(filters, fmt.Sprintf("ano IN (%s)", strings.Join(years, ",")))
 	}
 
 	//Insere os filtros de mês
 	if len(searchParams.Months) > 0 {
 		lastIndex := len(searchParams.Years)
-		if lastIndex > 0 {
-			*query = fmt.Sprintf("%s AND", *query)
-		}
 		var months []string
 		months = append(months, searchParams.Months...)
 		for i := lastIndex; i < len(searchParams.Months)+lastIndex; i++ {
 			months[i-lastIndex] = fmt.Sprintf("$%d", i+1)
 		}
-		*query = fmt.Sprintf("%s mes IN (%s)", *query, strings.Join(months, ","))
+		filters = append(filters, fmt.Sprintf("mes IN (%s)", strings.Join(months, ",")))
 	}
 
 	//Insere o filtro de órgãos
 	if len(searchParams.Agencies) > 0 {
 		lastIndex := len(searchParams.Years) + len(searchParams.Months)
-		if lastIndex > 0 {
-			*query = fmt.Sprintf("%s AND", *query)
-		}
 		var agencies []string
 		agencies = append(agencies, searchParams.Agencies...)
 		for i := lastIndex; i < lastIndex+len(searchParams.Agencies); i++ {
 			agencies[i-lastIndex] = fmt.Sprintf("$%d", i+1)
 		}
-		*query = fmt.Sprintf("%s id_orgao IN (%s)", *query, strings.Join(agencies, ","))
+		filters = append(filters, fmt.Sprintf("id_orgao IN (%s)", strings.Join(agencies, ",")))
+	} else if searchParams.Agencies != nil {
+		// Nenhum órgão atende aos filtros de grupos.
+		filters = append(filters, "FALSE")
+	}
+
+	//Insere o filtro de intervalo de meses
+	if searchParams.Range != nil {
+		lastIndex := len(searchParams.Years) + len(searchParams.Months) + len(searchParams.Agencies)
+		filters = append(filters, fmt.Sprintf("(ano, mes) BETWEEN ($%d, $%d) AND ($%d, $%d)", lastIndex+1, lastIndex+2, lastIndex+3, lastIndex+4))
+	}
+
+	// Os demais filtros são aplicados nas linhas dos arquivos.
+	if len(filters) > 0 {
+		*query = fmt.Sprintf("%s WHERE %s", *query, strings.Join(filters, " AND "))
 	}
 }
 
@@ -214,6 +243,9 @@ func (p postgresDB) arguments(searchParams *searchParams) []interface{} {
 				arguments = append(arguments, a)
 			}
 		}
+		if r := searchParams.Range; r != nil {
+			arguments = append(arguments, r.From.Year, r.From.Month, r.To.Year, r.To.Month)
+		}
 	}
 
 	return arguments
@@ -0,0 +1,187 @@
+package uiapi
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dadosjusbr/api/daterange"
+	"github.com/dadosjusbr/api/ipca"
+	strModels "github.com/dadosjusbr/storage/models"
+)
+
+// Métricas aceitas pelo ranking. Exceto o índice de transparência, todas são
+// valores mensais por membro.
+var rankingMetrics = map[string]bool{
+	"remuneracao":      true,
+	"remuneracao_base": true,
+	"outras":           true,
+	"descontos":        true,
+	"rubrica":          true,
+	"indice":           true,
+}
+
+// Mês de um período do ranking.
+type yearMonth struct {
+	Year  int
+	Month int
+}
+
+// Número sequencial do mês, usado para deslocar os períodos.
+func (ym yearMonth) number() int {
+	return daterange.Number(ym.Year, ym.Month)
+}
+
+func (ym yearMonth) String() string {
+	return fmt.Sprintf("%04d-%02d", ym.Year, ym.Month)
+}
+
+func newYearMonth(n int) yearMonth {
+	m := daterange.MonthOf(n)
+	return yearMonth{Year: m.Year, Month: m.Month}
+}
+
+// rankingPeriod retorna, em ordem cronológica, os meses dos anos informados,
+// limitados aos meses e ao intervalo informados, se houver.
+func rankingPeriod(params *searchParams) []yearMonth {
+	var period []yearMonth
+	for _, year := range params.years() {
+		for m := 1; m <= 12; m++ {
+			if params.matchMonth(year, m) {
+				period = append(period, yearMonth{Year: year, Month: m})
+			}
+		}
+	}
+	sort.Slice(period, func(i, j int) bool {
+		return period[i].number() < period[j].number()
+	})
+	return period
+}
+
+// previousPeriod desloca o período para trás pela sua duração, do primeiro ao
+// último mês. Por exemplo, o período anterior a 2023 é 2022 e o anterior a
+// abril a junho de 2023 é janeiro a março de 2023.
+func previousPeriod(period []yearMonth) []yearMonth {
+	if len(period) == 0 {
+		return nil
+	}
+	span := period[len(period)-1].number() - period[0].number() + 1
+	previous := make([]yearMonth, len(period))
+	for i, ym := range period {
+		previous[i] = newYearMonth(ym.number() - span)
+	}
+	return previous
+}
+
+// rankingValue retorna o valor da métrica no mês e se o órgão tem dados para ela.
+func rankingValue(metric, item string, mi strModels.AgencyMonthlyInfo) (float64, bool) {
+	if metric == "indice" {
+		if mi.Score == nil {
+			return 0, false
+		}
+		return mi.Score.Score, true
+	}
+	if mi.Summary == nil || mi.Summary.BaseRemuneration.Total+mi.Summary.OtherRemunerations.Total <= 0 {
+		return 0, false
+	}
+	switch metric {
+	case "remuneracao_base":
+		return mi.Summary.BaseRemuneration.Average, true
+	case "outras":
+		return mi.Summary.OtherRemunerations.Average, true
+	case "descontos":
+		return mi.Summary.Discounts.Average, true
+	case "rubrica":
+		if mi.Summary.Count == 0 {
+			return 0, false
+		}
+		return mi.Summary.ItemSummary[item] / float64(mi.Summary.Count), true
+	default:
+		return mi.Summary.Remunerations.Average, true
+	}
+}
+
+// rankingValues calcula, para cada órgão, a média mensal da métrica nos meses
+// do período com dados.
+func rankingValues(metric, item string, monthlyInfos map[string][]strModels.AgencyMonthlyInfo, period []yearMonth, corr *ipca.Correction) []rankingPosition {
+	months := map[yearMonth]bool{}
+	for _, ym := range period {
+		months[ym] = true
+	}
+	var positions []rankingPosition
+	for aID, mis := range monthlyInfos {
+		p := rankingPosition{AgencyID: aID}
+		for _, mi := range mis {
+			if !months[yearMonth{Year: mi.Year, Month: mi.Month}] {
+				continue
+			}
+			v, ok := rankingValue(metric, item, mi)
+			if !ok {
+				continue
+			}
+			if metric != "indice" {
+				v *= corr.Month(mi.Year, mi.Month)
+				p.Uncorrected = p.Uncorrected || corr.Uncorrected(mi.Year, mi.Month)
+			}
+			p.Value += v
+			p.MonthsWithData++
+		}
+		if p.MonthsWithData > 0 {
+			p.Value /= float64(p.MonthsWithData)
+			positions = append(positions, p)
+		}
+	}
+	return positions
+}
+
+// rankPositions ordena os órgãos pelo valor e atribui as posições. Os empates
+// são desfeitos pelo ID do órgão.
+func rankPositions(positions []rankingPosition, desc bool) {
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].Value != positions[j].Value {
+			if desc {
+				return positions[i].Value > positions[j].Value
+			}
+			return positions[i].Value < positions[j].Value
+		}
+		return positions[i].AgencyID < positions[j].AgencyID
+	})
+	for i := range positions {
+		positions[i].Rank = i + 1
+	}
+}
+
+// newRankingPositions classifica os órgãos no período e no período anterior e
+// retorna as limit primeiras posições do período, com a variação em relação ao
+// anterior. Um limit igual a zero retorna todas as posições.
+func newRankingPositions(metric, item string, monthlyInfos map[string][]strModels.AgencyMonthlyInfo, period, previous []yearMonth, desc bool, limit int, corr *ipca.Correction) []rankingPosition {
+	positions := rankingValues(metric, item, monthlyInfos, period, corr)
+	rankPositions(positions, desc)
+	previousPositions := rankingValues(metric, item, monthlyInfos, previous, corr)
+	rankPositions(previousPositions, desc)
+	byAgency := map[string]rankingPosition{}
+	for _, p := range previousPositions {
+		byAgency[p.AgencyID] = p
+	}
+	for i := range positions {
+		prev, ok := byAgency[positions[i].AgencyID]
+		if !ok {
+			continue
+		}
+		rank, value := prev.Rank, prev.Value
+		change := positions[i].Value - value
+		positions[i].PreviousRank = &rank
+		positions[i].PreviousValue = &value
+		positions[i].Change = &change
+		if value != 0 {
+			percent := change / value * 100
+			positions[i].ChangePercent = &percent
+		}
+	}
+	if limit > 0 && len(positions) > limit {
+		positions = positions[:limit]
+	}
+	if positions == nil {
+		positions = []rankingPosition{}
+	}
+	return positions
+}
@@ -0,0 +1,255 @@
+package uiapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+
+	"github.com/gocarina/gocsv"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// errStopStreaming deve ser retornado pelo callback de streamRemunerations
+// para interromper a leitura dos arquivos sem que isso seja tratado como erro.
+var errStopStreaming = errors.New("stop streaming")
+
+// remunerationReader lê as linhas dos contracheques dos arquivos zip de
+// remunerações, independente de onde eles estão armazenados.
+type remunerationReader struct {
+	zips     ZipStore
+	newrelic *newrelic.Application
+}
+
+// getRemunerations retorna até limit linhas que atendem aos filtros, a
+// partir da posição indicada pelo cursor. Se houver mais linhas, também retorna
+// o cursor da próxima página.
+func (s remunerationReader) getRemunerations(limit int, params *searchParams, results []searchDetails, start searchCursor) ([]searchResult, int, *searchCursor, error) {
+	// A query já retorna a soma acumulada das linhas das categorias pedidas. Os
+	// filtros aplicados às linhas não são considerados nessa contagem.
+	var numRows = 0
+	if len(results) > 0 {
+		numRows = results[len(results)-1].LinhasAcumuladas
+	}
+
+	txn := s.newrelic.StartTransaction("zips.GetRemunerations")
+	defer txn.End()
+	ctx := newrelic.NewContext(context.Background(), txn)
+
+	// Com ordenação, precisamos ler todos os arquivos para saber quais são as
+	// primeiras linhas, mas guardamos apenas as linhas da página, que vêm
+	// depois da chave do cursor, mais uma para saber se existe uma próxima
+	// página.
+	if order := params.sort(); order != nil {
+		sorted := newSortedResults(*order, limit+1, start.After)
+		err := s.streamRemunerations(ctx, results, searchCursor{}, func(rem searchResult, pos searchCursor) error {
+			if params.match(rem) {
+				sorted.Add(rem, pos)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		searchResults := sorted.Results()
+		if len(searchResults) > limit {
+			last := sorted.Key(limit - 1)
+			return searchResults[:limit], numRows, &searchCursor{After: &last}, nil
+		}
+		return searchResults, numRows, nil, nil
+	}
+
+	/* Queremos guardar na memória apenas os resultados que atendem aos filtros
+	que o usuário pediu. Ao atingir o limite, paramos de baixar os arquivos zip
+	e a posição da próxima linha encontrada se torna o cursor da próxima página. */
+	searchResults := []searchResult{}
+	var next *searchCursor
+	// Buscamos uma linha a mais que o limite para saber se existe uma próxima página.
+	err := s.streamRemunerations(ctx, zipsForLimit(results, params, start, limit+1), start, func(rem searchResult, pos searchCursor) error {
+		if !params.match(rem) {
+			return nil
+		}
+		if len(searchResults) >= limit {
+			next = &pos
+			return errStopStreaming
+		}
+		searchResults = append(searchResults, rem)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return searchResults, numRows, next, nil
+}
+
+// streamRemunerations busca os arquivos zip de remunerações um de cada vez
+// e chama fn para cada linha, à medida que ela é decodificada. Dessa forma, apenas
+// um arquivo fica na memória por vez, independente do tamanho da consulta.
+// A leitura começa na posição indicada por start, e fn também recebe a posição
+// de cada linha.
+func (s remunerationReader) streamRemunerations(ctx context.Context, results []searchDetails, start searchCursor, fn func(searchResult, searchCursor) error) error {
+	for i := start.Zip; i < len(results); i++ {
+		content, err := s.zips.GetZip(ctx, results[i].ZipUrl)
+		if err != nil {
+			return err
+		}
+		pos := searchCursor{Zip: i}
+		err = readRemunerations(content, func(rem searchResult) error {
+			defer func() { pos.Row++ }()
+			if i == start.Zip && pos.Row < start.Row {
+				return nil
+			}
+			return fn(rem, pos)
+		})
+		if err != nil {
+			if errors.Is(err, errStopStreaming) {
+				return nil
+			}
+			return fmt.Errorf("error reading file (%s): %w", results[i].ZipUrl, err)
+		}
+	}
+	return nil
+}
+
+// streamMemberResults funciona como streamRemunerations, mas agrupa as linhas
+// que atendem aos filtros por membro. Como cada arquivo tem os contracheques de
+// um órgão em um mês, os membros de um arquivo são enviados para fn depois que
+// ele é lido por completo. Nas posições enviadas para fn, Row é o índice do
+// membro no arquivo.
+func (s remunerationReader) streamMemberResults(ctx context.Context, results []searchDetails, params *searchParams, start searchCursor, fn func(memberResult, searchCursor) error) error {
+	for i := start.Zip; i < len(results); i++ {
+		content, err := s.zips.GetZip(ctx, results[i].ZipUrl)
+		if err != nil {
+			return err
+		}
+		agg := newMemberAggregator()
+		err = readRemunerations(content, func(rem searchResult) error {
+			if !params.match(rem) {
+				return nil
+			}
+			return agg.Add(rem)
+		})
+		if err != nil {
+			return fmt.Errorf("error reading file (%s): %w", results[i].ZipUrl, err)
+		}
+		for j, m := range agg.Results() {
+			if i == start.Zip && j < start.Row {
+				continue
+			}
+			if err := fn(m, searchCursor{Zip: i, Row: j}); err != nil {
+				if errors.Is(err, errStopStreaming) {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// getMemberResults funciona como getRemunerations, mas retorna até limit
+// contracheques agrupados por membro.
+func (s remunerationReader) getMemberResults(limit int, params *searchParams, results []searchDetails, start searchCursor) ([]memberResult, int, *searchCursor, error) {
+	var numRows = 0
+	if len(results) > 0 {
+		numRows = results[len(results)-1].LinhasAcumuladas
+	}
+
+	txn := s.newrelic.StartTransaction("zips.GetMemberResults")
+	defer txn.End()
+	ctx := newrelic.NewContext(context.Background(), txn)
+
+	if order := params.sort(); order != nil {
+		sorted := newSortedResults(*order, limit+1, start.After)
+		err := s.streamMemberResults(ctx, results, params, searchCursor{}, func(m memberResult, pos searchCursor) error {
+			sorted.AddMember(m, pos)
+			return nil
+		})
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		members := sorted.MemberResults()
+		if len(members) > limit {
+			last := sorted.Key(limit - 1)
+			return members[:limit], numRows, &searchCursor{After: &last}, nil
+		}
+		return members, numRows, nil, nil
+	}
+
+	// A contagem de linhas não diz quantos membros existem em cada arquivo,
+	// então lemos os arquivos até encontrar um membro além do limite.
+	members := []memberResult{}
+	var next *searchCursor
+	err := s.streamMemberResults(ctx, results, params, start, func(m memberResult, pos searchCursor) error {
+		if len(members) >= limit {
+			next = &pos
+			return errStopStreaming
+		}
+		members = append(members, m)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return members, numRows, next, nil
+}
+
+// zipsForLimit retorna os arquivos necessários para ler limit linhas a partir
+// do cursor, de acordo com a contagem de linhas retornada pela query. Quando há
+// filtros aplicados às linhas, a contagem é apenas um limite superior, então
+// todos os arquivos podem ser necessários. Os índices dos arquivos não mudam,
+// para que continuem válidos nos cursores.
+func zipsForLimit(results []searchDetails, params *searchParams, start searchCursor, limit int) []searchDetails {
+	if limit <= 0 || params.filtersRows() || start.Zip >= len(results) {
+		return results
+	}
+	// Como o cursor pode estar no meio de um arquivo, não contamos as linhas
+	// do primeiro arquivo.
+	for i := start.Zip + 1; i < len(results); i++ {
+		if results[i].LinhasAcumuladas-results[start.Zip].LinhasAcumuladas >= limit {
+			return results[:i+1]
+		}
+	}
+	return results
+}
+
+// readRemunerations decodifica o remuneracoes.csv contido no zip, linha a linha.
+func readRemunerations(content []byte, fn func(searchResult) error) error {
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return fmt.Errorf("error creating zip reader: %w", err)
+	}
+	if len(zipReader.File) == 0 {
+		return fmt.Errorf("empty zip file")
+	}
+	fReader, err := zipReader.File[0].Open()
+	if err != nil {
+		return fmt.Errorf("error opening zip file: %w", err)
+	}
+	defer fReader.Close()
+
+	// Definimos o separador de colunas personalizado
+	csvReader := csv.NewReader(fReader)
+	csvReader.Comma = ';'
+
+	rows := make(chan searchResult)
+	decodeErr := make(chan error, 1)
+	go func() {
+		decodeErr <- gocsv.UnmarshalDecoderToChan(gocsv.NewSimpleDecoderFromCSVReader(csvReader), rows)
+	}()
+	var fnErr error
+	for rem := range rows {
+		// Mesmo depois do callback pedir para parar, precisamos esvaziar o canal
+		// para que a goroutine de leitura termine.
+		if fnErr != nil {
+			continue
+		}
+		fnErr = fn(rem)
+	}
+	if err := <-decodeErr; err != nil {
+		return fmt.Errorf("error unmarshaling remuneracoes.csv: %w", err)
+	}
+	return fnErr
+}
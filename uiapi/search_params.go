@@ -2,25 +2,118 @@ package uiapi
 
 import (
 	"fmt"
+	"math"
+	"net/url"
 	"strconv"
 	"strings"
+	"unicode"
+
+	"github.com/dadosjusbr/api/daterange"
+	"github.com/dadosjusbr/api/jurisdiction"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 )
 
 type searchParams struct {
-	Years    []string
-	Months   []string
+	// Anos pesquisados. Quando há um intervalo, são os anos do intervalo.
+	Years  []string
+	Months []string
+	// Intervalo de meses pedido nos parâmetros de e ate. Nil significa sem intervalo.
+	Range *daterange.Range
+	// Órgãos pesquisados. Nil significa todos os órgãos e uma lista vazia
+	// significa que nenhum órgão atende aos filtros de grupos.
 	Agencies []string
-	Category string
-	Types    string
+	// Grupos de órgãos, que são convertidos em órgãos por resolveAgencyGroups.
+	// Os grupos são guardados com o nome da jurisdição no banco e as entidades
+	// normalizadas.
+	Groups   []string
+	UFs      []string
+	Entities []string
+	// Categorias dos contracheques. Vazio significa todas as categorias.
+	Categories []string
+	// Filtros de texto aplicados às linhas dos contracheques. Os valores já
+	// estão normalizados (ver normalizeText).
+	Name      string
+	Role      string
+	Workplace string
+	Item      string
+	// Faixa de valores das linhas dos contracheques, em centavos. Nil significa
+	// sem limite.
+	MinValue *int64
+	MaxValue *int64
+	// Ordenação dos resultados. Nil significa a ordem dos arquivos.
+	Sort *searchSort
+	// Agrupamento das linhas dos contracheques. Vazio significa sem agrupamento.
+	GroupBy string
+}
+
+// Campos aceitos pelo parâmetro "ordenar".
+var sortFields = map[string]bool{
+	"orgao":                     true,
+	"mes":                       true,
+	"ano":                       true,
+	"nome":                      true,
+	"cargo":                     true,
+	"lotacao":                   true,
+	"detalhamento_contracheque": true,
+	"valor":                     true,
 }
 
-func newSearchParams(yearsQp, monthsQp, agenciesQp, categoriesQp, typesQp string) (*searchParams, error) {
+var federativeUnits = map[string]struct{}{"AC": {}, "AL": {}, "AP": {}, "AM": {}, "BA": {}, "CE": {}, "DF": {}, "ES": {}, "GO": {}, "MA": {}, "MT": {}, "MS": {}, "MG": {}, "PA": {}, "PB": {}, "PR": {}, "PE": {}, "PI": {}, "RJ": {}, "RN": {}, "RS": {}, "RO": {}, "RR": {}, "SC": {}, "SP": {}, "SE": {}, "TO": {}}
+
+// Colunas da tabela remuneracoes_zips com a quantidade de linhas de cada
+// categoria aceita pelo parâmetro "categorias".
+var categoryRowsColumns = map[string]string{
+	"base":      "linhas_base",
+	"outras":    "linhas_outras",
+	"descontos": "linhas_descontos",
+}
+
+// Parâmetros de newSearchParams aplicados às linhas dos contracheques. As rotas
+// que usam apenas os dados mensais dos órgãos não os aceitam (ver
+// rejectRowParams).
+var rowParams = []string{"categorias", "nome", "cargo", "lotacao", "detalhamento_contracheque", "valor_min", "valor_max", "ordenar", "agrupar"}
+
+type searchSort struct {
+	Field string
+	Desc  bool
+}
+
+// newSearchParams cria os filtros de pesquisa a partir dos query params. Se
+// nenhum filtro for informado, retorna nil.
+func newSearchParams(qp url.Values) (*searchParams, error) {
 	var years []string
 	var months []string
 	var agencies []string
-	var types string
 
-	if yearsQp == "" && monthsQp == "" && agenciesQp == "" && categoriesQp == "" && typesQp == "" {
+	yearsQp := qp.Get("anos")
+	monthsQp := qp.Get("meses")
+	agenciesQp := qp.Get("orgaos")
+	categoriesQp := qp.Get("categorias")
+	groupsQp := qp.Get("grupos")
+	ufsQp := qp.Get("ufs")
+	entitiesQp := qp.Get("entidades")
+	nameQp := qp.Get("nome")
+	roleQp := qp.Get("cargo")
+	workplaceQp := qp.Get("lotacao")
+	itemQp := qp.Get("detalhamento_contracheque")
+	minValueQp := qp.Get("valor_min")
+	maxValueQp := qp.Get("valor_max")
+	sortQp := qp.Get("ordenar")
+	groupByQp := qp.Get("agrupar")
+	fromQp := qp.Get(daterange.FromParam)
+	toQp := qp.Get(daterange.ToParam)
+
+	// As linhas dos contracheques não têm tipo, e o parâmetro tipos, que era
+	// ignorado, foi substituído por categorias.
+	if qp.Has("tipos") {
+		return nil, fmt.Errorf("o parâmetro tipos não é suportado! Use o parâmetro categorias.")
+	}
+	if yearsQp == "" && monthsQp == "" && fromQp == "" && toQp == "" && agenciesQp == "" && categoriesQp == "" &&
+		groupsQp == "" && ufsQp == "" && entitiesQp == "" &&
+		nameQp == "" && roleQp == "" && workplaceQp == "" && itemQp == "" &&
+		minValueQp == "" && maxValueQp == "" && sortQp == "" && groupByQp == "" {
 		return nil, nil
 	}
 	if yearsQp != "" {
@@ -39,15 +132,332 @@ func newSearchParams(yearsQp, monthsQp, agenciesQp, categoriesQp, typesQp string
 			}
 		}
 	}
+	dateRange, err := daterange.Parse(fromQp, toQp)
+	if err != nil {
+		return nil, err
+	}
+	// O intervalo é aplicado às consultas como um único filtro, sem ser
+	// convertido em anos.
+	if dateRange != nil && (yearsQp != "" || monthsQp != "") {
+		return nil, fmt.Errorf("os parâmetros de e ate não podem ser usados com anos e meses!")
+	}
 	if agenciesQp != "" {
 		agencies = strings.Split(agenciesQp, ",")
 	}
+	var groups, ufs, entities []string
+	if groupsQp != "" {
+		for _, g := range strings.Split(groupsQp, ",") {
+			j, ok := jurisdiction.Of(g)
+			if !ok {
+				return nil, fmt.Errorf("parâmetro grupo '%s' é inválido!", g)
+			}
+			groups = append(groups, j)
+		}
+	}
+	if ufsQp != "" {
+		for _, uf := range strings.Split(ufsQp, ",") {
+			if _, ok := federativeUnits[strings.ToUpper(uf)]; !ok {
+				return nil, fmt.Errorf("parâmetro uf '%s' é inválido!", uf)
+			}
+			ufs = append(ufs, strings.ToUpper(uf))
+		}
+	}
+	if entitiesQp != "" {
+		for _, e := range strings.Split(entitiesQp, ",") {
+			entities = append(entities, normalizeText(e))
+		}
+	}
+	var categories []string
+	if categoriesQp != "" {
+		for _, c := range strings.Split(categoriesQp, ",") {
+			// "tudo" é equivalente a não filtrar por categoria.
+			if c == "tudo" {
+				categories = nil
+				break
+			}
+			if _, ok := categoryRowsColumns[c]; !ok {
+				return nil, fmt.Errorf("parâmetro categoria '%s' é inválido!", c)
+			}
+			categories = append(categories, c)
+		}
+	}
+	var minValue, maxValue *int64
+	if minValueQp != "" {
+		v, err := parseCents(minValueQp)
+		if err != nil {
+			return nil, fmt.Errorf("parâmetro valor_min '%s' é inválido!", minValueQp)
+		}
+		minValue = &v
+	}
+	if maxValueQp != "" {
+		v, err := parseCents(maxValueQp)
+		if err != nil {
+			return nil, fmt.Errorf("parâmetro valor_max '%s' é inválido!", maxValueQp)
+		}
+		maxValue = &v
+	}
+	if minValue != nil && maxValue != nil && *minValue > *maxValue {
+		return nil, fmt.Errorf("parâmetro valor_min '%s' é maior que valor_max '%s'!", minValueQp, maxValueQp)
+	}
+	var sort *searchSort
+	if sortQp != "" {
+		// O formato é "campo direção", ex: "valor desc". A direção padrão é crescente.
+		fields := strings.Fields(strings.ToLower(sortQp))
+		if len(fields) == 0 || len(fields) > 2 || !sortFields[fields[0]] {
+			return nil, fmt.Errorf("parâmetro ordenar '%s' é inválido!", sortQp)
+		}
+		sort = &searchSort{Field: fields[0]}
+		if len(fields) == 2 {
+			switch fields[1] {
+			case "asc":
+			case "desc":
+				sort.Desc = true
+			default:
+				return nil, fmt.Errorf("parâmetro ordenar '%s' é inválido!", sortQp)
+			}
+		}
+	}
+	if groupByQp != "" && groupByQp != "membro" {
+		return nil, fmt.Errorf("parâmetro agrupar '%s' é inválido!", groupByQp)
+	}
+	// Os contracheques agrupados não possuem detalhamento.
+	if groupByQp != "" && sort != nil && sort.Field == "detalhamento_contracheque" {
+		return nil, fmt.Errorf("parâmetro ordenar '%s' não pode ser usado com agrupar '%s'!", sortQp, groupByQp)
+	}
 
 	return &searchParams{
-		Years:    years,
-		Months:   months,
-		Agencies: agencies,
-		Category: categoriesQp,
-		Types:    types,
+		Years:      years,
+		Months:     months,
+		Range:      dateRange,
+		Agencies:   agencies,
+		Groups:     groups,
+		UFs:        ufs,
+		Entities:   entities,
+		Categories: categories,
+		Name:       normalizeText(nameQp),
+		Role:       normalizeText(roleQp),
+		Workplace:  normalizeText(workplaceQp),
+		Item:       normalizeText(itemQp),
+		MinValue:   minValue,
+		MaxValue:   maxValue,
+		Sort:       sort,
+		GroupBy:    groupByQp,
 	}, nil
 }
+
+// years retorna os anos pedidos, informados no parâmetro anos ou pelo intervalo
+// de/ate.
+func (p *searchParams) years() []int {
+	if p == nil {
+		return nil
+	}
+	if p.Range != nil {
+		return p.Range.Years()
+	}
+	var years []int
+	for _, y := range p.Years {
+		year, _ := strconv.Atoi(y) // os anos já foram validados em newSearchParams
+		years = append(years, year)
+	}
+	return years
+}
+
+// rejectRowParams retorna um erro se algum parâmetro aplicado às linhas dos
+// contracheques foi informado, para que ele não seja ignorado sem aviso nas
+// rotas que usam apenas os dados mensais dos órgãos.
+func rejectRowParams(qp url.Values) error {
+	for _, p := range rowParams {
+		if qp.Has(p) {
+			return fmt.Errorf("o parâmetro %s não é aceito nesta rota!", p)
+		}
+	}
+	return nil
+}
+
+// Verifica se o mês atende aos filtros de meses e de intervalo.
+func (p *searchParams) matchMonth(year, month int) bool {
+	if p == nil {
+		return true
+	}
+	return containsInt(p.Months, month) && p.Range.Contains(year, month)
+}
+
+// Verifica se as linhas dos contracheques devem ser agrupadas por membro.
+func (p *searchParams) groupByMember() bool {
+	return p != nil && p.GroupBy == "membro"
+}
+
+// Retorna as categorias pedidas pelo usuário. Vazio significa todas.
+func (p *searchParams) categories() []string {
+	if p == nil {
+		return nil
+	}
+	return p.Categories
+}
+
+// Verifica se a linha do contracheque atende aos filtros pedidos pelo usuário.
+func (p *searchParams) match(rem searchResult) bool {
+	if p == nil {
+		return true
+	}
+	if len(p.Categories) > 0 && !contains(p.Categories, rem.CategoriaContracheque) {
+		return false
+	}
+	if p.MinValue != nil || p.MaxValue != nil {
+		valor, err := parseCents(rem.Valor)
+		if err != nil || (p.MinValue != nil && valor < *p.MinValue) || (p.MaxValue != nil && valor > *p.MaxValue) {
+			return false
+		}
+	}
+	return matchText(p.Name, &rem.Nome) &&
+		matchText(p.Role, rem.Cargo) &&
+		matchText(p.Workplace, rem.Lotacao) &&
+		matchText(p.Item, &rem.DetalhamentoContracheque)
+}
+
+// Verifica se há filtros que só podem ser aplicados ao ler as linhas dos
+// arquivos, ou seja, que não são considerados na contagem de linhas do banco.
+func (p *searchParams) filtersRows() bool {
+	if p == nil {
+		return false
+	}
+	return p.Name != "" || p.Role != "" || p.Workplace != "" || p.Item != "" ||
+		p.MinValue != nil || p.MaxValue != nil
+}
+
+// Retorna a ordenação pedida pelo usuário, ou nil se os resultados devem
+// seguir a ordem dos arquivos.
+func (p *searchParams) sort() *searchSort {
+	if p == nil {
+		return nil
+	}
+	return p.Sort
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifica se o texto contém o filtro, ignorando maiúsculas e acentos. O
+// filtro deve estar normalizado.
+func matchText(filter string, text *string) bool {
+	if filter == "" {
+		return true
+	}
+	if text == nil {
+		return false
+	}
+	return strings.Contains(normalizeText(*text), filter)
+}
+
+// Converte o texto para minúsculas e remove os acentos, para que "Auxílio"
+// e "auxilio" sejam considerados iguais.
+func normalizeText(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, s)
+	if err != nil {
+		result = s
+	}
+	return strings.ToLower(strings.TrimSpace(result))
+}
+
+// Converte o valor do contracheque, que vem como texto no csv, para número.
+// O valor é lido em centavos (ver parseCents), e o float64 é usado apenas nos
+// arquivos jsonl e parquet.
+func parseValor(valor string) (float64, error) {
+	cents, err := parseCents(valor)
+	if err != nil {
+		return 0, err
+	}
+	return float64(cents) / 100, nil
+}
+
+// parseCents converte o valor do contracheque para centavos, arredondando as
+// casas decimais além da segunda. Os arquivos usam tanto o formato "1234.56"
+// quanto o formato brasileiro "1.234,56". Quando há ponto e vírgula, o último
+// deles é o separador decimal. Quando há apenas um deles, ele é o separador
+// decimal se aparece uma vez e o separador de milhar se aparece mais vezes.
+func parseCents(valor string) (int64, error) {
+	valor = strings.TrimSpace(valor)
+	if valor == "" {
+		return 0, nil
+	}
+	invalid := fmt.Errorf("valor inválido '%s'", valor)
+	// Números em notação científica, como "1.5e+04", são aceitos como antes.
+	if strings.ContainsAny(valor, "eE") {
+		v, err := strconv.ParseFloat(valor, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) || math.Abs(v) >= math.MaxInt64/100 {
+			return 0, invalid
+		}
+		return int64(math.Round(v * 100)), nil
+	}
+	number := valor
+	negative := false
+	if number[0] == '-' || number[0] == '+' {
+		negative = number[0] == '-'
+		number = number[1:]
+	}
+	decimalSep, thousandsSep := ".", ","
+	lastDot, lastComma := strings.LastIndex(number, "."), strings.LastIndex(number, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			decimalSep, thousandsSep = ",", "."
+		}
+	case lastComma >= 0:
+		if strings.Count(number, ",") == 1 {
+			decimalSep, thousandsSep = ",", "."
+		} else {
+			decimalSep, thousandsSep = "", ","
+		}
+	case strings.Count(number, ".") > 1:
+		decimalSep, thousandsSep = "", "."
+	}
+	integer, fraction := number, ""
+	if i := strings.LastIndex(number, decimalSep); decimalSep != "" && i >= 0 {
+		integer, fraction = number[:i], number[i+1:]
+	}
+	if strings.Contains(integer, thousandsSep) {
+		groups := strings.Split(integer, thousandsSep)
+		for i, g := range groups {
+			if len(g) > 3 || len(g) == 0 || (i > 0 && len(g) != 3) {
+				return 0, invalid
+			}
+		}
+		integer = strings.Join(groups, "")
+	}
+	if (integer == "" && fraction == "") || !isDigits(integer) || !isDigits(fraction) {
+		return 0, invalid
+	}
+	var cents int64
+	if integer != "" {
+		v, err := strconv.ParseInt(integer, 10, 64)
+		if err != nil || v >= math.MaxInt64/100 {
+			return 0, invalid
+		}
+		cents = v * 100
+	}
+	fraction += "000"
+	cents += int64(fraction[0]-'0')*10 + int64(fraction[1]-'0')
+	if fraction[2] >= '5' {
+		cents++
+	}
+	if negative {
+		cents = -cents
+	}
+	return cents, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
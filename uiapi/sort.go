@@ -0,0 +1,172 @@
+package uiapi
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// sortedResults guarda as linhas dos contracheques na ordem pedida pelo
+// usuário. Quando há limite, apenas as limit primeiras linhas são mantidas
+// na memória, já que a ordem só é conhecida depois de ler todos os arquivos.
+// Quando há after, apenas as linhas que vêm depois dele são consideradas, de
+// forma que cada página guarda no máximo limit linhas, qualquer que seja a
+// posição dela na pesquisa.
+type sortedResults struct {
+	order searchSort
+	limit int // zero significa sem limite
+	after *sortKey
+	rows  []sortedRow
+}
+
+// sortKey é a posição de uma linha na ordem pedida: o valor do campo
+// ordenado, já convertido para não ser calculado a cada comparação, e a
+// posição da linha nos arquivos, usada para desempate.
+type sortKey struct {
+	Num  float64 `json:"n,omitempty"`
+	Text string  `json:"t,omitempty"`
+	Zip  int     `json:"z,omitempty"`
+	Row  int     `json:"l,omitempty"`
+}
+
+type sortedRow struct {
+	rem    searchResult
+	member memberResult // usado no lugar de rem quando há agrupamento por membro
+	key    sortKey
+}
+
+func newSortedResults(order searchSort, limit int, after *sortKey) *sortedResults {
+	return &sortedResults{order: order, limit: limit, after: after}
+}
+
+// Add adiciona uma linha lida na posição pos dos arquivos.
+func (s *sortedResults) Add(rem searchResult, pos searchCursor) {
+	row := sortedRow{rem: rem, key: sortKey{Zip: pos.Zip, Row: pos.Row}}
+	switch s.order.Field {
+	case "valor":
+		// Linhas com valores inválidos não têm posição na ordem e ficam de
+		// fora, como no filtro de valor_min e valor_max.
+		cents, err := parseCents(rem.Valor)
+		if err != nil {
+			return
+		}
+		row.key.Num = float64(cents)
+	case "mes":
+		row.key.Num = float64(rem.Mes)
+	case "ano":
+		row.key.Num = float64(rem.Ano)
+	case "orgao":
+		row.key.Text = rem.Orgao
+	case "nome":
+		row.key.Text = normalizeText(rem.Nome)
+	case "cargo":
+		if rem.Cargo != nil {
+			row.key.Text = normalizeText(*rem.Cargo)
+		}
+	case "lotacao":
+		if rem.Lotacao != nil {
+			row.key.Text = normalizeText(*rem.Lotacao)
+		}
+	case "detalhamento_contracheque":
+		row.key.Text = normalizeText(rem.DetalhamentoContracheque)
+	}
+	s.add(row)
+}
+
+// AddMember adiciona um contracheque agrupado por membro, na posição pos
+// (ver streamMemberResults). O campo "valor" corresponde ao valor líquido.
+func (s *sortedResults) AddMember(m memberResult, pos searchCursor) {
+	row := sortedRow{member: m, key: sortKey{Zip: pos.Zip, Row: pos.Row}}
+	switch s.order.Field {
+	case "valor":
+		row.key.Num = m.Liquido
+	case "mes":
+		row.key.Num = float64(m.Mes)
+	case "ano":
+		row.key.Num = float64(m.Ano)
+	case "orgao":
+		row.key.Text = m.Orgao
+	case "nome":
+		row.key.Text = normalizeText(m.Nome)
+	case "cargo":
+		if m.Cargo != nil {
+			row.key.Text = normalizeText(*m.Cargo)
+		}
+	case "lotacao":
+		if m.Lotacao != nil {
+			row.key.Text = normalizeText(*m.Lotacao)
+		}
+	}
+	s.add(row)
+}
+
+func (s *sortedResults) add(row sortedRow) {
+	if s.after != nil && !s.before(*s.after, row.key) {
+		return
+	}
+	if s.limit <= 0 {
+		s.rows = append(s.rows, row)
+		return
+	}
+	// Mantemos um heap com a pior linha no topo, para descartá-la quando o
+	// limite for ultrapassado.
+	heap.Push(s, row)
+	if len(s.rows) > s.limit {
+		heap.Pop(s)
+	}
+}
+
+// Results retorna as linhas ordenadas.
+func (s *sortedResults) Results() []searchResult {
+	s.sort()
+	results := make([]searchResult, len(s.rows))
+	for i, row := range s.rows {
+		results[i] = row.rem
+	}
+	return results
+}
+
+// MemberResults retorna os contracheques agrupados por membro ordenados.
+func (s *sortedResults) MemberResults() []memberResult {
+	s.sort()
+	results := make([]memberResult, len(s.rows))
+	for i, row := range s.rows {
+		results[i] = row.member
+	}
+	return results
+}
+
+// Key retorna a chave da linha i de Results ou MemberResults, usada como
+// cursor da página seguinte.
+func (s *sortedResults) Key(i int) sortKey {
+	return s.rows[i].key
+}
+
+func (s *sortedResults) sort() {
+	sort.Slice(s.rows, func(i, j int) bool {
+		return s.before(s.rows[i].key, s.rows[j].key)
+	})
+}
+
+// Verifica se a linha a vem antes da linha b na ordem pedida. Linhas com o
+// mesmo valor seguem a ordem em que foram lidas.
+func (s *sortedResults) before(a, b sortKey) bool {
+	if a.Num != b.Num || a.Text != b.Text {
+		less := a.Num < b.Num || (a.Num == b.Num && a.Text < b.Text)
+		if s.order.Desc {
+			return !less
+		}
+		return less
+	}
+	return a.Zip < b.Zip || (a.Zip == b.Zip && a.Row < b.Row)
+}
+
+// Implementação de heap.Interface, com a pior linha no topo.
+func (s *sortedResults) Len() int           { return len(s.rows) }
+func (s *sortedResults) Less(i, j int) bool { return s.before(s.rows[j].key, s.rows[i].key) }
+func (s *sortedResults) Swap(i, j int)      { s.rows[i], s.rows[j] = s.rows[j], s.rows[i] }
+func (s *sortedResults) Push(x interface{}) { s.rows = append(s.rows, x.(sortedRow)) }
+func (s *sortedResults) Pop() interface{} {
+	row := s.rows[len(s.rows)-1]
+	s.rows = s.rows[:len(s.rows)-1]
+	return row
+}
@@ -0,0 +1,90 @@
+package uiapi
+
+import (
+	"context"
+	"fmt"
+
+	strModels "github.com/dadosjusbr/storage/models"
+)
+
+// Versão da tabela do teto constitucional. Deve ser atualizada sempre que um
+// valor for incluído ou corrigido, para que os clientes saibam com qual tabela
+// os resultados foram calculados.
+const tetoTableVersion = "2025-02"
+
+// Valores do teto constitucional (subsídio mensal dos ministros do STF), em
+// ordem de vigência. Cada valor vale até o início do seguinte.
+var tetoTable = []tetoValue{
+	{Start: "2015-01", Value: 33763.00, Law: "Lei nº 13.091/2015"},
+	{Start: "2019-01", Value: 39293.32, Law: "Lei nº 13.752/2018"},
+	{Start: "2023-04", Value: 41650.92, Law: "Lei nº 14.520/2023"},
+	{Start: "2024-02", Value: 44008.52, Law: "Lei nº 14.520/2023"},
+	{Start: "2025-02", Value: 46366.19, Law: "Lei nº 14.520/2023"},
+}
+
+// Retorna o teto vigente no mês e se existe um valor para ele na tabela.
+func tetoAt(year, month int) (float64, bool) {
+	date := fmt.Sprintf("%04d-%02d", year, month)
+	value, ok := 0.0, false
+	for _, t := range tetoTable {
+		if t.Start > date {
+			break
+		}
+		value, ok = t.Value, true
+	}
+	return value, ok
+}
+
+// Chave dos resumos mensais dos órgãos usados em tetoBreaches.
+func monthlySummaryKey(agency string, year, month int) string {
+	return fmt.Sprintf("%s/%d/%d", agency, year, month)
+}
+
+// tetoBreaches calcula, para cada arquivo de remunerações, quantos membros
+// receberam remuneração líquida acima do teto e a soma do que excedeu. Quando o
+// resumo do mês mostra que a maior remuneração não passa do teto, o arquivo não
+// é lido. Os meses anteriores à tabela do teto são ignorados.
+func (h handler) tetoBreaches(ctx context.Context, results []searchDetails, summaries map[string]*strModels.Summary) ([]tetoBreachMonth, error) {
+	months := []tetoBreachMonth{}
+	for i, r := range results {
+		teto, ok := tetoAt(r.Ano, r.Mes)
+		if !ok {
+			continue
+		}
+		month := tetoBreachMonth{AgencyID: r.Orgao, Year: r.Ano, Month: r.Mes, Teto: teto}
+		if s := summaries[monthlySummaryKey(r.Orgao, r.Ano, r.Mes)]; s != nil && s.Remunerations.Max <= teto {
+			month.Members = s.Count
+		} else {
+			err := h.remunerations.streamMemberResults(ctx, results[i:i+1], nil, searchCursor{}, func(m memberResult, _ searchCursor) error {
+				month.Members++
+				if m.Liquido > teto {
+					month.Breaches++
+					month.Excess += m.Liquido - teto
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if month.Members > 0 {
+			month.Share = float64(month.Breaches) / float64(month.Members)
+		}
+		months = append(months, month)
+	}
+	return months, nil
+}
+
+// Soma os resultados mensais.
+func newTetoBreachTotals(months []tetoBreachMonth) tetoBreachTotals {
+	var totals tetoBreachTotals
+	for _, m := range months {
+		totals.Paychecks += m.Members
+		totals.Breaches += m.Breaches
+		totals.Excess += m.Excess
+	}
+	if totals.Paychecks > 0 {
+		totals.Share = float64(totals.Breaches) / float64(totals.Paychecks)
+	}
+	return totals
+}
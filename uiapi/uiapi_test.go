@@ -1,15 +1,25 @@
 package uiapi
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/dadosjusbr/api/daterange"
+	"github.com/dadosjusbr/api/ipca"
 	"github.com/dadosjusbr/proto/coleta"
 	"github.com/dadosjusbr/storage"
 	"github.com/dadosjusbr/storage/models"
@@ -74,7 +84,7 @@ func (g getSummaryOfAgency) testWhenDataExists(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020", "1")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -136,7 +146,7 @@ func (g getSummaryOfAgency) testWhenDataDoesNotExist(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020", "1")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -168,7 +178,7 @@ func (g getSummaryOfAgency) testWhenYearIsInvalid(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020a", "1")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -200,7 +210,7 @@ func (g getSummaryOfAgency) testWhenMonthIsInvalid(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020", "1a")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -245,7 +255,7 @@ func (g getSalaryOfAgencyMonthYear) testWhenDataExists(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020", "1")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -295,7 +305,7 @@ func (g getSalaryOfAgencyMonthYear) testWhenDataDoesNotExist(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020", "1")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -327,7 +337,7 @@ func (g getSalaryOfAgencyMonthYear) testWhenYearIsInvalid(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020a", "1")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -359,7 +369,7 @@ func (g getSalaryOfAgencyMonthYear) testWhenMonthIsInvalid(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020", "1a")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -400,7 +410,7 @@ func (g getSalaryOfAgencyMonthYear) testWhenProcInfoIsNotNull(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020", "1")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -473,7 +483,7 @@ func (g getBasicInfoOfType) testWhenGroupIsAJurisdiction(t *testing.T) {
 	ctx.SetParamValues("justica-estadual")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -534,7 +544,7 @@ func (g getBasicInfoOfType) testWhenGroupIsAnUF(t *testing.T) {
 	ctx.SetParamValues("PB")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -582,7 +592,7 @@ func (g getBasicInfoOfType) testWhenGroupDoesNotExist(t *testing.T) {
 	ctx.SetParamValues("grupo-que-nao-existe")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -627,7 +637,7 @@ func (g getBasicInfoOfType) testWhenJurisdictionIsInIrregularCase(t *testing.T)
 	ctx.SetParamValues("JuStiCa-esTaDuaL")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -688,7 +698,7 @@ func (g getBasicInfoOfType) testWhenUFIsInIrregularCase(t *testing.T) {
 	ctx.SetParamValues("pB")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -753,7 +763,7 @@ func (g getGeneralSummary) testWhenAllDataAreReturned(t *testing.T) {
 	ctx := e.NewContext(request, recorder)
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -793,7 +803,7 @@ func (g getGeneralSummary) testWhenGetAgenciesCountReturnsAnError(t *testing.T)
 	ctx := e.NewContext(request, recorder)
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -825,7 +835,7 @@ func (g getGeneralSummary) testWhenGetNumberOfMonthsCollectedReturnsAnError(t *t
 	ctx := e.NewContext(request, recorder)
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -858,7 +868,7 @@ func (g getGeneralSummary) testWhenGetNumberOfPaychecksCollectedReturnsAnError(t
 	ctx := e.NewContext(request, recorder)
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -892,7 +902,7 @@ func (g getGeneralSummary) testWhenGetFirstDateWithMonthlyInfoReturnsAnError(t *
 	ctx := e.NewContext(request, recorder)
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -927,7 +937,7 @@ func (g getGeneralSummary) testWhenGetLastDateWithMonthlyInfoReturnsAnError(t *t
 	ctx := e.NewContext(request, recorder)
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -963,7 +973,7 @@ func (g getGeneralSummary) testWhenGetGeneralMonthlyInfoReturnsAnError(t *testin
 	ctx := e.NewContext(request, recorder)
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -981,6 +991,8 @@ func TestGetGeneralRemunerationFromYear(t *testing.T) {
 	t.Run("Test GetGeneralRemunerationFromYear when data exists", tests.testWhenDataExists)
 	t.Run("Test GetGeneralRemunerationFromYear when data does not exist", tests.testWhenDataDoesNotExist)
 	t.Run("Test GetGeneralRemunerationFromYear when year is invalid", tests.testWhenYearIsInvalid)
+	t.Run("Test GetGeneralRemunerationFromYear when values are corrected by IPCA", tests.testWhenValuesAreCorrected)
+	t.Run("Test GetGeneralRemunerationFromYear when correction is invalid", tests.testWhenCorrectionIsInvalid)
 }
 
 type getGenerealRemunerationFromYear struct{}
@@ -1037,7 +1049,7 @@ func (g getGenerealRemunerationFromYear) testWhenDataExists(t *testing.T) {
 	ctx.SetParamValues("2020")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1103,7 +1115,7 @@ func (g getGenerealRemunerationFromYear) testWhenDataDoesNotExist(t *testing.T)
 	ctx.SetParamValues("2020")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1135,7 +1147,7 @@ func (g getGenerealRemunerationFromYear) testWhenYearIsInvalid(t *testing.T) {
 	ctx.SetParamValues("2020a")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1148,6 +1160,57 @@ func (g getGenerealRemunerationFromYear) testWhenYearIsInvalid(t *testing.T) {
 	assert.Equal(t, expectedJson, strings.Trim(recorder.Body.String(), "\n"))
 }
 
+func (g getGenerealRemunerationFromYear) testWhenValuesAreCorrected(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dbMock := database.NewMockInterface(mockCtrl)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+
+	mi := []models.GeneralMonthlyInfo{
+		{Month: 1, Count: 100, BaseRemuneration: 10000, Remunerations: 10000, ItemSummary: models.ItemSummary{"outras": 200}},
+		{Month: 2, Count: 100, BaseRemuneration: 10000, Remunerations: 10000},
+	}
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+	dbMock.EXPECT().GetGeneralMonthlyInfosFromYear(2020).Return(mi, nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/v2/geral/remuneracao/2020?corrigir=ipca&base=2020-02", nil)
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(request, recorder)
+	ctx.SetParamNames("ano")
+	ctx.SetParamValues("2020")
+
+	client, _ := storage.NewClient(dbMock, fsMock)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.V2GetGeneralRemunerationFromYear(ctx)
+
+	var body []mensalRemuneration
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	// IPCA de janeiro de 2020: 5331.42. IPCA de fevereiro de 2020: 5344.75.
+	factor := 5344.75 / 5331.42
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Regexp(t, `^ipca; base=2020-02; serie=2017-12/\d{4}-\d{2}$`, recorder.Header().Get("X-Correcao-Monetaria"))
+	assert.InDelta(t, 10000*factor, body[0].BaseRemuneration, 0.001)
+	assert.InDelta(t, 200*factor, body[0].ItemSummary["outras"], 0.001)
+	assert.Equal(t, 10000.0, body[1].BaseRemuneration)
+}
+
+func (g getGenerealRemunerationFromYear) testWhenCorrectionIsInvalid(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/v2/geral/remuneracao/2020?corrigir=igpm", nil)
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(request, recorder)
+	ctx.SetParamNames("ano")
+	ctx.SetParamValues("2020")
+
+	hand.V2GetGeneralRemunerationFromYear(ctx)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, `"parâmetro corrigir 'igpm' é inválido!"`+"\n", recorder.Body.String())
+}
+
 func TestGetTotalsOfAgencyYear(t *testing.T) {
 	tests := getTotalsOfAgencyYear{}
 	t.Run("test when data exists", tests.testWhenDataExists)
@@ -1186,6 +1249,7 @@ func (g getTotalsOfAgencyYear) testWhenDataExists(t *testing.T) {
 	dbMock.EXPECT().Connect().Return(nil).Times(1)
 	dbMock.EXPECT().GetAgency("tjal").Return(&agencies[0], nil).Times(1)
 	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2020).Return(map[string][]models.AgencyMonthlyInfo{"tjal": agmi}, nil).Times(1)
+	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2019).Return(nil, nil).Times(1)
 	fsMock.EXPECT().GetFile("tjal/datapackage/tjal-2020.zip").Return(agmi[0].Package, nil)
 	dbMock.EXPECT().GetAveragePerCapita("tjal", 2020).Return(&avg, nil).Times(1)
 
@@ -1201,7 +1265,7 @@ func (g getTotalsOfAgencyYear) testWhenDataExists(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1294,6 +1358,7 @@ func (g getTotalsOfAgencyYear) testWhenMonthlyInfoDoesNotExist(t *testing.T) {
 	dbMock.EXPECT().Connect().Return(nil).Times(1)
 	dbMock.EXPECT().GetAgency("tjal").Return(&agency, nil).Times(1)
 	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2020).Return(nil, nil).Times(1)
+	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2019).Return(nil, nil).Times(1)
 	fsMock.EXPECT().GetFile("tjal/datapackage/tjal-2020.zip").Return(nil, nil).Times(1)
 	dbMock.EXPECT().GetAveragePerCapita("tjal", 2020).Return(&avg, nil).Times(1)
 
@@ -1309,7 +1374,7 @@ func (g getTotalsOfAgencyYear) testWhenMonthlyInfoDoesNotExist(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1361,7 +1426,7 @@ func (g getTotalsOfAgencyYear) testWhenYearIsInvalid(t *testing.T) {
 	ctx.SetParamValues("tjal", "2020a")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1447,7 +1512,7 @@ func (g getAnnualSummary) testWhenDataExists(t *testing.T) {
 	ctx.SetParamValues("tjal")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1528,7 +1593,7 @@ func (g getAnnualSummary) testWhenAgencyDoesNotExist(t *testing.T) {
 	ctx.SetParamValues("tjal")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1572,7 +1637,7 @@ func (g getAnnualSummary) testWhenGetAnnualSummaryReturnsError(t *testing.T) {
 	ctx.SetParamValues("tjal")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1616,7 +1681,7 @@ func (g getAnnualSummary) testWhenAgencyDoesNotHaveData(t *testing.T) {
 	ctx.SetParamValues("tjal")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1767,7 +1832,7 @@ func (g getAveragePerAgency) testWhenDataExists(t *testing.T) {
 	ctx.SetParamValues("2020")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1821,7 +1886,7 @@ func (g getAveragePerAgency) testWhenDataDoesNotExist(t *testing.T) {
 	ctx.SetParamValues("2020")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1853,7 +1918,7 @@ func (g getAveragePerAgency) testWhenYearIsInvalid(t *testing.T) {
 	ctx.SetParamValues("2020a")
 
 	client, _ := storage.NewClient(dbMock, fsMock)
-	handler, err := NewHandler(client, nil, nil, "us-east-1", "dadosjusbr_public", loc, []string{}, 100, 100)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1865,3 +1930,2198 @@ func (g getAveragePerAgency) testWhenYearIsInvalid(t *testing.T) {
 	assert.Equal(t, expectedCode, recorder.Code)
 	assert.Equal(t, expectedJson, strings.Trim(recorder.Body.String(), "\n"))
 }
+
+func TestReadRemunerations(t *testing.T) {
+	tests := readRemunerationsTests{}
+	t.Run("Test readRemunerations when all rows are read", tests.testWhenAllRowsAreRead)
+	t.Run("Test readRemunerations when callback stops streaming", tests.testWhenCallbackStopsStreaming)
+	t.Run("Test readRemunerations when file is not a zip", tests.testWhenFileIsNotAZip)
+}
+
+type readRemunerationsTests struct{}
+
+func (r readRemunerationsTests) testWhenAllRowsAreRead(t *testing.T) {
+	var results []searchResult
+	err := readRemunerations(remunerationsZip(t), func(rem searchResult) error {
+		results = append(results, rem)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, "tjal", results[0].Orgao)
+	assert.Equal(t, "subsidio", results[0].DetalhamentoContracheque)
+	assert.Equal(t, "descontos", results[2].CategoriaContracheque)
+}
+
+func (r readRemunerationsTests) testWhenCallbackStopsStreaming(t *testing.T) {
+	var results []searchResult
+	err := readRemunerations(remunerationsZip(t), func(rem searchResult) error {
+		if len(results) == 1 {
+			return errStopStreaming
+		}
+		results = append(results, rem)
+		return nil
+	})
+
+	assert.ErrorIs(t, err, errStopStreaming)
+	assert.Len(t, results, 1)
+}
+
+func (r readRemunerationsTests) testWhenFileIsNotAZip(t *testing.T) {
+	err := readRemunerations([]byte("not a zip"), func(rem searchResult) error {
+		return nil
+	})
+
+	assert.Error(t, err)
+}
+
+func remunerationsZip(t *testing.T) []byte {
+	content := `orgao;mes;ano;matricula;nome;cargo;lotacao;categoria_contracheque;detalhamento_contracheque;valor;desambiguacao_micro;desambiguacao_macro
+tjal;1;2020;123;Maria José;Juiz;Maceió;base;subsidio;35462.22;;
+tjal;1;2020;123;Maria José;Juiz;Maceió;outras;auxílio-alimentação;1000.5;;
+tjal;1;2020;123;Maria José;Juiz;Maceió;descontos;imposto de renda;8000;;
+`
+	return zipWithCSV(t, content)
+}
+
+// Cria um arquivo zip com o CSV de remunerações informado.
+func zipWithCSV(t *testing.T, content string) []byte {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	f, err := w.Create("remuneracoes.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDownloadByUrl(t *testing.T) {
+	tests := downloadByUrl{}
+	t.Run("Test DownloadByUrl when format is invalid", tests.testWhenFormatIsInvalid)
+}
+
+type downloadByUrl struct{}
+
+func (d downloadByUrl) testWhenFormatIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dbMock := database.NewMockInterface(mockCtrl)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+
+	e := echo.New()
+	request := httptest.NewRequest(
+		http.MethodGet,
+		"/uiapi/v2/download?anos=2020&orgaos=tjal&formato=xlsx",
+		nil,
+	)
+	recorder := httptest.NewRecorder()
+	ctx := e.NewContext(request, recorder)
+
+	client, _ := storage.NewClient(dbMock, fsMock)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.DownloadByUrl(ctx)
+
+	expectedCode := http.StatusBadRequest
+	expectedJson := `"formato inválido: 'xlsx'. Os formatos aceitos são csv, jsonl e parquet"`
+
+	assert.Equal(t, expectedCode, recorder.Code)
+	assert.Equal(t, expectedJson, strings.Trim(recorder.Body.String(), "\n"))
+}
+
+func TestRemunerationWriters(t *testing.T) {
+	tests := remunerationWriters{}
+	t.Run("Test csv writer", tests.testCSV)
+	t.Run("Test jsonl writer", tests.testJSONL)
+	t.Run("Test parquet writer", tests.testParquet)
+	t.Run("Test parquet writer when there are no rows", tests.testParquetWithoutRows)
+	t.Run("Test parquet writer when rows are grouped by member", tests.testParquetMembers)
+}
+
+type remunerationWriters struct{}
+
+func (r remunerationWriters) rows() []searchResult {
+	cargo := "Juiz"
+	return []searchResult{
+		{Orgao: "tjal", Mes: 1, Ano: 2020, Nome: "Maria José", Cargo: &cargo, CategoriaContracheque: "base", DetalhamentoContracheque: "subsidio", Valor: "35462.22"},
+		{Orgao: "tjal", Mes: 1, Ano: 2020, Nome: "Maria José", CategoriaContracheque: "outras", DetalhamentoContracheque: "auxílio-alimentação", Valor: "1000,5"},
+	}
+}
+
+func (r remunerationWriters) write(t *testing.T, format string, rows []searchResult) []byte {
+	buf := new(bytes.Buffer)
+	w, err := downloadFormats[format].NewWriter(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rem := range rows {
+		if err := w.Write(rem); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func (r remunerationWriters) testCSV(t *testing.T) {
+	content := string(r.write(t, "csv", r.rows()))
+
+	expected := `orgao,mes,ano,matricula,nome,cargo,lotacao,categoria_contracheque,detalhamento_contracheque,valor,desambiguacao_micro,desambiguacao_macro
+tjal,1,2020,,Maria José,Juiz,,base,subsidio,35462.22,,
+tjal,1,2020,,Maria José,,,outras,auxílio-alimentação,"1000,5",,
+`
+	assert.Equal(t, expected, content)
+}
+
+func (r remunerationWriters) testJSONL(t *testing.T) {
+	content := string(r.write(t, "jsonl", r.rows()))
+
+	expected := `{"orgao":"tjal","mes":1,"ano":2020,"matricula":null,"nome":"Maria José","cargo":"Juiz","lotacao":null,"categoria_contracheque":"base","detalhamento_contracheque":"subsidio","valor":35462.22,"desambiguacao_micro":"","desambiguacao_macro":""}
+{"orgao":"tjal","mes":1,"ano":2020,"matricula":null,"nome":"Maria José","cargo":null,"lotacao":null,"categoria_contracheque":"outras","detalhamento_contracheque":"auxílio-alimentação","valor":1000.5,"desambiguacao_micro":"","desambiguacao_macro":""}
+`
+	assert.Equal(t, expected, content)
+}
+
+func (r remunerationWriters) testParquet(t *testing.T) {
+	content := r.write(t, "parquet", r.rows())
+
+	assert.Equal(t, "PAR1", string(content[:4]))
+	assert.Equal(t, "PAR1", string(content[len(content)-4:]))
+	// O tamanho dos metadados fica antes da assinatura final do arquivo.
+	footerSize := int(content[len(content)-8]) | int(content[len(content)-7])<<8 | int(content[len(content)-6])<<16 | int(content[len(content)-5])<<24
+	footer := content[len(content)-8-footerSize : len(content)-8]
+	assert.Contains(t, string(footer), "detalhamento_contracheque")
+	assert.Contains(t, string(content), "auxílio-alimentação")
+}
+
+func (r remunerationWriters) testParquetWithoutRows(t *testing.T) {
+	content := r.write(t, "parquet", nil)
+
+	assert.Equal(t, "PAR1", string(content[:4]))
+	assert.Equal(t, "PAR1", string(content[len(content)-4:]))
+}
+
+func (r remunerationWriters) testParquetMembers(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := downloadFormats["parquet"].NewMemberWriter(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, w.Write(memberResult{Orgao: "tjal", Mes: 1, Ano: 2020, Nome: "Maria José", Base: 1000, Liquido: 1000}))
+	assert.NoError(t, w.Close())
+	content := buf.Bytes()
+
+	assert.Equal(t, "PAR1", string(content[len(content)-4:]))
+	assert.Contains(t, string(content), "liquido")
+	assert.NotContains(t, string(content), "detalhamento_contracheque")
+}
+
+func TestSearchParamsMatch(t *testing.T) {
+	tests := searchParamsMatch{}
+	t.Run("Test match when there are no filters", tests.testWhenThereAreNoFilters)
+	t.Run("Test match when text filters ignore case and accents", tests.testWhenTextFiltersIgnoreCaseAndAccents)
+	t.Run("Test match when text filter does not match", tests.testWhenTextFilterDoesNotMatch)
+	t.Run("Test match when optional field is empty", tests.testWhenOptionalFieldIsEmpty)
+	t.Run("Test match when category does not match", tests.testWhenCategoryDoesNotMatch)
+	t.Run("Test match when one of the categories matches", tests.testWhenOneOfTheCategoriesMatches)
+	t.Run("Test newSearchParams when category is invalid", tests.testWhenCategoryIsInvalid)
+	t.Run("Test newSearchParams when types are passed", tests.testWhenTypesArePassed)
+	t.Run("Test match when value is in range", tests.testWhenValueIsInRange)
+	t.Run("Test match when value is out of range", tests.testWhenValueIsOutOfRange)
+	t.Run("Test newSearchParams when value range is invalid", tests.testWhenValueRangeIsInvalid)
+	t.Run("Test newSearchParams when sort is invalid", tests.testWhenSortIsInvalid)
+	t.Run("Test newSearchParams when group by is invalid", tests.testWhenGroupByIsInvalid)
+	t.Run("Test newSearchParams when there is a date range", tests.testWhenThereIsADateRange)
+	t.Run("Test newSearchParams when date range is used with years", tests.testWhenDateRangeIsUsedWithYears)
+}
+
+type searchParamsMatch struct{}
+
+func (s searchParamsMatch) row() searchResult {
+	cargo := "Juiz de Direito"
+	lotacao := "Comarca de Maceió"
+	return searchResult{
+		Orgao:                    "tjal",
+		Nome:                     "MARIA JOSÉ",
+		Cargo:                    &cargo,
+		Lotacao:                  &lotacao,
+		CategoriaContracheque:    "outras",
+		DetalhamentoContracheque: "Auxílio-Moradia",
+	}
+}
+
+func (s searchParamsMatch) params(t *testing.T, query string) *searchParams {
+	qp, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := newSearchParams(qp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return params
+}
+
+func (s searchParamsMatch) testWhenThereAreNoFilters(t *testing.T) {
+	params := s.params(t, "")
+
+	assert.Nil(t, params)
+	assert.True(t, params.match(s.row()))
+}
+
+func (s searchParamsMatch) testWhenTextFiltersIgnoreCaseAndAccents(t *testing.T) {
+	params := s.params(t, "nome=maria jose&cargo=JUIZ&lotacao=maceio&detalhamento_contracheque=auxilio-moradia")
+
+	assert.True(t, params.match(s.row()))
+}
+
+func (s searchParamsMatch) testWhenTextFilterDoesNotMatch(t *testing.T) {
+	params := s.params(t, "detalhamento_contracheque=auxilio-alimentacao")
+
+	assert.False(t, params.match(s.row()))
+}
+
+func (s searchParamsMatch) testWhenOptionalFieldIsEmpty(t *testing.T) {
+	params := s.params(t, "lotacao=maceio")
+	row := s.row()
+	row.Lotacao = nil
+
+	assert.False(t, params.match(row))
+}
+
+func (s searchParamsMatch) testWhenCategoryDoesNotMatch(t *testing.T) {
+	params := s.params(t, "categorias=base&nome=maria")
+
+	assert.False(t, params.match(s.row()))
+}
+
+func (s searchParamsMatch) testWhenOneOfTheCategoriesMatches(t *testing.T) {
+	params := s.params(t, "categorias=base,outras")
+
+	assert.Equal(t, []string{"base", "outras"}, params.Categories)
+	assert.True(t, params.match(s.row()))
+}
+
+func (s searchParamsMatch) testWhenCategoryIsInvalid(t *testing.T) {
+	_, err := newSearchParams(url.Values{"categorias": {"base,salario"}})
+
+	assert.EqualError(t, err, "parâmetro categoria 'salario' é inválido!")
+}
+
+func (s searchParamsMatch) testWhenTypesArePassed(t *testing.T) {
+	_, err := newSearchParams(url.Values{"tipos": {"membro"}})
+
+	assert.EqualError(t, err, "o parâmetro tipos não é suportado! Use o parâmetro categorias.")
+}
+
+func (s searchParamsMatch) testWhenValueIsInRange(t *testing.T) {
+	params := s.params(t, "valor_min=1000&valor_max=5000,5")
+	row := s.row()
+	row.Valor = "4377.66"
+
+	assert.True(t, params.match(row))
+}
+
+func (s searchParamsMatch) testWhenValueIsOutOfRange(t *testing.T) {
+	params := s.params(t, "valor_min=1000")
+	row := s.row()
+	row.Valor = "999.99"
+
+	assert.False(t, params.match(row))
+}
+
+func (s searchParamsMatch) testWhenValueRangeIsInvalid(t *testing.T) {
+	_, err := newSearchParams(url.Values{"valor_min": {"abc"}})
+	assert.EqualError(t, err, "parâmetro valor_min 'abc' é inválido!")
+
+	_, err = newSearchParams(url.Values{"valor_min": {"10"}, "valor_max": {"5"}})
+	assert.EqualError(t, err, "parâmetro valor_min '10' é maior que valor_max '5'!")
+}
+
+func (s searchParamsMatch) testWhenSortIsInvalid(t *testing.T) {
+	_, err := newSearchParams(url.Values{"ordenar": {"salario desc"}})
+	assert.EqualError(t, err, "parâmetro ordenar 'salario desc' é inválido!")
+
+	_, err = newSearchParams(url.Values{"ordenar": {"valor para baixo"}})
+	assert.EqualError(t, err, "parâmetro ordenar 'valor para baixo' é inválido!")
+}
+
+func (s searchParamsMatch) testWhenGroupByIsInvalid(t *testing.T) {
+	_, err := newSearchParams(url.Values{"agrupar": {"orgao"}})
+	assert.EqualError(t, err, "parâmetro agrupar 'orgao' é inválido!")
+
+	_, err = newSearchParams(url.Values{"agrupar": {"membro"}, "ordenar": {"detalhamento_contracheque"}})
+	assert.EqualError(t, err, "parâmetro ordenar 'detalhamento_contracheque' não pode ser usado com agrupar 'membro'!")
+
+	params, err := newSearchParams(url.Values{"agrupar": {"membro"}})
+	assert.NoError(t, err)
+	assert.True(t, params.groupByMember())
+}
+
+func (s searchParamsMatch) testWhenThereIsADateRange(t *testing.T) {
+	params := s.params(t, "de=2021-11&ate=2022-02")
+
+	assert.Empty(t, params.Years)
+	assert.Equal(t, []int{2021, 2022}, params.years())
+	assert.Equal(t, &daterange.Range{From: daterange.Month{Year: 2021, Month: 11}, To: daterange.Month{Year: 2022, Month: 2}}, params.Range)
+	assert.True(t, params.matchMonth(2021, 12))
+	assert.True(t, params.matchMonth(2022, 2))
+	assert.False(t, params.matchMonth(2021, 10))
+	assert.False(t, params.matchMonth(2022, 3))
+}
+
+func (s searchParamsMatch) testWhenDateRangeIsUsedWithYears(t *testing.T) {
+	_, err := newSearchParams(url.Values{"de": {"2021-11"}, "anos": {"2021"}})
+	assert.EqualError(t, err, "os parâmetros de e ate não podem ser usados com anos e meses!")
+
+	_, err = newSearchParams(url.Values{"de": {"2021-13"}})
+	assert.EqualError(t, err, "parâmetro de '2021-13' é inválido! Use o formato AAAA-MM.")
+
+	_, err = newSearchParams(url.Values{"de": {"0001-01"}})
+	assert.ErrorContains(t, err, "parâmetro de '0001-01' é inválido! Os dados estão disponíveis de 2018-01 a")
+}
+
+func TestSortedResults(t *testing.T) {
+	tests := sortedResultsTests{}
+	t.Run("Test sortedResults when there is a limit", tests.testWhenThereIsALimit)
+	t.Run("Test sortedResults when there is no limit", tests.testWhenThereIsNoLimit)
+	t.Run("Test sortedResults when rows are grouped by member", tests.testWhenRowsAreGroupedByMember)
+	t.Run("Test sortedResults when a value is invalid", tests.testWhenAValueIsInvalid)
+}
+
+type sortedResultsTests struct{}
+
+func (s sortedResultsTests) rows() []searchResult {
+	return []searchResult{
+		{Nome: "Carlos", Valor: "1500.10"},
+		{Nome: "Ana", Valor: "35462.22"},
+		{Nome: "Bruno", Valor: "200"},
+		{Nome: "Álvaro", Valor: "8000,5"},
+		{Nome: "Daniela", Valor: "35462.22"},
+	}
+}
+
+func (s sortedResultsTests) testWhenThereIsALimit(t *testing.T) {
+	sorted := newSortedResults(searchSort{Field: "valor", Desc: true}, 3, nil)
+	for i, rem := range s.rows() {
+		sorted.Add(rem, searchCursor{Row: i})
+	}
+
+	var names []string
+	for _, rem := range sorted.Results() {
+		names = append(names, rem.Nome)
+	}
+	assert.Equal(t, []string{"Ana", "Daniela", "Álvaro"}, names)
+}
+
+func (s sortedResultsTests) testWhenThereIsNoLimit(t *testing.T) {
+	sorted := newSortedResults(searchSort{Field: "nome"}, 0, nil)
+	for i, rem := range s.rows() {
+		sorted.Add(rem, searchCursor{Row: i})
+	}
+
+	var names []string
+	for _, rem := range sorted.Results() {
+		names = append(names, rem.Nome)
+	}
+	assert.Equal(t, []string{"Álvaro", "Ana", "Bruno", "Carlos", "Daniela"}, names)
+}
+
+func (s sortedResultsTests) testWhenRowsAreGroupedByMember(t *testing.T) {
+	sorted := newSortedResults(searchSort{Field: "valor", Desc: true}, 2, nil)
+	sorted.AddMember(memberResult{Nome: "Ana", Liquido: 100}, searchCursor{Row: 0})
+	sorted.AddMember(memberResult{Nome: "Bruno", Liquido: 300}, searchCursor{Row: 1})
+	sorted.AddMember(memberResult{Nome: "Carlos", Liquido: 200}, searchCursor{Zip: 1})
+
+	var names []string
+	for _, m := range sorted.MemberResults() {
+		names = append(names, m.Nome)
+	}
+	assert.Equal(t, []string{"Bruno", "Carlos"}, names)
+}
+
+func (s sortedResultsTests) testWhenAValueIsInvalid(t *testing.T) {
+	sorted := newSortedResults(searchSort{Field: "valor"}, 0, nil)
+	for i, rem := range append(s.rows(), searchResult{Nome: "Eva", Valor: "R$ 10"}) {
+		sorted.Add(rem, searchCursor{Row: i})
+	}
+
+	var names []string
+	for _, rem := range sorted.Results() {
+		names = append(names, rem.Nome)
+	}
+	assert.Equal(t, []string{"Bruno", "Carlos", "Álvaro", "Ana", "Daniela"}, names)
+}
+
+func TestParseCents(t *testing.T) {
+	tests := parseCentsTests{}
+	t.Run("Test parseCents when the value uses a decimal point", tests.testWhenTheValueUsesADecimalPoint)
+	t.Run("Test parseCents when the value is in the Brazilian format", tests.testWhenTheValueIsInTheBrazilianFormat)
+	t.Run("Test parseCents when the value has more than two decimals", tests.testWhenTheValueHasMoreThanTwoDecimals)
+	t.Run("Test parseCents when the value is invalid", tests.testWhenTheValueIsInvalid)
+}
+
+type parseCentsTests struct{}
+
+func (p parseCentsTests) assertCents(t *testing.T, valor string, expected int64) {
+	cents, err := parseCents(valor)
+	assert.NoError(t, err, valor)
+	assert.Equal(t, expected, cents, valor)
+}
+
+func (p parseCentsTests) testWhenTheValueUsesADecimalPoint(t *testing.T) {
+	p.assertCents(t, "35462.22", 3546222)
+	p.assertCents(t, "1,234.56", 123456)
+	p.assertCents(t, "200", 20000)
+	p.assertCents(t, "-0.1", -10)
+	p.assertCents(t, "1.5e+04", 1500000)
+	p.assertCents(t, "", 0)
+}
+
+func (p parseCentsTests) testWhenTheValueIsInTheBrazilianFormat(t *testing.T) {
+	p.assertCents(t, "1.234,56", 123456)
+	p.assertCents(t, "1.234.567,8", 123456780)
+	p.assertCents(t, "8000,5", 800050)
+	p.assertCents(t, "1.234.567", 123456700)
+}
+
+func (p parseCentsTests) testWhenTheValueHasMoreThanTwoDecimals(t *testing.T) {
+	p.assertCents(t, "0.105", 11)
+	p.assertCents(t, "1234.5649", 123456)
+	p.assertCents(t, "-0.995", -100)
+}
+
+func (p parseCentsTests) testWhenTheValueIsInvalid(t *testing.T) {
+	for _, valor := range []string{"abc", "R$ 10", "1.2.3", "12,34,5", "1,234.5.6", "-", "99999999999999999999"} {
+		_, err := parseCents(valor)
+		assert.EqualError(t, err, fmt.Sprintf("valor inválido '%s'", valor))
+	}
+}
+
+func TestSearchCursor(t *testing.T) {
+	tests := searchCursorTests{}
+	t.Run("Test searchCursor when it is encoded and decoded", tests.testWhenItIsEncodedAndDecoded)
+	t.Run("Test searchCursor when it is empty", tests.testWhenItIsEmpty)
+	t.Run("Test searchCursor when it is invalid", tests.testWhenItIsInvalid)
+	t.Run("Test SearchByUrl when cursor is invalid", tests.testSearchByUrlWhenCursorIsInvalid)
+}
+
+type searchCursorTests struct{}
+
+func (s searchCursorTests) testWhenItIsEncodedAndDecoded(t *testing.T) {
+	cursor := searchCursor{Zip: 3, Row: 1542}
+
+	decoded, err := decodeSearchCursor(cursor.encode())
+
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	sorted := searchCursor{After: &sortKey{Num: 3546222, Zip: 1, Row: 7}}
+
+	decoded, err = decodeSearchCursor(sorted.encode())
+
+	assert.NoError(t, err)
+	assert.Equal(t, sorted, decoded)
+}
+
+func (s searchCursorTests) testWhenItIsEmpty(t *testing.T) {
+	decoded, err := decodeSearchCursor("")
+
+	assert.NoError(t, err)
+	assert.Equal(t, searchCursor{}, decoded)
+}
+
+func (s searchCursorTests) testWhenItIsInvalid(t *testing.T) {
+	_, err := decodeSearchCursor("abc$")
+	assert.EqualError(t, err, "parâmetro proximo 'abc$' é inválido!")
+
+	negative := searchCursor{Zip: -1}.encode()
+	_, err = decodeSearchCursor(negative)
+	assert.EqualError(t, err, fmt.Sprintf("parâmetro proximo '%s' é inválido!", negative))
+
+	// Cursor de posição, usado antes das páginas ordenadas usarem a chave
+	// da última linha.
+	offset := base64.RawURLEncoding.EncodeToString([]byte(`{"o":100}`))
+	_, err = decodeSearchCursor(offset)
+	assert.EqualError(t, err, fmt.Sprintf("parâmetro proximo '%s' é inválido!", offset))
+}
+
+func (s searchCursorTests) testSearchByUrlWhenCursorIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dbMock := database.NewMockInterface(mockCtrl)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+
+	e := echo.New()
+	request := httptest.NewRequest(
+		http.MethodGet,
+		"/uiapi/v2/pesquisar?anos=2020&proximo=abc$",
+		nil,
+	)
+	recorder := httptest.NewRecorder()
+	ctx := e.NewContext(request, recorder)
+
+	client, _ := storage.NewClient(dbMock, fsMock)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.SearchByUrl(ctx)
+
+	expectedCode := http.StatusBadRequest
+	expectedJson := `"parâmetro proximo 'abc$' é inválido!"`
+
+	assert.Equal(t, expectedCode, recorder.Code)
+	assert.Equal(t, expectedJson, strings.Trim(recorder.Body.String(), "\n"))
+}
+
+func TestRemunerationQuery(t *testing.T) {
+	tests := remunerationQuery{}
+	t.Run("Test remunerationQuery when there are no filters", tests.testWhenThereAreNoFilters)
+	t.Run("Test remunerationQuery when there are only row filters", tests.testWhenThereAreOnlyRowFilters)
+	t.Run("Test remunerationQuery when there are agency and category filters", tests.testWhenThereAreAgencyAndCategoryFilters)
+	t.Run("Test remunerationQuery when there are many categories", tests.testWhenThereAreManyCategories)
+	t.Run("Test remunerationQuery when no agency matches the groups", tests.testWhenNoAgencyMatchesTheGroups)
+	t.Run("Test remunerationQuery when there is a date range", tests.testWhenThereIsADateRange)
+	t.Run("Test zipsForLimit", tests.testZipsForLimit)
+}
+
+type remunerationQuery struct{}
+
+func (r remunerationQuery) testWhenThereAreNoFilters(t *testing.T) {
+	query := postgresDB{}.remunerationQuery(nil)
+
+	assert.NotContains(t, query, "WHERE")
+	assert.Contains(t, query, "SUM(linhas_descontos + linhas_base + linhas_outras) OVER (ORDER BY ano, mes, id_orgao ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) as linhas_acumuladas")
+	assert.True(t, strings.HasSuffix(query, "ORDER BY ano, mes, id_orgao"))
+}
+
+func (r remunerationQuery) testWhenThereAreOnlyRowFilters(t *testing.T) {
+	params := &searchParams{Name: "maria"}
+
+	query := postgresDB{}.remunerationQuery(params)
+
+	assert.NotContains(t, query, "WHERE")
+	assert.Empty(t, postgresDB{}.arguments(params))
+}
+
+func (r remunerationQuery) testWhenThereAreAgencyAndCategoryFilters(t *testing.T) {
+	params := &searchParams{Years: []string{"2020"}, Agencies: []string{"tjal", "mpal"}, Categories: []string{"base"}}
+
+	query := postgresDB{}.remunerationQuery(params)
+
+	assert.Contains(t, query, "linhas_base as linhas")
+	assert.Contains(t, query, "WHERE ano IN ($1) AND id_orgao IN ($2,$3) ORDER BY ano, mes, id_orgao")
+	assert.Equal(t, []interface{}{"2020", "tjal", "mpal"}, postgresDB{}.arguments(params))
+}
+
+func (r remunerationQuery) testWhenThereAreManyCategories(t *testing.T) {
+	params := &searchParams{Categories: []string{"base", "outras"}}
+
+	query := postgresDB{}.remunerationQuery(params)
+
+	assert.Contains(t, query, "SUM(linhas_base + linhas_outras) OVER")
+	assert.NotContains(t, query, "WHERE")
+}
+
+func (r remunerationQuery) testWhenNoAgencyMatchesTheGroups(t *testing.T) {
+	params := &searchParams{Years: []string{"2020"}, Agencies: []string{}}
+
+	query := postgresDB{}.remunerationQuery(params)
+
+	assert.Contains(t, query, "WHERE ano IN ($1) AND FALSE ORDER BY")
+	assert.Equal(t, []interface{}{"2020"}, postgresDB{}.arguments(params))
+}
+
+func (r remunerationQuery) testWhenThereIsADateRange(t *testing.T) {
+	params := searchParamsMatch{}.params(t, "de=2021-11&ate=2022-02&orgaos=tjal")
+
+	query := postgresDB{}.remunerationQuery(params)
+
+	assert.Contains(t, query, "WHERE id_orgao IN ($1) AND (ano, mes) BETWEEN ($2, $3) AND ($4, $5) ORDER BY")
+	assert.NotContains(t, query, "ano IN")
+	assert.Equal(t, []interface{}{"tjal", 2021, 11, 2022, 2}, postgresDB{}.arguments(params))
+}
+
+func (r remunerationQuery) testZipsForLimit(t *testing.T) {
+	results := []searchDetails{
+		{Orgao: "tjal", Linhas: 10, LinhasAcumuladas: 10},
+		{Orgao: "mpal", Linhas: 10, LinhasAcumuladas: 20},
+		{Orgao: "tjpb", Linhas: 10, LinhasAcumuladas: 30},
+		{Orgao: "mppb", Linhas: 10, LinhasAcumuladas: 40},
+	}
+
+	assert.Len(t, zipsForLimit(results, nil, searchCursor{}, 15), 3)
+	assert.Len(t, zipsForLimit(results, nil, searchCursor{Zip: 1, Row: 4}, 15), 4)
+	assert.Len(t, zipsForLimit(results, nil, searchCursor{}, 100), 4)
+	assert.Len(t, zipsForLimit(results, nil, searchCursor{}, 0), 4)
+	assert.Len(t, zipsForLimit(results, &searchParams{Name: "maria"}, searchCursor{}, 15), 4)
+}
+
+func TestLocalZipStore(t *testing.T) {
+	tests := localZipStore{}
+	t.Run("Test search results when zips are in a local directory", tests.testSearchResults)
+	t.Run("Test search results when paging with the cursor", tests.testSearchResultsWithCursor)
+	t.Run("Test sorted search results when paging with the cursor", tests.testSortedSearchResultsWithCursor)
+	t.Run("Test streamed results when zips are in a local directory", tests.testStreamSearchResults)
+	t.Run("Test search results grouped by member", tests.testMemberResults)
+	t.Run("Test search results grouped by member with cursor", tests.testMemberResultsWithCursor)
+	t.Run("Test download grouped by member", tests.testWriteMemberResults)
+	t.Run("Test bufferSearchResults", tests.testBufferSearchResults)
+	t.Run("Test GetZip when file does not exist", tests.testWhenFileDoesNotExist)
+	t.Run("Test zipKey", tests.testZipKey)
+}
+
+type localZipStore struct{}
+
+// Cria um handler que lê dois arquivos zip, de tjal e mpal, em um diretório local.
+func (l localZipStore) handler(t *testing.T) (*handler, []searchDetails) {
+	dir := t.TempDir()
+	for _, name := range []string{"tjal/tjal-2020-1.zip", "mpal/mpal-2020-1.zip"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), remunerationsZip(t), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler, err := NewHandler(nil, nil, nil, NewDirZipStore(dir, "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []searchDetails{
+		{Orgao: "tjal", Mes: 1, Ano: 2020, Base: 1, Outras: 1, Descontos: 1, Linhas: 3, LinhasAcumuladas: 3, ZipUrl: "https://dadosjusbr_public.s3.amazonaws.com/tjal/tjal-2020-1.zip"},
+		{Orgao: "mpal", Mes: 1, Ano: 2020, Base: 1, Outras: 1, Descontos: 1, Linhas: 3, LinhasAcumuladas: 6, ZipUrl: "https://dadosjusbr_public.s3.amazonaws.com/mpal/mpal-2020-1.zip"},
+	}
+	return handler, results
+}
+
+func (l localZipStore) testSearchResults(t *testing.T) {
+	handler, results := l.handler(t)
+
+	remunerations, numRows, next, err := handler.getSearchResults(100, &searchParams{Categories: []string{"base"}}, results, searchCursor{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, numRows)
+	assert.Nil(t, next)
+	assert.Len(t, remunerations, 2)
+	assert.Equal(t, "subsidio", remunerations[0].DetalhamentoContracheque)
+}
+
+func (l localZipStore) testSearchResultsWithCursor(t *testing.T) {
+	handler, results := l.handler(t)
+
+	var pages [][]searchResult
+	cursor := searchCursor{}
+	for {
+		remunerations, _, next, err := handler.getSearchResults(4, nil, results, cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, remunerations)
+		if next == nil {
+			break
+		}
+		cursor = *next
+	}
+
+	assert.Len(t, pages, 2)
+	assert.Len(t, pages[0], 4)
+	assert.Len(t, pages[1], 2)
+	assert.Equal(t, "outras", pages[1][0].CategoriaContracheque)
+}
+
+func (l localZipStore) testSortedSearchResultsWithCursor(t *testing.T) {
+	handler, results := l.handler(t)
+	params := &searchParams{Sort: &searchSort{Field: "valor", Desc: true}}
+
+	var values []string
+	cursor := searchCursor{}
+	for {
+		remunerations, _, next, err := handler.getSearchResults(1, params, results, cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, rem := range remunerations {
+			values = append(values, rem.Valor)
+		}
+		if next == nil {
+			break
+		}
+		assert.NotNil(t, next.After)
+		cursor = *next
+	}
+
+	// Cada página começa depois da chave da anterior, mesmo com valores repetidos.
+	assert.Equal(t, []string{"35462.22", "35462.22", "8000", "8000", "1000.5", "1000.5"}, values)
+}
+
+func (l localZipStore) testStreamSearchResults(t *testing.T) {
+	handler, results := l.handler(t)
+
+	var values []string
+	err := handler.streamSearchResults(context.Background(), 0, &searchParams{Sort: &searchSort{Field: "valor", Desc: true}}, results, func(rem searchResult) error {
+		values = append(values, rem.Valor)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"35462.22", "35462.22", "8000", "8000", "1000.5", "1000.5"}, values)
+}
+
+func (l localZipStore) testMemberResults(t *testing.T) {
+	handler, results := l.handler(t)
+
+	members, numRows, next, err := handler.remunerations.getMemberResults(100, &searchParams{GroupBy: "membro"}, results, searchCursor{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, numRows)
+	assert.Nil(t, next)
+	assert.Len(t, members, 2)
+	assert.Equal(t, "123", *members[0].Matricula)
+	assert.Equal(t, 35462.22, members[0].Base)
+	assert.Equal(t, 1000.5, members[0].Outras)
+	assert.Equal(t, 8000.0, members[0].Descontos)
+	assert.InDelta(t, 28462.72, members[0].Liquido, 0.001)
+}
+
+func (l localZipStore) testMemberResultsWithCursor(t *testing.T) {
+	handler, results := l.handler(t)
+	params := &searchParams{GroupBy: "membro", Categories: []string{"base"}}
+
+	first, _, next, err := handler.remunerations.getMemberResults(1, params, results, searchCursor{})
+	assert.NoError(t, err)
+	assert.Len(t, first, 1)
+	assert.Equal(t, &searchCursor{Zip: 1}, next)
+	second, _, next, err := handler.remunerations.getMemberResults(1, params, results, *next)
+
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+	assert.Len(t, second, 1)
+	assert.Equal(t, 35462.22, second[0].Liquido)
+}
+
+func (l localZipStore) testWriteMemberResults(t *testing.T) {
+	handler, results := l.handler(t)
+	buf := new(bytes.Buffer)
+
+	rows, err := handler.writeSearchResults(context.Background(), buf, downloadFormats["csv"], 1, &searchParams{GroupBy: "membro"}, results, func() {})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rows)
+	assert.Equal(t, "orgao,mes,ano,matricula,nome,cargo,lotacao,base,outras,descontos,liquido\ntjal,1,2020,123,Maria José,Juiz,Maceió,35462.22,1000.5,8000,28462.72\n", buf.String())
+}
+
+func (l localZipStore) testBufferSearchResults(t *testing.T) {
+	handler, results := l.handler(t)
+	params := &searchParams{GroupBy: "membro"}
+
+	buf, rows, err := handler.bufferSearchResults(context.Background(), downloadFormats["csv"], 2, params, results)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rows)
+	assert.Equal(t, 3, strings.Count(buf.String(), "\n"))
+
+	_, _, err = handler.bufferSearchResults(context.Background(), downloadFormats["csv"], 1, params, results)
+
+	assert.ErrorIs(t, err, errDownloadTooLarge)
+}
+
+func (l localZipStore) testWhenFileDoesNotExist(t *testing.T) {
+	store := NewDirZipStore(t.TempDir(), "dadosjusbr_public")
+
+	_, err := store.GetZip(context.Background(), "https://dadosjusbr_public.s3.amazonaws.com/../../etc/passwd")
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func (l localZipStore) testZipKey(t *testing.T) {
+	assert.Equal(t, "tjal/tjal-2020-1.zip", zipKey("https://dadosjusbr_public.s3.amazonaws.com/tjal/tjal-2020-1.zip", "dadosjusbr_public"))
+	assert.Equal(t, "tjal/tjal-2020-1.zip", zipKey("http://localhost:9000/dadosjusbr_public/tjal/tjal-2020-1.zip", "dadosjusbr_public"))
+	assert.Equal(t, "tjal/tjal-2020-1.zip", zipKey("tjal/tjal-2020-1.zip", "dadosjusbr_public"))
+}
+
+func TestCachedZipStore(t *testing.T) {
+	tests := cachedZipStoreTests{}
+	t.Run("Test cached zip store when file is requested twice", tests.testWhenFileIsRequestedTwice)
+	t.Run("Test cached zip store when cache is full", tests.testWhenCacheIsFull)
+	t.Run("Test cached zip store when file is larger than cache", tests.testWhenFileIsLargerThanCache)
+	t.Run("Test cached zip store when store returns an error", tests.testWhenStoreReturnsAnError)
+	t.Run("Test cached zip store when the first request is cancelled", tests.testWhenTheFirstRequestIsCancelled)
+}
+
+type cachedZipStoreTests struct{}
+
+// ZipStore que conta quantas vezes cada arquivo foi buscado.
+type countingZipStore struct {
+	files map[string][]byte
+	calls map[string]int
+}
+
+func (c *countingZipStore) GetZip(ctx context.Context, zipURL string) ([]byte, error) {
+	c.calls[zipURL]++
+	content, ok := c.files[zipURL]
+	if !ok {
+		return nil, fmt.Errorf("file not found: %s", zipURL)
+	}
+	return content, nil
+}
+
+// ZipStore que só retorna o arquivo quando release é fechado, e falha se o
+// contexto da busca for cancelado antes disso.
+type blockingZipStore struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingZipStore) GetZip(ctx context.Context, zipURL string) ([]byte, error) {
+	close(b.started)
+	select {
+	case <-b.release:
+		return []byte(zipURL), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c cachedZipStoreTests) testWhenTheFirstRequestIsCancelled(t *testing.T) {
+	store := &blockingZipStore{started: make(chan struct{}), release: make(chan struct{})}
+	cache := NewCachedZipStore(store, 100, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, err := cache.GetZip(ctx, "a.zip")
+		firstErr <- err
+	}()
+	<-store.started
+	second := make(chan []byte)
+	go func() {
+		content, _ := cache.GetZip(context.Background(), "a.zip")
+		second <- content
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+	close(store.release)
+
+	assert.Equal(t, []byte("a.zip"), <-second)
+}
+
+func (c cachedZipStoreTests) store() *countingZipStore {
+	return &countingZipStore{
+		files: map[string][]byte{
+			"a.zip": bytes.Repeat([]byte("a"), 40),
+			"b.zip": bytes.Repeat([]byte("b"), 40),
+			"c.zip": bytes.Repeat([]byte("c"), 40),
+		},
+		calls: map[string]int{},
+	}
+}
+
+func (c cachedZipStoreTests) testWhenFileIsRequestedTwice(t *testing.T) {
+	store := c.store()
+	cache := NewCachedZipStore(store, 100, nil)
+
+	first, err := cache.GetZip(context.Background(), "a.zip")
+	assert.NoError(t, err)
+	second, err := cache.GetZip(context.Background(), "a.zip")
+	assert.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, store.calls["a.zip"])
+	assert.Equal(t, zipCacheStats{Hits: 1, Misses: 1, Entries: 1, Bytes: 40}, cache.(*cachedZipStore).Stats())
+}
+
+func (c cachedZipStoreTests) testWhenCacheIsFull(t *testing.T) {
+	store := c.store()
+	cache := NewCachedZipStore(store, 100, nil)
+	ctx := context.Background()
+
+	cache.GetZip(ctx, "a.zip")
+	cache.GetZip(ctx, "b.zip")
+	cache.GetZip(ctx, "a.zip") // a.zip passa a ser o mais recente
+	cache.GetZip(ctx, "c.zip") // b.zip é descartado
+	cache.GetZip(ctx, "a.zip")
+	cache.GetZip(ctx, "b.zip")
+
+	assert.Equal(t, 1, store.calls["a.zip"])
+	assert.Equal(t, 2, store.calls["b.zip"])
+	assert.Equal(t, zipCacheStats{Hits: 2, Misses: 4, Evictions: 2, Entries: 2, Bytes: 80}, cache.(*cachedZipStore).Stats())
+}
+
+func (c cachedZipStoreTests) testWhenFileIsLargerThanCache(t *testing.T) {
+	store := c.store()
+	cache := NewCachedZipStore(store, 10, nil)
+
+	cache.GetZip(context.Background(), "a.zip")
+	cache.GetZip(context.Background(), "a.zip")
+
+	assert.Equal(t, 2, store.calls["a.zip"])
+	assert.Equal(t, zipCacheStats{Misses: 2}, cache.(*cachedZipStore).Stats())
+}
+
+func (c cachedZipStoreTests) testWhenStoreReturnsAnError(t *testing.T) {
+	store := c.store()
+	cache := NewCachedZipStore(store, 100, nil)
+
+	_, err := cache.GetZip(context.Background(), "d.zip")
+	assert.Error(t, err)
+	_, err = cache.GetZip(context.Background(), "d.zip")
+	assert.Error(t, err)
+
+	assert.Equal(t, 2, store.calls["d.zip"])
+}
+
+func TestResolveAgencyGroups(t *testing.T) {
+	tests := resolveAgencyGroups{}
+	t.Run("Test resolveAgencyGroups when there are no groups", tests.testWhenThereAreNoGroups)
+	t.Run("Test resolveAgencyGroups when there are groups and ufs", tests.testWhenThereAreGroupsAndUFs)
+	t.Run("Test resolveAgencyGroups when there are entities and agencies", tests.testWhenThereAreEntitiesAndAgencies)
+	t.Run("Test resolveAgencyGroups when no agency matches", tests.testWhenNoAgencyMatches)
+	t.Run("Test resolveAgencyGroups when group is invalid", tests.testWhenGroupIsInvalid)
+}
+
+type resolveAgencyGroups struct{}
+
+func (r resolveAgencyGroups) agencies() []models.Agency {
+	return []models.Agency{
+		{ID: "tjal", Entity: "Tribunal", UF: "AL", Type: "Estadual"},
+		{ID: "tjpb", Entity: "Tribunal", UF: "PB", Type: "Estadual"},
+		{ID: "mpal", Entity: "Ministério", UF: "AL", Type: "Ministério"},
+		{ID: "trt13", Entity: "Tribunal", UF: "PB", Type: "Trabalho"},
+	}
+}
+
+func (r resolveAgencyGroups) handler(t *testing.T, dbMock *database.MockInterface) *handler {
+	mockCtrl := gomock.NewController(t)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+	client, _ := storage.NewClient(dbMock, fsMock)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return handler
+}
+
+func (r resolveAgencyGroups) testWhenThereAreNoGroups(t *testing.T) {
+	dbMock := database.NewMockInterface(gomock.NewController(t))
+	h := r.handler(t, dbMock)
+	params := searchParamsMatch{}.params(t, "anos=2020&orgaos=tjal")
+
+	assert.NoError(t, h.resolveAgencyGroups(params))
+	assert.Equal(t, []string{"tjal"}, params.Agencies)
+	assert.NoError(t, h.resolveAgencyGroups(nil))
+}
+
+func (r resolveAgencyGroups) testWhenThereAreGroupsAndUFs(t *testing.T) {
+	dbMock := database.NewMockInterface(gomock.NewController(t))
+	dbMock.EXPECT().GetAllAgencies().Return(r.agencies(), nil)
+	h := r.handler(t, dbMock)
+	params := searchParamsMatch{}.params(t, "grupos=justica-estadual,justica-do-trabalho&ufs=al")
+
+	assert.NoError(t, h.resolveAgencyGroups(params))
+	assert.Equal(t, []string{"tjal"}, params.Agencies)
+}
+
+func (r resolveAgencyGroups) testWhenThereAreEntitiesAndAgencies(t *testing.T) {
+	dbMock := database.NewMockInterface(gomock.NewController(t))
+	dbMock.EXPECT().GetAllAgencies().Return(r.agencies(), nil)
+	h := r.handler(t, dbMock)
+	params := searchParamsMatch{}.params(t, "entidades=tribunal&orgaos=tjpb,mpal,trt13")
+
+	assert.NoError(t, h.resolveAgencyGroups(params))
+	assert.Equal(t, []string{"tjpb", "trt13"}, params.Agencies)
+}
+
+func (r resolveAgencyGroups) testWhenNoAgencyMatches(t *testing.T) {
+	dbMock := database.NewMockInterface(gomock.NewController(t))
+	dbMock.EXPECT().GetAllAgencies().Return(r.agencies(), nil)
+	h := r.handler(t, dbMock)
+	params := searchParamsMatch{}.params(t, "ufs=PE")
+
+	assert.NoError(t, h.resolveAgencyGroups(params))
+	assert.NotNil(t, params.Agencies)
+	assert.Empty(t, params.Agencies)
+}
+
+func (r resolveAgencyGroups) testWhenGroupIsInvalid(t *testing.T) {
+	_, err := newSearchParams(url.Values{"grupos": {"justica-estelar"}})
+
+	assert.EqualError(t, err, "parâmetro grupo 'justica-estelar' é inválido!")
+}
+
+func TestExports(t *testing.T) {
+	tests := exports{}
+	t.Run("Test local ExportStore", tests.testDirExportStore)
+	t.Run("Test CreateExport", tests.testCreateExport)
+	t.Run("Test CreateExport when format is invalid", tests.testCreateExportWhenFormatIsInvalid)
+	t.Run("Test GetExport when export does not exist", tests.testGetExportWhenExportDoesNotExist)
+	t.Run("Test DownloadExport", tests.testDownloadExport)
+	t.Run("Test DownloadExport when export is not done", tests.testDownloadExportWhenExportIsNotDone)
+	t.Run("Test CreateExport when client reached the limit", tests.testCreateExportWhenClientReachedTheLimit)
+	t.Run("Test runExport when export was already claimed", tests.testRunExportWhenExportWasAlreadyClaimed)
+	t.Run("Test cleanup", tests.testCleanup)
+	t.Run("Test cleanup when an export was abandoned", tests.testCleanupWhenAnExportWasAbandoned)
+}
+
+type exports struct{}
+
+func (e exports) handler(t *testing.T) (*handler, ExportStore) {
+	store := NewDirExportStore(t.TempDir())
+	handler, err := NewHandler(nil, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), store, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return handler, store
+}
+
+func (e exports) request(method, target, id string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, target, nil)
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(request, recorder)
+	if id != "" {
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+	}
+	return ctx, recorder
+}
+
+func (e exports) testDirExportStore(t *testing.T) {
+	store := NewDirExportStore(t.TempDir())
+	ctx := context.Background()
+
+	assert.NoError(t, store.Put(ctx, "exportacoes/a.json", strings.NewReader("{}")))
+	assert.NoError(t, store.Put(ctx, "../outros/b.csv", strings.NewReader("x")))
+	r, err := store.Get(ctx, "exportacoes/a.json")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "{}", string(content))
+	_, err = store.Get(ctx, "exportacoes/c.json")
+	assert.ErrorIs(t, err, ErrExportNotFound)
+	keys, err := store.List(ctx, "exportacoes/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"exportacoes/a.json"}, keys)
+	assert.NoError(t, store.Create(ctx, "exportacoes/a.claim", []byte("x")))
+	assert.ErrorIs(t, store.Create(ctx, "exportacoes/a.claim", []byte("y")), ErrExportExists)
+	assert.NoError(t, store.Delete(ctx, "exportacoes/a.json"))
+	assert.NoError(t, store.Delete(ctx, "exportacoes/a.json"))
+	_, err = store.Get(ctx, "exportacoes/a.json")
+	assert.ErrorIs(t, err, ErrExportNotFound)
+}
+
+func (e exports) testCreateExport(t *testing.T) {
+	handler, _ := e.handler(t)
+	ctx, recorder := e.request(http.MethodPost, "/uiapi/v2/exportacoes?anos=2020&orgaos=tjal&formato=jsonl", "")
+
+	handler.CreateExport(ctx)
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	var job exportJob
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &job))
+	assert.Equal(t, exportPending, job.Status)
+	assert.Equal(t, "jsonl", job.Format)
+	assert.Equal(t, "anos=2020&orgaos=tjal", job.Parameters)
+	assert.Equal(t, "/uiapi/v2/exportacoes/"+job.ID, recorder.Header().Get(echo.HeaderLocation))
+	assert.Equal(t, job.ID, <-handler.exports.pending)
+	saved, err := handler.exports.load(context.Background(), job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, exportPending, saved.Status)
+}
+
+func (e exports) testCreateExportWhenFormatIsInvalid(t *testing.T) {
+	handler, _ := e.handler(t)
+	ctx, recorder := e.request(http.MethodPost, "/uiapi/v2/exportacoes?anos=2020&formato=xlsx", "")
+
+	handler.CreateExport(ctx)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Empty(t, handler.exports.pending)
+}
+
+func (e exports) testGetExportWhenExportDoesNotExist(t *testing.T) {
+	handler, _ := e.handler(t)
+	for _, id := range []string{"0123456789abcdef0123456789abcdef", "..%2F..%2Fetc"} {
+		ctx, recorder := e.request(http.MethodGet, "/uiapi/v2/exportacoes/"+id, id)
+
+		handler.GetExport(ctx)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	}
+}
+
+func (e exports) testDownloadExport(t *testing.T) {
+	handler, store := e.handler(t)
+	job := &exportJob{ID: "0123456789abcdef0123456789abcdef", Status: exportDone, Format: "csv", Rows: 1}
+	assert.NoError(t, handler.exports.save(context.Background(), job))
+	assert.NoError(t, store.Put(context.Background(), "exportacoes/"+job.ID+".csv", strings.NewReader("orgao\ntjal\n")))
+	ctx, recorder := e.request(http.MethodGet, "/uiapi/v2/exportacoes/"+job.ID+"/arquivo", job.ID)
+
+	handler.DownloadExport(ctx)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "orgao\ntjal\n", recorder.Body.String())
+}
+
+func (e exports) testDownloadExportWhenExportIsNotDone(t *testing.T) {
+	handler, _ := e.handler(t)
+	job := &exportJob{ID: "0123456789abcdef0123456789abcdef", Status: exportRunning, Format: "csv"}
+	assert.NoError(t, handler.exports.save(context.Background(), job))
+	ctx, recorder := e.request(http.MethodGet, "/uiapi/v2/exportacoes/"+job.ID+"/arquivo", job.ID)
+
+	handler.DownloadExport(ctx)
+
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func (e exports) testCreateExportWhenClientReachedTheLimit(t *testing.T) {
+	handler, _ := e.handler(t)
+	handler.exports.maxPerClient = 1
+	for _, code := range []int{http.StatusAccepted, http.StatusTooManyRequests} {
+		ctx, recorder := e.request(http.MethodPost, "/uiapi/v2/exportacoes?anos=2020&orgaos=tjal", "")
+
+		handler.CreateExport(ctx)
+
+		assert.Equal(t, code, recorder.Code)
+	}
+	// Mesmo em outra instância da API, o limite vale até a exportação terminar.
+	other, _ := NewHandler(nil, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), handler.exports.store, loc, []string{}, 100, 100, []string{})
+	other.exports.maxPerClient = 1
+	ctx, recorder := e.request(http.MethodPost, "/uiapi/v2/exportacoes?anos=2020&orgaos=tjal", "")
+	other.CreateExport(ctx)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	job, err := handler.exports.load(context.Background(), <-handler.exports.pending)
+	assert.NoError(t, err)
+	job.finish(time.Now(), defaultExportTTL)
+	assert.NoError(t, handler.exports.save(context.Background(), job))
+	ctx, recorder = e.request(http.MethodPost, "/uiapi/v2/exportacoes?anos=2020&orgaos=tjal", "")
+
+	handler.CreateExport(ctx)
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+}
+
+func (e exports) testRunExportWhenExportWasAlreadyClaimed(t *testing.T) {
+	handler, store := e.handler(t)
+	job := &exportJob{ID: "0123456789abcdef0123456789abcdef", Status: exportPending, Format: "csv"}
+	assert.NoError(t, handler.exports.save(context.Background(), job))
+	assert.NoError(t, store.Create(context.Background(), "exportacoes/"+job.ID+".0.claim", []byte(`{"instancia": "outra-instancia"}`)))
+
+	assert.NoError(t, handler.runExport(context.Background(), job.ID))
+
+	saved, err := handler.exports.load(context.Background(), job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, exportPending, saved.Status)
+	assert.Nil(t, saved.FinishedAt)
+}
+
+func (e exports) testCleanup(t *testing.T) {
+	handler, store := e.handler(t)
+	ctx := context.Background()
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, loc)
+	old := now.Add(-2 * defaultExportTTL)
+	expired := &exportJob{ID: "00000000000000000000000000000001", Status: exportDone, Format: "csv", CreatedAt: old}
+	expired.finish(old, defaultExportTTL)
+	done := &exportJob{ID: "00000000000000000000000000000002", Status: exportDone, Format: "csv", CreatedAt: now}
+	done.finish(now, defaultExportTTL)
+	stuck := &exportJob{ID: "00000000000000000000000000000003", Status: exportRunning, Format: "csv", CreatedAt: old}
+	pending := &exportJob{ID: "00000000000000000000000000000004", Status: exportPending, Format: "csv", CreatedAt: now}
+	for _, job := range []*exportJob{expired, done, stuck, pending} {
+		assert.NoError(t, handler.exports.save(ctx, job))
+	}
+	assert.NoError(t, store.Put(ctx, "exportacoes/"+expired.ID+".csv", strings.NewReader("orgao\n")))
+
+	ids, _, err := handler.exports.cleanup(ctx, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{pending.ID}, ids)
+	keys, err := store.List(ctx, "exportacoes/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"exportacoes/" + done.ID + ".json",
+		"exportacoes/" + stuck.ID + ".json",
+		"exportacoes/" + pending.ID + ".json",
+	}, keys)
+	saved, err := handler.exports.load(ctx, stuck.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, exportFailed, saved.Status)
+	assert.True(t, now.Add(defaultExportTTL).Equal(*saved.ExpiresAt))
+}
+
+func (e exports) testCleanupWhenAnExportWasAbandoned(t *testing.T) {
+	handler, store := e.handler(t)
+	ctx := context.Background()
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, loc)
+	abandoned := &exportJob{ID: "00000000000000000000000000000001", Status: exportRunning, Format: "csv", CreatedAt: now.Add(-time.Hour)}
+	running := &exportJob{ID: "00000000000000000000000000000002", Status: exportRunning, Format: "csv", CreatedAt: now.Add(-time.Hour)}
+	for _, job := range []*exportJob{abandoned, running} {
+		assert.NoError(t, handler.exports.save(ctx, job))
+	}
+	claimed, err := handler.exports.claim(ctx, abandoned, now.Add(-exportLeaseTimeout-time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = handler.exports.claim(ctx, running, now.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	pending, requeued, err := handler.exports.cleanup(ctx, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{abandoned.ID}, pending)
+	assert.Equal(t, []string{abandoned.ID}, requeued)
+	saved, err := handler.exports.load(ctx, abandoned.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, exportPending, saved.Status)
+	assert.Equal(t, 1, saved.attempt)
+	// A nova tentativa tem sua própria reserva.
+	claimed, err = handler.exports.claim(ctx, saved, now)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	saved, err = handler.exports.load(ctx, running.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, exportRunning, saved.Status)
+	_, err = store.Get(ctx, exportClaimKey(running.ID, 0))
+	assert.NoError(t, err)
+}
+
+func TestNewMemberTimeSeries(t *testing.T) {
+	tests := memberTimeSeriesTests{}
+	t.Run("Test newMemberTimeSeries when members have registration", tests.testWhenMembersHaveRegistration)
+	t.Run("Test newMemberTimeSeries when members have no registration", tests.testWhenMembersHaveNoRegistration)
+	t.Run("Test normalizeName", tests.testNormalizeName)
+}
+
+type memberTimeSeriesTests struct{}
+
+func (m memberTimeSeriesTests) row(registration *string, name string, year, month int, category, valor string) searchResult {
+	return searchResult{Orgao: "tjal", Ano: year, Mes: month, Matricula: registration, Nome: name, CategoriaContracheque: category, Valor: valor}
+}
+
+func (m memberTimeSeriesTests) testWhenMembersHaveRegistration(t *testing.T) {
+	a, b := "123", "456"
+	rows := []searchResult{
+		m.row(&a, "Maria José", 2020, 2, "base", "1000"),
+		m.row(&a, "Maria José", 2020, 2, "outras", "200,5"),
+		m.row(&b, "Maria José", 2020, 1, "base", "3000"),
+		m.row(&a, "Maria José", 2020, 1, "base", "900"),
+		m.row(&a, "Maria José", 2020, 1, "descontos", "100"),
+	}
+
+	series, err := newMemberTimeSeries(rows)
+
+	assert.NoError(t, err)
+	assert.Len(t, series, 2)
+	assert.Equal(t, "123", *series[0].Registration)
+	assert.Equal(t, []memberMonth{
+		{Year: 2020, Month: 1, Base: 900, Discounts: 100},
+		{Year: 2020, Month: 2, Base: 1000, Other: 200.5},
+	}, series[0].Months)
+	assert.Equal(t, "456", *series[1].Registration)
+	assert.Len(t, series[1].Months, 1)
+}
+
+func (m memberTimeSeriesTests) testWhenMembersHaveNoRegistration(t *testing.T) {
+	rows := []searchResult{
+		m.row(nil, "Maria José", 2020, 1, "base", "1000"),
+		m.row(nil, "MARIA  JOSE", 2020, 2, "base", "1000"),
+	}
+
+	series, err := newMemberTimeSeries(rows)
+
+	assert.NoError(t, err)
+	assert.Len(t, series, 1)
+	assert.Nil(t, series[0].Registration)
+	assert.Len(t, series[0].Months, 2)
+}
+
+func (m memberTimeSeriesTests) testNormalizeName(t *testing.T) {
+	assert.Equal(t, "maria jose da silva", normalizeName("  MARIA   José da  Silva "))
+}
+
+func TestGetItemTimeSeries(t *testing.T) {
+	tests := itemTimeSeriesTests{}
+	t.Run("Test GetItems", tests.testGetItems)
+	t.Run("Test GetItemTimeSeries when there are filters", tests.testWhenThereAreFilters)
+	t.Run("Test GetItemTimeSeries when there are no filters", tests.testWhenThereAreNoFilters)
+	t.Run("Test GetItemTimeSeries when item does not exist", tests.testWhenItemDoesNotExist)
+	t.Run("Test GetItemTimeSeries when item is only in the data", tests.testWhenItemIsOnlyInTheData)
+	t.Run("Test GetItemTimeSeries when a row filter is passed", tests.testWhenARowFilterIsPassed)
+}
+
+type itemTimeSeriesTests struct{}
+
+func (i itemTimeSeriesTests) handler(t *testing.T, dbMock *database.MockInterface) *handler {
+	fsMock := file_storage.NewMockInterface(gomock.NewController(t))
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+	client, _ := storage.NewClient(dbMock, fsMock)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return handler
+}
+
+func (i itemTimeSeriesTests) monthlyInfo(agency string, year, month int, health float64) models.AgencyMonthlyInfo {
+	return models.AgencyMonthlyInfo{
+		AgencyID: agency,
+		Year:     year,
+		Month:    month,
+		Summary: &models.Summary{
+			BaseRemuneration: models.DataSummary{Total: 1000},
+			ItemSummary:      models.ItemSummary{"auxilio_saude": health},
+		},
+	}
+}
+
+func (i itemTimeSeriesTests) get(handler *handler, item, query string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, "/uiapi/v2/rubricas/"+item+"?"+query, nil)
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(request, recorder)
+	ctx.SetParamNames("rubrica")
+	ctx.SetParamValues(item)
+	handler.GetItemTimeSeries(ctx)
+	return recorder
+}
+
+func (i itemTimeSeriesTests) testWhenARowFilterIsPassed(t *testing.T) {
+	// Nenhuma consulta ao banco é esperada.
+	handler := i.handler(t, database.NewMockInterface(gomock.NewController(t)))
+
+	recorder := i.get(handler, "auxilio_saude", "anos=2020&nome=maria")
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "\"o parâmetro nome não é aceito nesta rota!\"\n", recorder.Body.String())
+}
+
+func (i itemTimeSeriesTests) testGetItems(t *testing.T) {
+	dbMock := database.NewMockInterface(gomock.NewController(t))
+	agencies := []models.Agency{{ID: "tjal"}}
+	dbMock.EXPECT().GetAllAgencies().Return(agencies, nil)
+	dbMock.EXPECT().GetFirstDateWithMonthlyInfo().Return(1, 2020, nil)
+	dbMock.EXPECT().GetLastDateWithMonthlyInfo().Return(12, 2020, nil)
+	newItem := i.monthlyInfo("tjal", 2020, 2, 0)
+	newItem.Summary.ItemSummary = models.ItemSummary{"auxilio_transporte": 50, "outras": 10}
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2020).Return(map[string][]models.AgencyMonthlyInfo{
+		"tjal": {
+			i.monthlyInfo("tjal", 2020, 1, 250.5),
+			newItem,
+			{AgencyID: "tjal", Year: 2020, Month: 3, Summary: &models.Summary{ItemSummary: models.ItemSummary{"sem_remuneracao": 1}}},
+		},
+	}, nil)
+	handler := i.handler(t, dbMock)
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/rubricas", nil), recorder)
+
+	handler.GetItems(ctx)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `[
+		{"rubrica": "auxilio_saude", "descricao": "Auxílio-saúde"},
+		{"rubrica": "auxilio_transporte"},
+		{"rubrica": "outras", "descricao": "Soma das demais rubricas, não identificadas individualmente"}
+	]`, recorder.Body.String())
+}
+
+func (i itemTimeSeriesTests) testWhenThereAreFilters(t *testing.T) {
+	dbMock := database.NewMockInterface(gomock.NewController(t))
+	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2020).Return(map[string][]models.AgencyMonthlyInfo{
+		"tjal": {
+			i.monthlyInfo("tjal", 2020, 2, 300),
+			i.monthlyInfo("tjal", 2020, 1, 250.5),
+			{AgencyID: "tjal", Year: 2020, Month: 3},
+		},
+	}, nil)
+	handler := i.handler(t, dbMock)
+
+	recorder := i.get(handler, "auxilio_saude", "anos=2020&orgaos=tjal")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{
+		"rubrica": "auxilio_saude",
+		"descricao": "Auxílio-saúde",
+		"orgaos": [
+			{"id_orgao": "tjal", "meses": [{"ano": 2020, "mes": 1, "total": 250.5}, {"ano": 2020, "mes": 2, "total": 300}]}
+		]
+	}`, recorder.Body.String())
+}
+
+func (i itemTimeSeriesTests) testWhenThereAreNoFilters(t *testing.T) {
+	dbMock := database.NewMockInterface(gomock.NewController(t))
+	agencies := []models.Agency{{ID: "mpal"}, {ID: "tjal"}}
+	dbMock.EXPECT().GetAllAgencies().Return(agencies, nil)
+	dbMock.EXPECT().GetFirstDateWithMonthlyInfo().Return(1, 2019, nil)
+	dbMock.EXPECT().GetLastDateWithMonthlyInfo().Return(12, 2020, nil)
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2019).Return(map[string][]models.AgencyMonthlyInfo{
+		"tjal": {i.monthlyInfo("tjal", 2019, 12, 10)},
+	}, nil)
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2020).Return(map[string][]models.AgencyMonthlyInfo{
+		"tjal": {i.monthlyInfo("tjal", 2020, 1, 20)},
+		"mpal": {i.monthlyInfo("mpal", 2020, 1, 30)},
+	}, nil)
+	handler := i.handler(t, dbMock)
+
+	recorder := i.get(handler, "auxilio_saude", "")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var series itemTimeSeries
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &series))
+	assert.Len(t, series.Agencies, 2)
+	assert.Equal(t, "mpal", series.Agencies[0].AgencyID)
+	assert.Equal(t, []itemMonthTotal{{Year: 2019, Month: 12, Total: 10}, {Year: 2020, Month: 1, Total: 20}}, series.Agencies[1].Months)
+}
+
+func (i itemTimeSeriesTests) testWhenItemDoesNotExist(t *testing.T) {
+	dbMock := database.NewMockInterface(gomock.NewController(t))
+	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2020).Return(map[string][]models.AgencyMonthlyInfo{
+		"tjal": {i.monthlyInfo("tjal", 2020, 1, 250.5)},
+	}, nil)
+	handler := i.handler(t, dbMock)
+
+	recorder := i.get(handler, "auxilio_inexistente", "anos=2020&orgaos=tjal")
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func (i itemTimeSeriesTests) testWhenItemIsOnlyInTheData(t *testing.T) {
+	dbMock := database.NewMockInterface(gomock.NewController(t))
+	mi := i.monthlyInfo("tjal", 2020, 1, 0)
+	mi.Summary.ItemSummary = models.ItemSummary{"auxilio_transporte": 50}
+	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2020).Return(map[string][]models.AgencyMonthlyInfo{
+		"tjal": {mi},
+	}, nil)
+	handler := i.handler(t, dbMock)
+
+	recorder := i.get(handler, "auxilio_transporte", "anos=2020&orgaos=tjal")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{
+		"rubrica": "auxilio_transporte",
+		"orgaos": [{"id_orgao": "tjal", "meses": [{"ano": 2020, "mes": 1, "total": 50}]}]
+	}`, recorder.Body.String())
+}
+
+func TestTetoBreaches(t *testing.T) {
+	tests := tetoBreachesTests{}
+	t.Run("Test tetoAt", tests.testTetoAt)
+	t.Run("Test tetoBreaches when summary is below the teto", tests.testWhenSummaryIsBelowTheTeto)
+	t.Run("Test tetoBreaches when zip is read", tests.testWhenZipIsRead)
+	t.Run("Test tetoBreaches when month is before the table", tests.testWhenMonthIsBeforeTheTable)
+	t.Run("Test newTetoBreachTotals", tests.testTotals)
+	t.Run("Test GetTetoBreaches when parameters are missing", tests.testWhenParametersAreMissing)
+}
+
+type tetoBreachesTests struct{}
+
+// Cria um handler que lê um arquivo zip de tjal com dois membros, um acima e
+// outro abaixo do teto de 2020.
+func (tb tetoBreachesTests) handler(t *testing.T) (*handler, []searchDetails) {
+	content := `orgao;mes;ano;matricula;nome;cargo;lotacao;categoria_contracheque;detalhamento_contracheque;valor;desambiguacao_micro;desambiguacao_macro
+tjal;1;2020;123;Maria José;Juiz;Maceió;base;subsidio;35462.22;;
+tjal;1;2020;123;Maria José;Juiz;Maceió;outras;auxílio-alimentação;1000.5;;
+tjal;1;2020;123;Maria José;Juiz;Maceió;descontos;imposto de renda;8000;;
+tjal;1;2020;456;João Silva;Desembargador;Maceió;base;subsidio;37328.65;;
+tjal;1;2020;456;João Silva;Desembargador;Maceió;outras;licença-prêmio;12000;;
+tjal;1;2020;456;João Silva;Desembargador;Maceió;descontos;imposto de renda;5000;;
+`
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "tjal"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tjal/tjal-2020-1.zip"), zipWithCSV(t, content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	handler, err := NewHandler(nil, nil, nil, NewDirZipStore(dir, "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []searchDetails{
+		{Orgao: "tjal", Mes: 1, Ano: 2020, Linhas: 6, LinhasAcumuladas: 6, ZipUrl: "https://dadosjusbr_public.s3.amazonaws.com/tjal/tjal-2020-1.zip"},
+	}
+	return handler, results
+}
+
+func (tb tetoBreachesTests) testTetoAt(t *testing.T) {
+	_, ok := tetoAt(2014, 12)
+	assert.False(t, ok)
+	teto, ok := tetoAt(2015, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 33763.00, teto)
+	teto, _ = tetoAt(2018, 12)
+	assert.Equal(t, 33763.00, teto)
+	teto, _ = tetoAt(2019, 1)
+	assert.Equal(t, 39293.32, teto)
+	teto, _ = tetoAt(2030, 6)
+	assert.Equal(t, 46366.19, teto)
+}
+
+func (tb tetoBreachesTests) testWhenSummaryIsBelowTheTeto(t *testing.T) {
+	handler, results := tb.handler(t)
+	// O arquivo não existe, então ele não pode ser lido.
+	results[0].ZipUrl = "https://dadosjusbr_public.s3.amazonaws.com/tjal/inexistente.zip"
+	summaries := map[string]*models.Summary{
+		monthlySummaryKey("tjal", 2020, 1): {Count: 10, Remunerations: models.DataSummary{Max: 30000}},
+	}
+
+	months, err := handler.tetoBreaches(context.Background(), results, summaries)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []tetoBreachMonth{
+		{AgencyID: "tjal", Year: 2020, Month: 1, Teto: 39293.32, Members: 10},
+	}, months)
+}
+
+func (tb tetoBreachesTests) testWhenZipIsRead(t *testing.T) {
+	handler, results := tb.handler(t)
+	summaries := map[string]*models.Summary{
+		monthlySummaryKey("tjal", 2020, 1): {Count: 2, Remunerations: models.DataSummary{Max: 44328.65}},
+	}
+
+	months, err := handler.tetoBreaches(context.Background(), results, summaries)
+
+	assert.NoError(t, err)
+	assert.Len(t, months, 1)
+	assert.Equal(t, 2, months[0].Members)
+	assert.Equal(t, 1, months[0].Breaches)
+	assert.Equal(t, 0.5, months[0].Share)
+	assert.InDelta(t, 44328.65-39293.32, months[0].Excess, 0.001)
+}
+
+func (tb tetoBreachesTests) testWhenMonthIsBeforeTheTable(t *testing.T) {
+	handler, results := tb.handler(t)
+	results[0].Ano = 2014
+
+	months, err := handler.tetoBreaches(context.Background(), results, nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, months)
+}
+
+func (tb tetoBreachesTests) testTotals(t *testing.T) {
+	totals := newTetoBreachTotals([]tetoBreachMonth{
+		{Members: 10, Breaches: 1, Excess: 100},
+		{Members: 30, Breaches: 9, Excess: 50.5},
+	})
+
+	assert.Equal(t, tetoBreachTotals{Paychecks: 40, Breaches: 10, Share: 0.25, Excess: 150.5}, totals)
+	assert.Equal(t, tetoBreachTotals{}, newTetoBreachTotals(nil))
+}
+
+func (tb tetoBreachesTests) testWhenParametersAreMissing(t *testing.T) {
+	for _, query := range []string{"orgaos=tjal", "anos=2020"} {
+		recorder := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/teto/excedentes?"+query, nil), recorder)
+
+		hand.GetTetoBreaches(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
+
+func TestCompareAgencies(t *testing.T) {
+	tests := compareAgenciesTests{}
+	t.Run("Test newAgencyComparison when series are aligned", tests.testWhenSeriesAreAligned)
+	t.Run("Test newAgencyComparison when months are filtered", tests.testWhenMonthsAreFiltered)
+	t.Run("Test newAgencyComparison when a month has no IPCA", tests.testWhenAMonthHasNoIPCA)
+	t.Run("Test CompareAgencies", tests.testCompareAgencies)
+	t.Run("Test CompareAgencies when parameters are missing", tests.testWhenParametersAreMissing)
+}
+
+type compareAgenciesTests struct{}
+
+func (ca compareAgenciesTests) monthlyInfo(agency string, year, month int, perCapita float64) models.AgencyMonthlyInfo {
+	return models.AgencyMonthlyInfo{
+		AgencyID: agency,
+		Year:     year,
+		Month:    month,
+		Summary: &models.Summary{
+			Count:            10,
+			BaseRemuneration: models.DataSummary{Total: perCapita * 10, Average: perCapita},
+			Remunerations:    models.DataSummary{Total: perCapita * 10, Average: perCapita},
+		},
+	}
+}
+
+func (ca compareAgenciesTests) monthlyInfos() map[string][]models.AgencyMonthlyInfo {
+	return map[string][]models.AgencyMonthlyInfo{
+		"tjal": {ca.monthlyInfo("tjal", 2022, 2, 400), ca.monthlyInfo("tjal", 2022, 1, 100)},
+		"tjpe": {ca.monthlyInfo("tjpe", 2022, 1, 200), {AgencyID: "tjpe", Year: 2022, Month: 2}},
+	}
+}
+
+func (ca compareAgenciesTests) testWhenSeriesAreAligned(t *testing.T) {
+	comparison := newAgencyComparison([]string{"tjpe", "tjal", "tjba"}, ca.monthlyInfos(), nil, nil)
+
+	assert.Equal(t, []comparisonMonth{{Year: 2022, Month: 1}, {Year: 2022, Month: 2}}, comparison.Months)
+	assert.Len(t, comparison.Agencies, 3)
+	tjpe, tjal, tjba := comparison.Agencies[0], comparison.Agencies[1], comparison.Agencies[2]
+	assert.Equal(t, "tjpe", tjpe.AgencyID)
+	assert.Equal(t, 2, tjpe.Position)
+	assert.Equal(t, 1, tjpe.Months[0].Position)
+	assert.Nil(t, tjpe.Months[1])
+	assert.Equal(t, 1, tjal.Position)
+	assert.Equal(t, 250.0, tjal.RemunerationsPerCapita)
+	assert.Equal(t, 2, tjal.Months[0].Position)
+	assert.Equal(t, 1, tjal.Months[1].Position)
+	assert.Equal(t, 4000.0, tjal.Months[1].Remunerations)
+	assert.Equal(t, 0, tjba.Position)
+	assert.Equal(t, []*comparisonTotals{nil, nil}, tjba.Months)
+}
+
+func (ca compareAgenciesTests) testWhenMonthsAreFiltered(t *testing.T) {
+	comparison := newAgencyComparison([]string{"tjal", "tjpe"}, ca.monthlyInfos(), &searchParams{Months: []string{"02"}}, nil)
+
+	assert.Equal(t, []comparisonMonth{{Year: 2022, Month: 2}}, comparison.Months)
+	assert.Equal(t, 1, comparison.Agencies[0].Position)
+	assert.Equal(t, 0, comparison.Agencies[1].Position)
+}
+
+func (ca compareAgenciesTests) testWhenAMonthHasNoIPCA(t *testing.T) {
+	corr, err := ipca.NewCorrection("ipca", "2022-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// O IPCA de 2100 não está na série, então o mês fica com o valor nominal.
+	monthlyInfos := map[string][]models.AgencyMonthlyInfo{
+		"tjal": {ca.monthlyInfo("tjal", 2022, 1, 100), ca.monthlyInfo("tjal", 2100, 1, 400)},
+		"tjpe": {ca.monthlyInfo("tjpe", 2022, 1, 200)},
+	}
+
+	comparison := newAgencyComparison([]string{"tjal", "tjpe"}, monthlyInfos, nil, corr)
+
+	tjal, tjpe := comparison.Agencies[0], comparison.Agencies[1]
+	assert.False(t, tjal.Months[0].Uncorrected)
+	assert.True(t, tjal.Months[1].Uncorrected)
+	assert.Equal(t, 4000.0, tjal.Months[1].Remunerations)
+	assert.True(t, tjal.Uncorrected)
+	assert.False(t, tjpe.Uncorrected)
+}
+
+func (ca compareAgenciesTests) testCompareAgencies(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dbMock := database.NewMockInterface(mockCtrl)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjpe"}, {ID: "tjal"}}, 2022).Return(ca.monthlyInfos(), nil)
+	client, _ := storage.NewClient(dbMock, fsMock)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/comparar?orgaos=tjpe,tjal,tjpe&anos=2022&meses=1", nil), recorder)
+
+	handler.CompareAgencies(ctx)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{
+		"meses": [{"ano": 2022, "mes": 1}],
+		"orgaos": [
+			{"id_orgao": "tjpe", "posicao": 1, "remuneracoes_por_membro": 200, "meses_com_dados": 1, "totais": [
+				{"posicao": 1, "total_membros": 10, "remuneracao_base": 2000, "remuneracao_base_por_membro": 200, "outras_remuneracoes": 0, "outras_remuneracoes_por_membro": 0, "descontos": 0, "descontos_por_membro": 0, "remuneracoes": 2000, "remuneracoes_por_membro": 200}
+			]},
+			{"id_orgao": "tjal", "posicao": 2, "remuneracoes_por_membro": 100, "meses_com_dados": 1, "totais": [
+				{"posicao": 2, "total_membros": 10, "remuneracao_base": 1000, "remuneracao_base_por_membro": 100, "outras_remuneracoes": 0, "outras_remuneracoes_por_membro": 0, "descontos": 0, "descontos_por_membro": 0, "remuneracoes": 1000, "remuneracoes_por_membro": 100}
+			]}
+		]
+	}`, recorder.Body.String())
+}
+
+func (ca compareAgenciesTests) testWhenParametersAreMissing(t *testing.T) {
+	for _, query := range []string{"orgaos=tjal,tjpe", "anos=2022", "orgaos=tjal&anos=2022&corrigir=igpm"} {
+		recorder := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/comparar?"+query, nil), recorder)
+
+		hand.CompareAgencies(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
+
+func TestRanking(t *testing.T) {
+	tests := rankingTests{}
+	t.Run("Test previousPeriod", tests.testPreviousPeriod)
+	t.Run("Test rankingValue", tests.testRankingValue)
+	t.Run("Test newRankingPositions", tests.testRankingPositions)
+	t.Run("Test newRankingPositions when order is ascending and there is a limit", tests.testWhenOrderIsAscendingAndThereIsALimit)
+	t.Run("Test GetRanking", tests.testGetRanking)
+	t.Run("Test GetRanking when parameters are invalid", tests.testWhenParametersAreInvalid)
+	t.Run("Test GetRanking when item is not in the data", tests.testWhenItemIsNotInTheData)
+}
+
+type rankingTests struct{}
+
+func (r rankingTests) monthlyInfo(agency string, year, month int, perCapita float64) models.AgencyMonthlyInfo {
+	return models.AgencyMonthlyInfo{
+		AgencyID: agency,
+		Year:     year,
+		Month:    month,
+		Summary: &models.Summary{
+			Count:            10,
+			BaseRemuneration: models.DataSummary{Total: perCapita * 10, Average: perCapita},
+			Remunerations:    models.DataSummary{Total: perCapita * 10, Average: perCapita},
+			ItemSummary:      models.ItemSummary{"auxilio_saude": perCapita},
+		},
+		Score: &models.Score{Score: perCapita / 1000},
+	}
+}
+
+func (r rankingTests) monthlyInfos() map[string][]models.AgencyMonthlyInfo {
+	return map[string][]models.AgencyMonthlyInfo{
+		"tjal": {r.monthlyInfo("tjal", 2022, 12, 100), r.monthlyInfo("tjal", 2023, 1, 300), r.monthlyInfo("tjal", 2023, 2, 100)},
+		"tjpe": {r.monthlyInfo("tjpe", 2022, 12, 200), r.monthlyInfo("tjpe", 2023, 1, 150)},
+		"tjba": {r.monthlyInfo("tjba", 2023, 2, 50)},
+	}
+}
+
+func (r rankingTests) testPreviousPeriod(t *testing.T) {
+	year := rankingPeriod(&searchParams{Years: []string{"2023"}})
+	assert.Len(t, year, 12)
+	assert.Equal(t, yearMonth{Year: 2022, Month: 1}, previousPeriod(year)[0])
+	assert.Equal(t, yearMonth{Year: 2022, Month: 12}, previousPeriod(year)[11])
+
+	quarter := rankingPeriod(&searchParams{Years: []string{"2023"}, Months: []string{"6", "4", "5"}})
+	assert.Equal(t, []yearMonth{{2023, 1}, {2023, 2}, {2023, 3}}, previousPeriod(quarter))
+
+	january := rankingPeriod(&searchParams{Years: []string{"2023"}, Months: []string{"1"}})
+	assert.Equal(t, []yearMonth{{2022, 12}}, previousPeriod(january))
+	assert.Nil(t, previousPeriod(nil))
+}
+
+func (r rankingTests) testRankingValue(t *testing.T) {
+	mi := r.monthlyInfo("tjal", 2023, 1, 300)
+
+	v, ok := rankingValue("rubrica", "auxilio_saude", mi)
+	assert.True(t, ok)
+	assert.Equal(t, 30.0, v)
+	v, ok = rankingValue("indice", "", mi)
+	assert.True(t, ok)
+	assert.Equal(t, 0.3, v)
+	_, ok = rankingValue("remuneracao", "", models.AgencyMonthlyInfo{})
+	assert.False(t, ok)
+	_, ok = rankingValue("indice", "", models.AgencyMonthlyInfo{})
+	assert.False(t, ok)
+}
+
+func (r rankingTests) testRankingPositions(t *testing.T) {
+	period := []yearMonth{{2023, 1}, {2023, 2}}
+
+	positions := newRankingPositions("remuneracao", "", r.monthlyInfos(), period, previousPeriod(period), true, 0, nil)
+
+	assert.Len(t, positions, 3)
+	assert.Equal(t, "tjal", positions[0].AgencyID)
+	assert.Equal(t, 1, positions[0].Rank)
+	assert.Equal(t, 200.0, positions[0].Value)
+	assert.Equal(t, 2, positions[0].MonthsWithData)
+	assert.Equal(t, 2, *positions[0].PreviousRank)
+	assert.Equal(t, 100.0, *positions[0].PreviousValue)
+	assert.Equal(t, 100.0, *positions[0].Change)
+	assert.Equal(t, 100.0, *positions[0].ChangePercent)
+	assert.Equal(t, "tjpe", positions[1].AgencyID)
+	assert.Equal(t, 1, *positions[1].PreviousRank)
+	assert.Equal(t, -25.0, *positions[1].ChangePercent)
+	assert.Equal(t, "tjba", positions[2].AgencyID)
+	assert.Nil(t, positions[2].PreviousRank)
+	assert.Nil(t, positions[2].Change)
+}
+
+func (r rankingTests) testWhenOrderIsAscendingAndThereIsALimit(t *testing.T) {
+	period := []yearMonth{{2023, 1}, {2023, 2}}
+
+	positions := newRankingPositions("remuneracao", "", r.monthlyInfos(), period, previousPeriod(period), false, 1, nil)
+
+	assert.Len(t, positions, 1)
+	assert.Equal(t, "tjba", positions[0].AgencyID)
+	assert.Equal(t, 1, positions[0].Rank)
+}
+
+func (r rankingTests) testGetRanking(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dbMock := database.NewMockInterface(mockCtrl)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+	agencies := []models.Agency{{ID: "tjal"}, {ID: "tjpe"}}
+	infos := r.monthlyInfos()
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2022).Return(map[string][]models.AgencyMonthlyInfo{
+		"tjal": infos["tjal"][:1],
+		"tjpe": infos["tjpe"][:1],
+	}, nil)
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2023).Return(map[string][]models.AgencyMonthlyInfo{
+		"tjal": infos["tjal"][1:],
+		"tjpe": infos["tjpe"][1:],
+	}, nil)
+	client, _ := storage.NewClient(dbMock, fsMock)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/ranking?orgaos=tjal,tjpe&anos=2023&meses=1&limite=1", nil), recorder)
+
+	handler.GetRanking(ctx)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{
+		"metrica": "remuneracao",
+		"ordem": "desc",
+		"periodo": {"inicio": "2023-01", "fim": "2023-01"},
+		"periodo_anterior": {"inicio": "2022-12", "fim": "2022-12"},
+		"orgaos": [
+			{"posicao": 1, "id_orgao": "tjal", "valor": 300, "meses_com_dados": 1, "posicao_anterior": 2, "valor_anterior": 100, "variacao": 200, "variacao_percentual": 200}
+		]
+	}`, recorder.Body.String())
+}
+
+func (r rankingTests) testWhenParametersAreInvalid(t *testing.T) {
+	for _, query := range []string{
+		"orgaos=tjal",
+		"anos=2023&metrica=teto",
+		"anos=2023&metrica=rubrica",
+		"anos=2023&rubrica=auxilio_saude",
+		"anos=2023&ordem=maior",
+		"anos=2023&limite=0",
+		"anos=2023&metrica=indice&corrigir=ipca",
+	} {
+		recorder := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/ranking?"+query, nil), recorder)
+
+		hand.GetRanking(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
+
+func (r rankingTests) testWhenItemIsNotInTheData(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dbMock := database.NewMockInterface(mockCtrl)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+	agencies := []models.Agency{{ID: "tjal"}}
+	infos := r.monthlyInfos()
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2022).Return(map[string][]models.AgencyMonthlyInfo{"tjal": infos["tjal"][:1]}, nil)
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2023).Return(map[string][]models.AgencyMonthlyInfo{"tjal": infos["tjal"][1:]}, nil)
+	client, _ := storage.NewClient(dbMock, fsMock)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/ranking?orgaos=tjal&anos=2023&meses=1&metrica=rubrica&rubrica=diarias", nil), recorder)
+
+	handler.GetRanking(ctx)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestDistribution(t *testing.T) {
+	tests := distributionTests{}
+	t.Run("Test newDistribution", tests.testNewDistribution)
+	t.Run("Test newDistribution when there are no values", tests.testWhenThereAreNoValues)
+	t.Run("Test gini", tests.testGini)
+	t.Run("Test parseBinEdges", tests.testParseBinEdges)
+	t.Run("Test memberValues", tests.testMemberValues)
+	t.Run("Test GetRemunerationDistribution when parameters are invalid", tests.testWhenParametersAreInvalid)
+}
+
+type distributionTests struct{}
+
+func (d distributionTests) testNewDistribution(t *testing.T) {
+	distribution := newDistribution([]float64{50000, 10000, 40000, 20000, 30000}, []float64{20000, 40000})
+
+	assert.Equal(t, 5, distribution.Members)
+	assert.Equal(t, 30000.0, distribution.Mean)
+	assert.Equal(t, 30000.0, distribution.Median)
+	assert.Equal(t, 10000.0, distribution.Min)
+	assert.Equal(t, 50000.0, distribution.Max)
+	assert.Equal(t, percentiles{P10: 14000, P25: 20000, P50: 30000, P75: 40000, P90: 46000, P99: 49600}, distribution.Percentiles)
+	assert.InDelta(t, 4.0/15, *distribution.Gini, 1e-9)
+	assert.Len(t, distribution.Histogram, 3)
+	assert.Nil(t, distribution.Histogram[0].Start)
+	assert.Equal(t, 20000.0, *distribution.Histogram[0].End)
+	assert.Equal(t, 1, distribution.Histogram[0].Count)
+	assert.Equal(t, 2, distribution.Histogram[1].Count)
+	assert.Equal(t, 40000.0, *distribution.Histogram[2].Start)
+	assert.Nil(t, distribution.Histogram[2].End)
+	assert.Equal(t, 2, distribution.Histogram[2].Count)
+}
+
+func (d distributionTests) testWhenThereAreNoValues(t *testing.T) {
+	distribution := newDistribution(nil, defaultBinEdges)
+
+	assert.Equal(t, 0, distribution.Members)
+	assert.Nil(t, distribution.Gini)
+	assert.Len(t, distribution.Histogram, len(defaultBinEdges)+1)
+}
+
+func (d distributionTests) testGini(t *testing.T) {
+	assert.Equal(t, 0.0, *gini([]float64{10, 10, 10, 10}))
+	assert.InDelta(t, 0.75, *gini([]float64{0, 0, 0, 10}), 1e-9)
+	assert.Nil(t, gini([]float64{-10, 5}))
+}
+
+func (d distributionTests) testParseBinEdges(t *testing.T) {
+	edges, err := parseBinEdges("0, 25000,50000.5")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 25000, 50000.5}, edges)
+	edges, err = parseBinEdges("")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultBinEdges, edges)
+	for _, qp := range []string{"0,abc", "10,5", "10,10", "NaN"} {
+		_, err := parseBinEdges(qp)
+		assert.Error(t, err, qp)
+	}
+}
+
+func (d distributionTests) testMemberValues(t *testing.T) {
+	handler, results := tetoBreachesTests{}.handler(t)
+
+	values, err := handler.memberValues(context.Background(), results, distributionValues["liquido"], 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, values, 2)
+	assert.InDelta(t, 2*28462.72, values[0], 0.001)
+	assert.InDelta(t, 2*44328.65, values[1], 0.001)
+}
+
+func (d distributionTests) testWhenParametersAreInvalid(t *testing.T) {
+	for _, params := range [][]string{
+		{"abc", "1", ""},
+		{"2020", "13", ""},
+		{"2020", "1", "valor=bruto"},
+		{"2020", "1", "faixas=10,5"},
+		{"2020", "1", "corrigir=igpm"},
+	} {
+		recorder := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/distribuicao/tjal?"+params[2], nil), recorder)
+		ctx.SetParamNames("orgao", "ano", "mes")
+		ctx.SetParamValues("tjal", params[0], params[1])
+
+		hand.GetRemunerationDistribution(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, params)
+	}
+}
+
+func TestAnomalies(t *testing.T) {
+	tests := anomaliesTests{}
+	t.Run("Test detectAnomalies when totals jump", tests.testWhenTotalsJump)
+	t.Run("Test detectAnomalies when items spike", tests.testWhenItemsSpike)
+	t.Run("Test detectAnomalies when there is not enough history", tests.testWhenThereIsNotEnoughHistory)
+	t.Run("Test GetAnomalies", tests.testGetAnomalies)
+	t.Run("Test GetAnomalies when years are not consecutive", tests.testWhenYearsAreNotConsecutive)
+	t.Run("Test GetAnomalies when years are missing", tests.testWhenYearsAreMissing)
+}
+
+type anomaliesTests struct{}
+
+func (a anomaliesTests) monthlyInfo(year, month, count int, remunerations float64, items models.ItemSummary) models.AgencyMonthlyInfo {
+	return models.AgencyMonthlyInfo{
+		AgencyID: "tjal",
+		Year:     year,
+		Month:    month,
+		Summary: &models.Summary{
+			Count:            count,
+			BaseRemuneration: models.DataSummary{Total: remunerations},
+			Remunerations:    models.DataSummary{Total: remunerations},
+			ItemSummary:      items,
+		},
+	}
+}
+
+// Três meses estáveis de 2019 e 2020 com um salto em fevereiro.
+func (a anomaliesTests) monthlyInfos() []models.AgencyMonthlyInfo {
+	return []models.AgencyMonthlyInfo{
+		a.monthlyInfo(2020, 2, 100, 250000, models.ItemSummary{"ferias": 1000}),
+		a.monthlyInfo(2019, 10, 100, 100000, models.ItemSummary{"ferias": 1000}),
+		a.monthlyInfo(2019, 11, 100, 110000, models.ItemSummary{"ferias": 1000}),
+		{AgencyID: "tjal", Year: 2019, Month: 12},
+		a.monthlyInfo(2020, 1, 100, 105000, models.ItemSummary{"ferias": 1000}),
+	}
+}
+
+func (a anomaliesTests) testWhenTotalsJump(t *testing.T) {
+	anomalies := detectAnomalies(a.monthlyInfos())
+
+	assert.Len(t, anomalies, 1)
+	found := anomalies[yearMonth{Year: 2020, Month: 2}]
+	assert.Len(t, found, 1)
+	assert.Equal(t, "tjal", found[0].AgencyID)
+	assert.Equal(t, anomalyRemunerations, found[0].Type)
+	assert.Equal(t, 250000.0, found[0].Value)
+	assert.Equal(t, 105000.0, found[0].Median)
+	assert.InDelta(t, 145000.0/105000, *found[0].Deviation, 1e-9)
+}
+
+func (a anomaliesTests) testWhenItemsSpike(t *testing.T) {
+	infos := a.monthlyInfos()[1:]
+	infos = append(infos,
+		a.monthlyInfo(2020, 2, 60, 105000, models.ItemSummary{"ferias": 3000, "licenca_premio": 20000, "auxilio_saude": 100}),
+	)
+
+	found := detectAnomalies(infos)[yearMonth{Year: 2020, Month: 2}]
+
+	assert.Len(t, found, 3)
+	assert.Equal(t, anomalyMemberCount, found[0].Type)
+	assert.InDelta(t, -0.4, *found[0].Deviation, 1e-9)
+	assert.Equal(t, anomaly{AgencyID: "tjal", Year: 2020, Month: 2, Type: anomalyItem, Item: "ferias", Value: 3000, Median: 1000, Deviation: found[1].Deviation}, found[1])
+	assert.Equal(t, 2.0, *found[1].Deviation)
+	assert.Equal(t, anomaly{AgencyID: "tjal", Year: 2020, Month: 2, Type: anomalyItem, Item: "licenca_premio", Value: 20000}, found[2])
+}
+
+func (a anomaliesTests) testWhenThereIsNotEnoughHistory(t *testing.T) {
+	infos := a.monthlyInfos()[:3]
+
+	assert.Empty(t, detectAnomalies(infos))
+}
+
+func (a anomaliesTests) testGetAnomalies(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dbMock := database.NewMockInterface(mockCtrl)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+	infos := a.monthlyInfos()
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2019).Return(map[string][]models.AgencyMonthlyInfo{"tjal": infos[1:4]}, nil)
+	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2020).Return(map[string][]models.AgencyMonthlyInfo{"tjal": {infos[4], infos[0]}}, nil)
+	client, _ := storage.NewClient(dbMock, fsMock)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/anomalias?orgaos=tjal&anos=2020", nil), recorder)
+
+	handler.GetAnomalies(ctx)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var found []anomaly
+	if err := json.Unmarshal(recorder.Body.Bytes(), &found); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, found, 1)
+	assert.Equal(t, 2, found[0].Month)
+	assert.Equal(t, anomalyRemunerations, found[0].Type)
+}
+
+func (a anomaliesTests) testWhenYearsAreNotConsecutive(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dbMock := database.NewMockInterface(mockCtrl)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+	infos := a.monthlyInfos()
+	agencies := []models.Agency{{ID: "tjal"}}
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2019).Return(map[string][]models.AgencyMonthlyInfo{"tjal": infos[1:4]}, nil)
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2020).Return(map[string][]models.AgencyMonthlyInfo{"tjal": {infos[4], infos[0]}}, nil)
+	// Comparada com 2022, a queda de janeiro de 2023 é uma anomalia; comparada
+	// com 2020, não seria.
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2022).Return(map[string][]models.AgencyMonthlyInfo{"tjal": {
+		a.monthlyInfo(2022, 10, 100, 300000, nil),
+		a.monthlyInfo(2022, 11, 100, 300000, nil),
+		a.monthlyInfo(2022, 12, 100, 300000, nil),
+	}}, nil)
+	dbMock.EXPECT().GetMonthlyInfo(agencies, 2023).Return(map[string][]models.AgencyMonthlyInfo{"tjal": {
+		a.monthlyInfo(2023, 1, 100, 120000, nil),
+	}}, nil)
+	client, _ := storage.NewClient(dbMock, fsMock)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/anomalias?orgaos=tjal&anos=2020,2023", nil), recorder)
+
+	handler.GetAnomalies(ctx)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var found []anomaly
+	if err := json.Unmarshal(recorder.Body.Bytes(), &found); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, found, 2)
+	assert.Equal(t, yearMonth{Year: 2020, Month: 2}, yearMonth{Year: found[0].Year, Month: found[0].Month})
+	assert.Equal(t, yearMonth{Year: 2023, Month: 1}, yearMonth{Year: found[1].Year, Month: found[1].Month})
+	assert.Equal(t, 275000.0, found[1].Median)
+}
+
+func (a anomaliesTests) testWhenYearsAreMissing(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/anomalias?orgaos=tjal", nil), recorder)
+
+	hand.GetAnomalies(ctx)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestGetTotalsOfAgencyRange(t *testing.T) {
+	tests := getTotalsOfAgencyRange{}
+	t.Run("Test GetTotalsOfAgencyRange when data exists", tests.testWhenDataExists)
+	t.Run("Test GetTotalsOfAgencyRange when range is missing", tests.testWhenRangeIsMissing)
+}
+
+type getTotalsOfAgencyRange struct{}
+
+func (g getTotalsOfAgencyRange) testWhenDataExists(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dbMock := database.NewMockInterface(mockCtrl)
+	fsMock := file_storage.NewMockInterface(mockCtrl)
+	infos := anomaliesTests{}.monthlyInfos()
+	dbMock.EXPECT().Connect().Return(nil).Times(1)
+	dbMock.EXPECT().GetAgency("tjal").Return(&models.Agency{ID: "tjal", Name: "Tribunal de Justiça do Estado de Alagoas"}, nil)
+	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2018).Return(map[string][]models.AgencyMonthlyInfo{}, nil)
+	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2019).Return(map[string][]models.AgencyMonthlyInfo{"tjal": infos[1:4]}, nil)
+	dbMock.EXPECT().GetMonthlyInfo([]models.Agency{{ID: "tjal"}}, 2020).Return(map[string][]models.AgencyMonthlyInfo{"tjal": {infos[0], infos[4]}}, nil)
+	client, _ := storage.NewClient(dbMock, fsMock)
+	handler, err := NewHandler(client, nil, nil, NewDirZipStore(os.TempDir(), "dadosjusbr_public"), nil, loc, []string{}, 100, 100, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/orgao/totais/tjal?de=2019-11&ate=2020-02", nil), recorder)
+	ctx.SetPath("/uiapi/v2/orgao/totais/:orgao")
+	ctx.SetParamNames("orgao")
+	ctx.SetParamValues("tjal")
+
+	handler.GetTotalsOfAgencyRange(ctx)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var totals agencyTotalsRange
+	if err := json.Unmarshal(recorder.Body.Bytes(), &totals); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "tjal", totals.Agency.ID)
+	// O mês sem dados é ignorado e outubro de 2019 fica fora do intervalo.
+	assert.Len(t, totals.MonthTotals, 3)
+	assert.Equal(t, []yearMonth{{2019, 11}, {2020, 1}, {2020, 2}}, []yearMonth{
+		{totals.MonthTotals[0].Year, totals.MonthTotals[0].Month},
+		{totals.MonthTotals[1].Year, totals.MonthTotals[1].Month},
+		{totals.MonthTotals[2].Year, totals.MonthTotals[2].Month},
+	})
+	assert.Len(t, totals.MonthTotals[2].Anomalies, 1)
+	assert.InDelta(t, 465000.0/300, totals.AveragePerCapita.Remunerations, 1e-9)
+}
+
+func (g getTotalsOfAgencyRange) testWhenRangeIsMissing(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/uiapi/v2/orgao/totais/tjal", nil), recorder)
+	ctx.SetPath("/uiapi/v2/orgao/totais/:orgao")
+	ctx.SetParamNames("orgao")
+	ctx.SetParamValues("tjal")
+
+	handler{}.GetTotalsOfAgencyRange(ctx)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "\"parâmetro de ou ate é obrigatório!\"\n", recorder.Body.String())
+}
@@ -0,0 +1,136 @@
+package uiapi
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// cachedZipStore guarda na memória os arquivos zip buscados mais recentemente,
+// até o limite de maxBytes. Quando o limite é atingido, os arquivos usados há
+// mais tempo são descartados (LRU).
+type cachedZipStore struct {
+	store    ZipStore
+	maxBytes int64
+	newrelic *newrelic.Application
+
+	mu       sync.Mutex
+	size     int64
+	entries  map[string]*list.Element
+	lru      *list.List // mais recente na frente
+	inFlight map[string]*zipFetch
+	stats    zipCacheStats
+}
+
+type zipCacheEntry struct {
+	zipURL  string
+	content []byte
+}
+
+// Busca em andamento de um arquivo, compartilhada entre as requisições que
+// pedirem o mesmo arquivo ao mesmo tempo.
+type zipFetch struct {
+	done    chan struct{}
+	content []byte
+	err     error
+}
+
+// Métricas do cache de arquivos zip.
+type zipCacheStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Entries   int
+	Bytes     int64
+}
+
+// NewCachedZipStore adiciona um cache em memória de até maxBytes ao ZipStore.
+// As métricas do cache também são enviadas ao New Relic, quando configurado.
+func NewCachedZipStore(store ZipStore, maxBytes int64, newrelic *newrelic.Application) ZipStore {
+	return &cachedZipStore{
+		store:    store,
+		maxBytes: maxBytes,
+		newrelic: newrelic,
+		entries:  map[string]*list.Element{},
+		lru:      list.New(),
+		inFlight: map[string]*zipFetch{},
+	}
+}
+
+func (c *cachedZipStore) GetZip(ctx context.Context, zipURL string) ([]byte, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[zipURL]; ok {
+		c.lru.MoveToFront(e)
+		c.stats.Hits++
+		c.mu.Unlock()
+		c.newrelic.RecordCustomMetric("Custom/ZipCache/Hit", 1)
+		return e.Value.(*zipCacheEntry).content, nil
+	}
+	c.stats.Misses++
+	f, ok := c.inFlight[zipURL]
+	if !ok {
+		f = &zipFetch{done: make(chan struct{})}
+		c.inFlight[zipURL] = f
+		// A busca é compartilhada, então não pode ser cancelada junto com a
+		// requisição que a iniciou: as demais continuariam esperando por ela.
+		go c.fetch(context.WithoutCancel(ctx), zipURL, f)
+	}
+	c.mu.Unlock()
+	c.newrelic.RecordCustomMetric("Custom/ZipCache/Miss", 1)
+	select {
+	case <-f.done:
+		return f.content, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch busca o arquivo no ZipStore, o adiciona ao cache e avisa as
+// requisições que esperam por ele.
+func (c *cachedZipStore) fetch(ctx context.Context, zipURL string, f *zipFetch) {
+	f.content, f.err = c.store.GetZip(ctx, zipURL)
+
+	c.mu.Lock()
+	delete(c.inFlight, zipURL)
+	if f.err == nil {
+		c.add(zipURL, f.content)
+	}
+	c.mu.Unlock()
+	close(f.done)
+}
+
+// Adiciona o arquivo ao cache, descartando os menos usados. Deve ser chamado
+// com o mutex travado.
+func (c *cachedZipStore) add(zipURL string, content []byte) {
+	size := int64(len(content))
+	// Arquivos maiores que o cache inteiro não são guardados.
+	if size > c.maxBytes {
+		return
+	}
+	if _, ok := c.entries[zipURL]; ok {
+		return
+	}
+	for c.size+size > c.maxBytes {
+		oldest := c.lru.Back()
+		entry := c.lru.Remove(oldest).(*zipCacheEntry)
+		delete(c.entries, entry.zipURL)
+		c.size -= int64(len(entry.content))
+		c.stats.Evictions++
+		c.newrelic.RecordCustomMetric("Custom/ZipCache/Eviction", 1)
+	}
+	c.entries[zipURL] = c.lru.PushFront(&zipCacheEntry{zipURL: zipURL, content: content})
+	c.size += size
+	c.newrelic.RecordCustomMetric("Custom/ZipCache/Bytes", float64(c.size))
+}
+
+// Stats retorna as métricas atuais do cache.
+func (c *cachedZipStore) Stats() zipCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	stats.Bytes = c.size
+	return stats
+}
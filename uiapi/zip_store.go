@@ -0,0 +1,97 @@
+package uiapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// ZipStore busca os arquivos zip de remunerações a partir da URL registrada
+// no banco de dados (coluna zip_url da tabela remuneracoes_zips).
+type ZipStore interface {
+	GetZip(ctx context.Context, zipURL string) ([]byte, error)
+}
+
+type s3ZipStore struct {
+	downloader *s3manager.Downloader
+	bucket     string
+}
+
+// NewS3ZipStore cria um ZipStore que baixa os arquivos de um bucket do AWS S3.
+// Se endpoint não for vazio, os arquivos são baixados de um serviço compatível
+// com o S3 (ex: MinIO) nesse endereço.
+func NewS3ZipStore(region, bucket, endpoint string) (ZipStore, error) {
+	config := &aws.Config{
+		Region: aws.String(region),
+	}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+		// Serviços compatíveis normalmente não possuem um subdomínio por bucket.
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating aws session: %w", err)
+	}
+	return &s3ZipStore{downloader: s3manager.NewDownloader(sess), bucket: bucket}, nil
+}
+
+func (s *s3ZipStore) GetZip(ctx context.Context, zipURL string) ([]byte, error) {
+	key := zipKey(zipURL, s.bucket)
+	buf := aws.NewWriteAtBuffer([]byte{})
+	_, err := s.downloader.DownloadWithContext(ctx, buf, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error downloading file (%s) from S3: %w", key, err)
+	}
+	return buf.Bytes(), nil
+}
+
+type dirZipStore struct {
+	dir    string
+	bucket string
+}
+
+// NewDirZipStore cria um ZipStore que lê os arquivos de um diretório local,
+// que deve seguir a mesma estrutura de chaves do bucket. Ex: o arquivo
+// https://dadosjusbr.s3.amazonaws.com/tjal/tjal-2020-1.zip é lido de
+// <dir>/tjal/tjal-2020-1.zip.
+func NewDirZipStore(dir, bucket string) ZipStore {
+	return &dirZipStore{dir: dir, bucket: bucket}
+}
+
+func (d *dirZipStore) GetZip(ctx context.Context, zipURL string) ([]byte, error) {
+	// Limpamos a chave como um caminho absoluto para que ela não saia do diretório.
+	key := path.Clean("/" + zipKey(zipURL, d.bucket))
+	name := filepath.Join(d.dir, filepath.FromSlash(key))
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file (%s): %w", name, err)
+	}
+	return content, nil
+}
+
+// Pega a chave do arquivo no bucket a partir da URL, que pode ser no formato
+// https://<bucket>.<host>/<chave> ou https://<host>/<bucket>/<chave>.
+func zipKey(zipURL, bucket string) string {
+	u, err := url.Parse(zipURL)
+	if err != nil || u.Host == "" {
+		return strings.TrimPrefix(zipURL, "/")
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if !strings.HasPrefix(u.Host, bucket+".") {
+		key = strings.TrimPrefix(key, bucket+"/")
+	}
+	return key
+}